@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Api serves the live state of the app on ApiAddress, a loopback address,
+// for dashboards and scripts. A request for another host than localhost or a
+// loopback IP is refused, so a web page cannot reach it by DNS rebinding.
+//
+//	GET /api/state        window title, dataset, labels and backend
+//	GET /api/grid         grid geometry of the last prediction
+//	GET /api/predictions  scores of every tile of the last prediction
+//	GET /api/model        the active model
+//	GET /api/frame.png    the frame of the last prediction
+//	GET /api/events       Server-Sent Events, one "prediction" per update
+const (
+	apiHeartbeatPeriod = 15 * time.Second
+	apiStopTimeout     = 5 * time.Second
+)
+
+type ApiTile struct {
+	Index int             `json:"Index"`
+	Rect  image.Rectangle `json:"Rect"`
+	Label string          `json:"Label"`
+	Score float64         `json:"Score"`
+	Lit   bool            `json:"Lit"`
+	Probs []float64       `json:"Probs"`
+}
+
+type ApiPrediction struct {
+	Time  string    `json:"Time"`
+	Tiles []ApiTile `json:"Tiles"`
+}
+
+type ApiGrid struct {
+	Columns int               `json:"Columns"`
+	Rows    int               `json:"Rows"`
+	Frame   image.Rectangle   `json:"Frame"`
+	Tiles   []image.Rectangle `json:"Tiles"`
+}
+
+type ApiState struct {
+	Window      string        `json:"Window"`
+	Dataset     DatasetStruct `json:"Dataset"`
+	DatasetName string        `json:"DatasetName"`
+	Labels      []string      `json:"Labels"`
+	Backend     string        `json:"Backend"`
+	Ready       bool          `json:"Ready"`
+}
+
+type ApiStruct struct {
+	mut        sync.Mutex
+	server     *http.Server
+	state      ApiState
+	prediction []byte
+	grid       *ApiGrid
+	frame      *image.RGBA
+	clients    map[chan []byte]struct{}
+}
+
+var Api ApiStruct
+
+func (m *ApiStruct) Start() error {
+	host, _, err := net.SplitHostPort(Config.Common.ApiAddress)
+	if err != nil {
+		return fmt.Errorf("Api start error: %v", err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("Api start error: %v is not a loopback address", Config.Common.ApiAddress)
+	}
+	l, err := net.Listen("tcp", Config.Common.ApiAddress)
+	if err != nil {
+		return fmt.Errorf("Api start error: %v", err)
+	}
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	m.PublishState()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/state", m.handleState)
+	mux.HandleFunc("/api/grid", m.handleGrid)
+	mux.HandleFunc("/api/predictions", m.handlePredictions)
+	mux.HandleFunc("/api/model", m.handleModel)
+	mux.HandleFunc("/api/frame.png", m.handleFrame)
+	mux.HandleFunc("/api/events", m.handleEvents)
+	s := &http.Server{Handler: m.checkHost(port, mux)}
+	m.mut.Lock()
+	m.server = s
+	m.clients = make(map[chan []byte]struct{})
+	m.mut.Unlock()
+	go func() {
+		if err := s.Serve(l); err != http.ErrServerClosed {
+			log.Println(fmt.Errorf("Api error: %v", err))
+		}
+	}()
+	log.Println("Api listens on", Config.Common.ApiAddress)
+	return nil
+}
+
+// apiLoopbackHost tells if the Host header hostport is localhost or a
+// loopback IP with port, no port is the http default.
+func apiLoopbackHost(hostport string, port string) bool {
+	host, p, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, p = hostport, "80"
+	}
+	if p != port {
+		return false
+	}
+	ip := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+	return strings.EqualFold(host, "localhost") || (ip != nil && ip.IsLoopback())
+}
+
+func (m *ApiStruct) checkHost(port string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !apiLoopbackHost(r.Host, port) {
+			http.Error(w, "host not allowed", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (m *ApiStruct) Stop() {
+	m.mut.Lock()
+	s := m.server
+	m.server = nil
+	for c := range m.clients {
+		close(c)
+	}
+	m.clients = nil
+	m.mut.Unlock()
+	if s == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiStopTimeout)
+	defer cancel()
+	s.Shutdown(ctx)
+}
+
+// Publish makes the prediction of frame current and sends it to the event
+// clients. lit are the tiles TileFilter lights up.
+func (m *ApiStruct) Publish(frame *image.RGBA, rects []*image.Rectangle, probs [][]float64, lit map[int]*SelectedData) {
+	m.mut.Lock()
+	running := m.server != nil
+	m.mut.Unlock()
+	if !running {
+		return
+	}
+	names := MlConfigClassNames()
+	p := ApiPrediction{Time: time.Now().Format("2006-01-02 15:04:05.000"), Tiles: make([]ApiTile, len(probs))}
+	g := &ApiGrid{Frame: frame.Rect, Tiles: make([]image.Rectangle, len(rects))}
+	xy := Grid.NumXYRects()
+	g.Columns, g.Rows = xy.X, xy.Y
+	for i, r := range rects {
+		g.Tiles[i] = *r
+	}
+	for i, x := range probs {
+		d := MlTopLabel(x)
+		t := ApiTile{Index: i, Score: d.Value, Probs: x}
+		if d.Class < len(names) {
+			t.Label = names[d.Class]
+		}
+		if i < len(rects) {
+			t.Rect = *rects[i]
+		}
+		_, t.Lit = lit[i]
+		p.Tiles[i] = t
+	}
+	data, err := json.Marshal(&p)
+	if err != nil {
+		log.Println(fmt.Errorf("Api publish error: %v", err))
+		return
+	}
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.prediction, m.grid, m.frame = data, g, frame
+	for c := range m.clients {
+		// A slow client skips updates instead of holding up the others.
+		select {
+		case c <- data:
+		default:
+		}
+	}
+}
+
+func (m *ApiStruct) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(fmt.Errorf("Api write error: %v", err))
+	}
+}
+
+// PublishState makes the window title and the dataset current for
+// /api/state. The GUI changes them on its own goroutine, so the handler reads
+// this copy.
+func (m *ApiStruct) PublishState() {
+	backend := MlBackendGo
+	if _, ok := Ml.(*MlGRPCStruct); ok {
+		backend = MlBackendGRPC
+	}
+	s := ApiState{Window: targetWindowTitle, Dataset: Dataset, DatasetName: Dataset.Name, Labels: MlConfigClassNames(), Backend: backend}
+	m.mut.Lock()
+	m.state = s
+	m.mut.Unlock()
+}
+
+func (m *ApiStruct) handleState(w http.ResponseWriter, r *http.Request) {
+	m.mut.Lock()
+	s := m.state
+	m.mut.Unlock()
+	s.Ready = Ml.Ready()
+	m.writeJSON(w, &s)
+}
+
+func (m *ApiStruct) handleGrid(w http.ResponseWriter, r *http.Request) {
+	m.mut.Lock()
+	g := m.grid
+	m.mut.Unlock()
+	if g == nil {
+		http.Error(w, "no prediction yet", http.StatusNotFound)
+		return
+	}
+	m.writeJSON(w, g)
+}
+
+func (m *ApiStruct) handlePredictions(w http.ResponseWriter, r *http.Request) {
+	m.mut.Lock()
+	data := m.prediction
+	m.mut.Unlock()
+	if data == nil {
+		http.Error(w, "no prediction yet", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (m *ApiStruct) handleModel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if Config.Common.mlCallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Config.Common.mlCallTimeout)
+		defer cancel()
+	}
+	models, err := Ml.ListModels(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	for _, i := range models {
+		if i.Active {
+			m.writeJSON(w, i)
+			return
+		}
+	}
+	http.Error(w, "no active model", http.StatusNotFound)
+}
+
+func (m *ApiStruct) handleFrame(w http.ResponseWriter, r *http.Request) {
+	m.mut.Lock()
+	frame := m.frame
+	m.mut.Unlock()
+	if frame == nil {
+		http.Error(w, "no frame yet", http.StatusNotFound)
+		return
+	}
+	b := bytes.Buffer{}
+	if err := png.Encode(&b, frame); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(b.Bytes())
+}
+
+func (m *ApiStruct) handleEvents(w http.ResponseWriter, r *http.Request) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	c := make(chan []byte, 1)
+	m.mut.Lock()
+	if m.clients == nil {
+		m.mut.Unlock()
+		http.Error(w, "stopped", http.StatusServiceUnavailable)
+		return
+	}
+	m.clients[c] = struct{}{}
+	m.mut.Unlock()
+	defer func() {
+		m.mut.Lock()
+		delete(m.clients, c)
+		m.mut.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	heartbeat := time.NewTicker(apiHeartbeatPeriod)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data, ok := <-c:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: prediction\ndata: %s\n\n", data)
+			f.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			f.Flush()
+		}
+	}
+}
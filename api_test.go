@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApiLoopbackHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"localhost:8077", true},
+		{"LOCALHOST:8077", true},
+		{"127.0.0.1:8077", true},
+		{"127.1.2.3:8077", true},
+		{"[::1]:8077", true},
+		{"localhost:8078", false},
+		{"localhost", false},
+		{"attacker.example:8077", false},
+		{"localhost.attacker.example:8077", false},
+		{"192.168.1.2:8077", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := apiLoopbackHost(tt.host, "8077"); got != tt.want {
+			t.Errorf("apiLoopbackHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+	if !apiLoopbackHost("localhost", "80") || !apiLoopbackHost("[::1]", "80") {
+		t.Errorf("no port is not the default one")
+	}
+}
+
+func TestApiCheckHost(t *testing.T) {
+	h := Api.checkHost("8077", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for host, want := range map[string]int{"127.0.0.1:8077": http.StatusOK, "rebound.example:8077": http.StatusForbidden} {
+		r := httptest.NewRequest("GET", "/api/frame.png", nil)
+		r.Host = host
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("%v: got %v, want %v", host, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"math/rand"
+)
+
+// Augmentation makes Copies variants of every training sample when the
+// samples are streamed to the server. A variant is made from the marked
+// file with a random generator seeded by its hash, so it is the same on every
+// run and the server keeps it like any other sample until the settings change.
+type AugmentStruct struct{}
+
+var Augment AugmentStruct
+
+// Samples returns samples followed by their variants.
+func (m *AugmentStruct) Samples(samples []MlSample) []MlSample {
+	a := &Config.Augmentation
+	if a.Copies <= 0 {
+		return samples
+	}
+	settings, _ := json.Marshal(a)
+	res := make([]MlSample, 0, len(samples)*(a.Copies+1))
+	res = append(res, samples...)
+	for _, s := range samples {
+		for v := 1; v <= a.Copies; v++ {
+			x := s
+			x.Variant = v
+			x.Hash = m.variantHash(s.Hash, settings, int64(v))
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
+func (m *AugmentStruct) variantHash(hash []byte, settings []byte, v int64) []byte {
+	h := sha256.New()
+	h.Write(hash)
+	h.Write(settings)
+	binary.Write(h, binary.BigEndian, v)
+	return h.Sum(nil)
+}
+
+// Preview returns n variants of img for the preview screen, seed picks them.
+func (m *AugmentStruct) Preview(img *image.RGBA, seed int64, n int) []*image.RGBA {
+	res := make([]*image.RGBA, 0, n)
+	for v := 0; v < n; v++ {
+		res = append(res, m.Apply(img, m.variantHash(nil, nil, seed*int64(n)+int64(v))))
+	}
+	return res
+}
+
+// Apply makes the variant of img given by seed: shift, scale and flips in one
+// nearest neighbour mapping clamped to the edges, then brightness and
+// contrast, then a JPEG round trip.
+func (m *AugmentStruct) Apply(img *image.RGBA, seed []byte) *image.RGBA {
+	a := &Config.Augmentation
+	r := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(seed))))
+	uniform := func(x float64) float64 {
+		return (r.Float64()*2 - 1) * x
+	}
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	scale := 1 + uniform(a.Scale)
+	dx, dy := uniform(a.Shift)*float64(w), uniform(a.Shift)*float64(h)
+	flipH := a.FlipHorizontal && r.Intn(2) == 1
+	flipV := a.FlipVertical && r.Intn(2) == 1
+	brightness := uniform(a.Brightness) * 255
+	contrast := 1 + uniform(a.Contrast)
+
+	res := image.NewRGBA(image.Rect(0, 0, w, h))
+	cx, cy := float64(w-1)/2, float64(h-1)/2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			u := int(math.Round((float64(x)-cx-dx)/scale + cx))
+			v := int(math.Round((float64(y)-cy-dy)/scale + cy))
+			if flipH {
+				u = w - 1 - u
+			}
+			if flipV {
+				v = h - 1 - v
+			}
+			u = augmentClamp(u, 0, w-1)
+			v = augmentClamp(v, 0, h-1)
+			src := img.PixOffset(u+img.Rect.Min.X, v+img.Rect.Min.Y)
+			dst := res.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				p := (float64(img.Pix[src+c])-128)*contrast + 128 + brightness
+				res.Pix[dst+c] = uint8(augmentClamp(int(math.Round(p)), 0, 255))
+			}
+			res.Pix[dst+3] = img.Pix[src+3]
+		}
+	}
+
+	if a.JpegQuality > 0 && r.Float64() < a.JpegProbability {
+		q := a.JpegQuality + r.Intn(augmentClamp(96-a.JpegQuality, 1, 96))
+		if j, err := m.jpeg(res, q); err == nil {
+			res = j
+		}
+	}
+	return res
+}
+
+// jpeg adds compression artifacts by a JPEG round trip, alpha is kept.
+func (m *AugmentStruct) jpeg(img *image.RGBA, quality int) (*image.RGBA, error) {
+	b := bytes.Buffer{}
+	if err := jpeg.Encode(&b, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("jpeg error: %v", err)
+	}
+	j, err := jpeg.Decode(&b)
+	if err != nil {
+		return nil, fmt.Errorf("jpeg error: %v", err)
+	}
+	res := image.NewRGBA(img.Rect)
+	draw.Draw(res, res.Rect, j, j.Bounds().Min, draw.Src)
+	for i := 3; i < len(res.Pix); i += 4 {
+		res.Pix[i] = img.Pix[i]
+	}
+	return res, nil
+}
+
+func augmentClamp(x, min, max int) int {
+	if x < min {
+		return min
+	}
+	if x > max {
+		return max
+	}
+	return x
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Commands run instead of the GUI when the app gets arguments:
+//
+//	scrml export [version]       export a model, the active one by default
+//	scrml verify-model <file.onnx>
+//	                             check an exported model against the dataset
+//	scrml migrate-index          index the marked samples saved before the
+//	                             sample index, from their file names
+//	scrml stats                  write the dataset statistics report
+//	scrml regenerate [rows]      cut the project frames again into new marked
+//	                             data, for a grid of rows and the sample size
+//	scrml import-legacy [folder] add a crop-only folder, markeddata by
+//	                             default, to the project
+//	scrml export-data [folder]   export the marked data as class folders,
+//	                             NPZ and CSV with train/val/test splits
+//	scrml import-data <folder|file.csv>
+//	                             add labelled images of other tools to the
+//	                             new marked data
+const commandServerWait = time.Minute
+
+func RunCommand(args []string) error {
+	switch args[0] {
+	case "export":
+		if len(args) > 2 {
+			break
+		}
+		version := ""
+		if len(args) == 2 {
+			version = args[1]
+		}
+		return commandExport(version)
+	case "verify-model":
+		if len(args) != 2 {
+			break
+		}
+		return commandVerifyModel(args[1])
+	case "migrate-index":
+		if len(args) != 1 {
+			break
+		}
+		return commandMigrateIndex()
+	case "stats":
+		if len(args) != 1 {
+			break
+		}
+		return commandStats()
+	case "regenerate":
+		if len(args) > 2 {
+			break
+		}
+		rows := verticalDomains
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				break
+			}
+			rows = n
+		}
+		return commandRegenerate(rows)
+	case "import-legacy":
+		if len(args) > 2 {
+			break
+		}
+		dir := Dataset.Path(fileMarkedDataFolder)
+		if len(args) == 2 {
+			dir = args[1]
+		}
+		return commandImportLegacy(dir)
+	case "export-data":
+		if len(args) > 2 {
+			break
+		}
+		dir := Dataset.Path(DatasetExportFolder)
+		if len(args) == 2 {
+			dir = args[1]
+		}
+		return commandExportData(dir)
+	case "import-data":
+		if len(args) != 2 {
+			break
+		}
+		return commandImportData(args[1])
+	}
+	return fmt.Errorf("usage: scrml [export [version] | verify-model <file.onnx> | migrate-index | stats | regenerate [rows] | import-legacy [folder] | export-data [folder] | import-data <folder|file.csv>]")
+}
+
+func commandExport(version string) error {
+	if err := MlSelectBackend(Config.Common.MlBackend); err != nil {
+		return err
+	}
+	if _, ok := Ml.(*MlGRPCStruct); ok {
+		if err := MlGRPC.StartMlServer(); err != nil {
+			return fmt.Errorf("Start ML server error: %v", err)
+		}
+		defer MlGRPC.StopMlServer()
+		deadline := time.Now().Add(commandServerWait)
+		for !Ml.Ready() {
+			if time.Now().After(deadline) {
+				return &MlError{Op: "export model", Code: ErrMlUnavailable, Message: fmt.Sprintf("no server in %v", commandServerWait)}
+			}
+			time.Sleep(mlServerHealthPeriod)
+		}
+	}
+	modelFile, sidecarFile, err := Ml.ExportModel(context.Background(), version, MlExportFolder)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Exported", modelFile, sidecarFile)
+	return nil
+}
+
+func commandVerifyModel(onnxFile string) error {
+	m, err := MlVerifyExportedModel(onnxFile)
+	if err != nil {
+		return err
+	}
+	s := &m.Sidecar
+	fmt.Printf("Model %v created %v: %dx%d %v samples, %d classes, %d bytes, graph %v to %v, matches the dataset and labels\n",
+		s.Version, s.Created, s.SampleSize, s.SampleSize, s.Channels, len(s.ClassNames), m.Size, &m.Input, &m.Output)
+	return nil
+}
+
+func commandMigrateIndex() error {
+	n, err := File.MigrateMarkedIndex()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Indexed %d samples\n", n)
+	return nil
+}
+
+func commandStats() error {
+	s := DatasetCollectStats(File.GetMarkedDataList())
+	for _, l := range s.Lines() {
+		fmt.Println(l)
+	}
+	if err := s.WriteReport(DatasetStatsReportFile); err != nil {
+		return err
+	}
+	fmt.Println("Report written to", DatasetStatsReportFile)
+	return nil
+}
+
+func commandRegenerate(rows int) error {
+	if !Project.Enabled() {
+		return fmt.Errorf("regenerate error: no Project in config.txt")
+	}
+	n, err := Project.Regenerate(rows)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Cut %d samples of %v for %d rows into %v\n", n, Project.Folder(), rows, Dataset.Path(fileNewMarkedDataFolder))
+	return nil
+}
+
+func commandImportLegacy(dir string) error {
+	if !Project.Enabled() {
+		return fmt.Errorf("import-legacy error: no Project in config.txt")
+	}
+	n, err := Project.ImportLegacy(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d legacy frames of %v into %v\n", n, dir, Project.Folder())
+	return nil
+}
+
+func commandExportData(dir string) error {
+	m, err := DatasetExport(File.GetMarkedDataList(), dir)
+	if err != nil {
+		return err
+	}
+	for _, x := range DatasetExportSplits {
+		s := m.Splits[x]
+		fmt.Printf("%v: %d samples of %d screenshots\n", x, s.Samples, s.Sources)
+	}
+	fmt.Printf("Exported to %v, %d samples skipped\n", dir, m.Skipped)
+	return nil
+}
+
+func commandImportData(src string) error {
+	r, err := DatasetImport(context.Background(), src)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d images of %v into %v, %d similar to other samples, %d skipped\n",
+		r.Imported, src, Dataset.Path(fileNewMarkedDataFolder), r.Similar, r.Skipped)
+	return nil
+}
@@ -3,18 +3,133 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/color"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 var Config ConfigStruct
 
 type ConfigStruct struct {
-	Keybindings ConfigKeybindings
-	Common      ConfigCommon
+	Keybindings  ConfigKeybindings
+	Common       ConfigCommon
+	Augmentation ConfigAugmentation
+	Smoothing    ConfigSmoothing
+	Balancing    ConfigBalancing
+	Rules        []ConfigRule
+	Labels       []ConfigLabel
+}
+
+// ConfigLabel is a markup class. The first label is the background class,
+// the one the old markup called negative.
+type ConfigLabel struct {
+	Name  string `json:"Name"`
+	Color string `json:"Color"`
+	rgba  color.RGBA
 }
 
 type ConfigCommon struct {
-	SaveMarkedToPersistent string `json:"SaveMarkedToPersistent"`
+	SaveMarkedToPersistent string  `json:"SaveMarkedToPersistent"`
+	MlBackend              string  `json:"MlBackend"`
+	MlGoWeightsFile        string  `json:"MlGoWeightsFile"`
+	MlModelSpecFile        string  `json:"MlModelSpecFile"`
+	MlServerManaged        bool    `json:"MlServerManaged"`
+	MlServerInterpreter    string  `json:"MlServerInterpreter"`
+	MlServerScript         string  `json:"MlServerScript"`
+	MlServerPort           int     `json:"MlServerPort"`
+	MlServerLogFile        string  `json:"MlServerLogFile"`
+	MlServerAddress        string  `json:"MlServerAddress"`
+	MlCallTimeout          string  `json:"MlCallTimeout"`
+	MlPredictTimeout       string  `json:"MlPredictTimeout"`
+	MlTrainTimeout         string  `json:"MlTrainTimeout"`
+	MlRetries              int     `json:"MlRetries"`
+	MlRetryBackoff         string  `json:"MlRetryBackoff"`
+	SampleSize             int     `json:"SampleSize"`
+	SampleChannels         string  `json:"SampleChannels"`
+	ReviewQueue            bool    `json:"ReviewQueue"`
+	ReviewQueueMin         float64 `json:"ReviewQueueMin"`
+	ReviewQueueMax         float64 `json:"ReviewQueueMax"`
+	ReviewQueuePeriod      string  `json:"ReviewQueuePeriod"`
+	ReviewQueueMaxFrames   int     `json:"ReviewQueueMaxFrames"`
+	ApiAddress             string  `json:"ApiAddress"`
+	Labeler                string  `json:"Labeler"`
+	Project                string  `json:"Project"`
+	DataRoot               string  `json:"DataRoot"`
+	Dataset                string  `json:"Dataset"`
+	mlCallTimeout          time.Duration
+	mlPredictTimeout       time.Duration
+	mlTrainTimeout         time.Duration
+	mlRetryBackoff         time.Duration
+	reviewQueuePeriod      time.Duration
+}
+
+// ConfigAugmentation ranges are plus or minus: Shift is a fraction of the
+// sample side, Scale of its size, Brightness of the full range and Contrast
+// of the current one. JpegQuality is the lowest quality of the JPEG round
+// trip made with JpegProbability, 0 turns it off.
+type ConfigAugmentation struct {
+	Copies          int     `json:"Copies"`
+	Shift           float64 `json:"Shift"`
+	Scale           float64 `json:"Scale"`
+	FlipHorizontal  bool    `json:"FlipHorizontal"`
+	FlipVertical    bool    `json:"FlipVertical"`
+	Brightness      float64 `json:"Brightness"`
+	Contrast        float64 `json:"Contrast"`
+	JpegQuality     int     `json:"JpegQuality"`
+	JpegProbability float64 `json:"JpegProbability"`
+}
+
+// ConfigSmoothing filters live predictions per tile, see TileFilter. Mode is
+// "off", "ema" or "vote". A tile lights up at OnThreshold and goes dark below
+// OffThreshold, tiles that changed in the last ChangedPeriod are outlined.
+type ConfigSmoothing struct {
+	Mode          string  `json:"Mode"`
+	Alpha         float64 `json:"Alpha"`
+	VoteWindow    int     `json:"VoteWindow"`
+	VoteCount     int     `json:"VoteCount"`
+	OnThreshold   float64 `json:"OnThreshold"`
+	OffThreshold  float64 `json:"OffThreshold"`
+	ChangedPeriod string  `json:"ChangedPeriod"`
+	changedPeriod time.Duration
+}
+
+// ConfigBalancing evens out the labels of the training split, see
+// MlBalanceSamples. Mode is "off", "undersample", "oversample" or "weights".
+type ConfigBalancing struct {
+	Mode     string  `json:"Mode"`
+	MaxRatio float64 `json:"MaxRatio"`
+}
+
+// ConfigRule fires its actions when a tile of Tiles, or with its centre in
+// Region, scores Label at Threshold or more for MinDuration. Both empty
+// means any tile. It fires once until the condition clears and not again
+// within Cooldown, see Rules.
+type ConfigRule struct {
+	Name        string             `json:"Name"`
+	Tiles       []int              `json:"Tiles"`
+	Region      *image.Rectangle   `json:"Region"`
+	Label       string             `json:"Label"`
+	Threshold   float64            `json:"Threshold"`
+	MinDuration string             `json:"MinDuration"`
+	Cooldown    string             `json:"Cooldown"`
+	Actions     []ConfigRuleAction `json:"Actions"`
+	class       int
+	minDuration time.Duration
+	cooldown    time.Duration
+}
+
+// ConfigRuleAction Type is "log" appending the event to File as a JSON
+// line, "command" running Command with Args, "frame" saving the frame to
+// Folder or "sound" playing the WAV File. Args may hold {rule}, {label},
+// {score}, {tiles}, {frame} and {time}.
+type ConfigRuleAction struct {
+	Type    string   `json:"Type"`
+	File    string   `json:"File"`
+	Command string   `json:"Command"`
+	Args    []string `json:"Args"`
+	Folder  string   `json:"Folder"`
 }
 
 type ConfigKeybindings struct {
@@ -23,6 +138,10 @@ type ConfigKeybindings struct {
 	Window        ConfigKeybindingsWindow
 	Help          ConfigKeybindingsHelp
 	SaveNewMarked ConfigKeybindingsSaveNewMarked
+	Models        ConfigKeybindingsModels
+	Augment       ConfigKeybindingsAugment
+	Review        ConfigKeybindingsReview
+	Datasets      ConfigKeybindingsDatasets
 }
 
 type ConfigKeybindingsMain struct {
@@ -30,12 +149,21 @@ type ConfigKeybindingsMain struct {
 	Help          string `json:"Help"`
 	Screenshot    string `json:"Screenshot"`
 	Learn         string `json:"Learn"`
+	CancelLearn   string `json:"Cancel learn"`
 	Window        string `json:"Window"`
 	SaveNewMarked string `json:"SaveNewMarked"`
+	Models        string `json:"Models"`
+	Evaluate      string `json:"Evaluate"`
+	ReviewQueue   string `json:"ReviewQueue"`
+	Augment       string `json:"Augment"`
+	Stats         string `json:"Stats"`
+	Review        string `json:"Review"`
+	Datasets      string `json:"Datasets"`
 }
 
 type ConfigKeybindingsMarkup struct {
 	SaveMarkup string `json:"Save markup"`
+	NextLabel  string `json:"Next label"`
 	Ignore     string `json:"Ignore"`
 	Quit       string `json:"Quit"`
 	Help       string `json:"Help"`
@@ -46,6 +174,37 @@ type ConfigKeybindingsWindow struct {
 	Quit string `json:"Quit"`
 }
 
+type ConfigKeybindingsModels struct {
+	Delete string `json:"Delete"`
+	Help   string `json:"Help"`
+	Quit   string `json:"Quit"`
+}
+
+type ConfigKeybindingsAugment struct {
+	Next     string `json:"Next"`
+	Previous string `json:"Previous"`
+	Shuffle  string `json:"Shuffle"`
+	Help     string `json:"Help"`
+	Quit     string `json:"Quit"`
+}
+
+type ConfigKeybindingsReview struct {
+	Next     string `json:"Next"`
+	Previous string `json:"Previous"`
+	Class    string `json:"Class"`
+	Source   string `json:"Source"`
+	Folder   string `json:"Folder"`
+	Delete   string `json:"Delete"`
+	Help     string `json:"Help"`
+	Quit     string `json:"Quit"`
+}
+
+type ConfigKeybindingsDatasets struct {
+	New  string `json:"New"`
+	Help string `json:"Help"`
+	Quit string `json:"Quit"`
+}
+
 type ConfigKeybindingsSaveNewMarked struct {
 	Quit string `json:"Quit"`
 }
@@ -61,8 +220,120 @@ func (c *ConfigStruct) Load() error {
 	}
 
 	err = json.Unmarshal(data, c)
-	return err
+	if err != nil {
+		return err
+	}
 
+	if len(c.Labels) < 2 {
+		return fmt.Errorf("at least 2 labels expected, got %d", len(c.Labels))
+	}
+	for i := range c.Labels {
+		l := &c.Labels[i]
+		if _, err := fmt.Sscanf(l.Color, "%02x%02x%02x", &l.rgba.R, &l.rgba.G, &l.rgba.B); err != nil {
+			return fmt.Errorf("label %v color %q error: %v", l.Name, l.Color, err)
+		}
+		l.rgba.A = 0xff
+	}
+
+	// Durations are Go duration strings, empty or "0" means no deadline.
+	durations := []struct {
+		name string
+		s    string
+		d    *time.Duration
+	}{
+		{"MlCallTimeout", c.Common.MlCallTimeout, &c.Common.mlCallTimeout},
+		{"MlPredictTimeout", c.Common.MlPredictTimeout, &c.Common.mlPredictTimeout},
+		{"MlTrainTimeout", c.Common.MlTrainTimeout, &c.Common.mlTrainTimeout},
+		{"MlRetryBackoff", c.Common.MlRetryBackoff, &c.Common.mlRetryBackoff},
+		{"ReviewQueuePeriod", c.Common.ReviewQueuePeriod, &c.Common.reviewQueuePeriod},
+		{"Smoothing ChangedPeriod", c.Smoothing.ChangedPeriod, &c.Smoothing.changedPeriod},
+	}
+	for _, x := range durations {
+		if x.s == "" {
+			continue
+		}
+		d, err := time.ParseDuration(x.s)
+		if err != nil {
+			return fmt.Errorf("%v error: %v", x.name, err)
+		}
+		*x.d = d
+	}
+	if a := c.Augmentation; a.Shift < 0 || a.Shift > 0.5 || a.Scale < 0 || a.Scale > 0.5 || a.JpegQuality < 0 || a.JpegQuality > 100 {
+		return fmt.Errorf("Augmentation error: Shift and Scale are expected in 0..0.5, JpegQuality in 0..100")
+	}
+	switch s := c.Smoothing; {
+	case s.Mode != "" && s.Mode != TileFilterOff && s.Mode != TileFilterEMA && s.Mode != TileFilterVote:
+		return fmt.Errorf("Smoothing error: unknown Mode %q", s.Mode)
+	case s.Mode == TileFilterEMA && (s.Alpha <= 0 || s.Alpha > 1):
+		return fmt.Errorf("Smoothing error: Alpha is expected in 0..1")
+	case s.Mode == TileFilterVote && (s.VoteCount <= 0 || s.VoteCount > s.VoteWindow):
+		return fmt.Errorf("Smoothing error: VoteCount is expected in 1..VoteWindow")
+	case s.OffThreshold > s.OnThreshold:
+		return fmt.Errorf("Smoothing error: OffThreshold %v is above OnThreshold %v", s.OffThreshold, s.OnThreshold)
+	}
+	switch b := c.Balancing; {
+	case b.Mode != "" && b.Mode != MlBalanceOff && b.Mode != MlBalanceUndersample && b.Mode != MlBalanceOversample && b.Mode != MlBalanceWeights:
+		return fmt.Errorf("Balancing error: unknown Mode %q", b.Mode)
+	case (b.Mode == MlBalanceUndersample || b.Mode == MlBalanceOversample) && b.MaxRatio < 1:
+		return fmt.Errorf("Balancing error: MaxRatio is expected to be 1 or more")
+	}
+	for i := range c.Rules {
+		if err := c.Rules[i].resolve(c.Labels); err != nil {
+			return err
+		}
+	}
+	if err := DatasetCheckName(c.Common.Dataset); err != nil {
+		return fmt.Errorf("Dataset error: %v", err)
+	}
+	if p := c.Common.Project; p != "" && (filepath.Base(p) != p || p == "." || p == "..") {
+		return fmt.Errorf("Project error: %q is not a folder name", p)
+	}
+	if c.Common.ReviewQueueMin > c.Common.ReviewQueueMax {
+		return fmt.Errorf("ReviewQueueMin %v is above ReviewQueueMax %v", c.Common.ReviewQueueMin, c.Common.ReviewQueueMax)
+	}
+	return nil
+}
+
+func (l *ConfigLabel) RGBA() color.RGBA {
+	return l.rgba
+}
+
+func (r *ConfigRule) resolve(labels []ConfigLabel) error {
+	r.class = -1
+	for i, l := range labels {
+		if l.Name == r.Label {
+			r.class = i
+		}
+	}
+	if r.class < 0 {
+		return fmt.Errorf("rule %v error: unknown label %q", r.Name, r.Label)
+	}
+	for _, x := range []struct {
+		s string
+		d *time.Duration
+	}{{r.MinDuration, &r.minDuration}, {r.Cooldown, &r.cooldown}} {
+		if x.s == "" {
+			continue
+		}
+		d, err := time.ParseDuration(x.s)
+		if err != nil {
+			return fmt.Errorf("rule %v error: %v", r.Name, err)
+		}
+		*x.d = d
+	}
+	for _, a := range r.Actions {
+		switch {
+		case a.Type == RuleActionLog && a.File == "", a.Type == RuleActionSound && a.File == "":
+			return fmt.Errorf("rule %v error: %v action needs File", r.Name, a.Type)
+		case a.Type == RuleActionCommand && a.Command == "":
+			return fmt.Errorf("rule %v error: command action needs Command", r.Name)
+		case a.Type == RuleActionFrame && a.Folder == "":
+			return fmt.Errorf("rule %v error: frame action needs Folder", r.Name)
+		case a.Type != RuleActionLog && a.Type != RuleActionSound && a.Type != RuleActionCommand && a.Type != RuleActionFrame:
+			return fmt.Errorf("rule %v error: unknown action %q", r.Name, a.Type)
+		}
+	}
+	return nil
 }
 
 func (c *ConfigStruct) Description() []string {
@@ -71,12 +342,21 @@ func (c *ConfigStruct) Description() []string {
 	a = append(a, fmt.Sprintf("   Markup - %v", c.Keybindings.Main.Markup))
 	a = append(a, fmt.Sprintf("   Make screenshot - %v", c.Keybindings.Main.Screenshot))
 	a = append(a, fmt.Sprintf("   Learn - %v", c.Keybindings.Main.Learn))
+	a = append(a, fmt.Sprintf("   Cancel learn - %v", c.Keybindings.Main.CancelLearn))
 	a = append(a, fmt.Sprintf("   Move new marked to persistent - %v", c.Keybindings.Main.SaveNewMarked))
 	a = append(a, fmt.Sprintf("   Help - %v", c.Keybindings.Main.Help))
 	a = append(a, fmt.Sprintf("   Select window - %v", c.Keybindings.Main.Window))
+	a = append(a, fmt.Sprintf("   Models - %v", c.Keybindings.Main.Models))
+	a = append(a, fmt.Sprintf("   Evaluate on held-out samples - %v", c.Keybindings.Main.Evaluate))
+	a = append(a, fmt.Sprintf("   Markup the review queue - %v", c.Keybindings.Main.ReviewQueue))
+	a = append(a, fmt.Sprintf("   Augmentation preview - %v", c.Keybindings.Main.Augment))
+	a = append(a, fmt.Sprintf("   Dataset statistics - %v", c.Keybindings.Main.Stats))
+	a = append(a, fmt.Sprintf("   Review marked samples - %v", c.Keybindings.Main.Review))
+	a = append(a, fmt.Sprintf("   Datasets - %v", c.Keybindings.Main.Datasets))
 	a = append(a, "")
 	a = append(a, "Markup:")
 	a = append(a, fmt.Sprintf("   Save markup - %v", c.Keybindings.Markup.SaveMarkup))
+	a = append(a, fmt.Sprintf("   Next label for left button - %v", c.Keybindings.Markup.NextLabel))
 	a = append(a, fmt.Sprintf("   Ignore - %v", c.Keybindings.Markup.SaveMarkup))
 	a = append(a, fmt.Sprintf("   Help - %v", c.Keybindings.Markup.Help))
 	a = append(a, fmt.Sprintf("   Quit - %v", c.Keybindings.Markup.Quit))
@@ -85,10 +365,42 @@ func (c *ConfigStruct) Description() []string {
 	a = append(a, fmt.Sprintf("   Help - %v", c.Keybindings.Window.Help))
 	a = append(a, fmt.Sprintf("   Quit - %v", c.Keybindings.Window.Quit))
 	a = append(a, "")
+	a = append(a, "Models (click activates):")
+	a = append(a, fmt.Sprintf("   Delete - %v", c.Keybindings.Models.Delete))
+	a = append(a, fmt.Sprintf("   Help - %v", c.Keybindings.Models.Help))
+	a = append(a, fmt.Sprintf("   Quit - %v", c.Keybindings.Models.Quit))
+	a = append(a, "")
+	a = append(a, "Augmentation preview:")
+	a = append(a, fmt.Sprintf("   Next sample - %v", c.Keybindings.Augment.Next))
+	a = append(a, fmt.Sprintf("   Previous sample - %v", c.Keybindings.Augment.Previous))
+	a = append(a, fmt.Sprintf("   Other variants - %v", c.Keybindings.Augment.Shuffle))
+	a = append(a, fmt.Sprintf("   Help - %v", c.Keybindings.Augment.Help))
+	a = append(a, fmt.Sprintf("   Quit - %v", c.Keybindings.Augment.Quit))
+	a = append(a, "")
+	a = append(a, "Review (click next label, right click previous label):")
+	a = append(a, fmt.Sprintf("   Next page - %v", c.Keybindings.Review.Next))
+	a = append(a, fmt.Sprintf("   Previous page - %v", c.Keybindings.Review.Previous))
+	a = append(a, fmt.Sprintf("   Filter by label - %v", c.Keybindings.Review.Class))
+	a = append(a, fmt.Sprintf("   Filter by screenshot of the hovered sample - %v", c.Keybindings.Review.Source))
+	a = append(a, fmt.Sprintf("   Persistent or new marked samples - %v", c.Keybindings.Review.Folder))
+	a = append(a, fmt.Sprintf("   Delete the hovered sample - %v", c.Keybindings.Review.Delete))
+	a = append(a, fmt.Sprintf("   Help - %v", c.Keybindings.Review.Help))
+	a = append(a, fmt.Sprintf("   Quit - %v", c.Keybindings.Review.Quit))
+	a = append(a, "")
+	a = append(a, "Datasets (click uses the dataset for the window):")
+	a = append(a, fmt.Sprintf("   New dataset named after the window - %v", c.Keybindings.Datasets.New))
+	a = append(a, fmt.Sprintf("   Help - %v", c.Keybindings.Datasets.Help))
+	a = append(a, fmt.Sprintf("   Quit - %v", c.Keybindings.Datasets.Quit))
+	a = append(a, "")
 	a = append(a, "Move new marked to persistent:")
 	a = append(a, fmt.Sprintf("   Quit - %v", c.Keybindings.SaveNewMarked.Quit))
 	a = append(a, "")
 	a = append(a, "Help:")
 	a = append(a, fmt.Sprintf("   Quit - %v", c.Keybindings.Help.Quit))
+	a = append(a, "")
+	a = append(a, "Labels (right button paints the first one):")
+	for i, l := range c.Labels {
+		a = append(a, fmt.Sprintf("   %d - %v", i, l.Name))
+	}
 	return a
 }
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// The sample shape is a property of the marked data, not of the app: it is
+// kept in dataset.json next to the marked data folders. A new dataset takes
+// SampleSize and SampleChannels from config.txt.
+//
+// Every named dataset is a folder of DataRoot holding its dataset.json,
+// screenshots, marked data, review queue and projects. The unnamed dataset
+// is DataRoot itself, the layout from before named datasets.
+const datasetFileName = "dataset.json"
+
+const (
+	DatasetChannelsRGB  = "rgb"
+	DatasetChannelsGray = "gray"
+	DatasetChannelsRGBA = "rgba"
+)
+
+const (
+	datasetDefaultSampleSize = 64
+	datasetDefaultChannels   = DatasetChannelsRGB
+)
+
+type DatasetStruct struct {
+	Name       string `json:"-"`
+	SampleSize int    `json:"SampleSize"`
+	Channels   string `json:"Channels"`
+}
+
+var Dataset DatasetStruct
+
+// Path is the file or folder elem of the dataset.
+func (d *DatasetStruct) Path(elem ...string) string {
+	return filepath.Join(append([]string{Config.Common.DataRoot, d.Name}, elem...)...)
+}
+
+// Select switches to the dataset name, a new one is created with the shape
+// of config.txt.
+func (d *DatasetStruct) Select(name string) error {
+	if err := DatasetCheckName(name); err != nil {
+		return fmt.Errorf("Dataset select error: %v", err)
+	}
+	x := DatasetStruct{Name: name}
+	if err := x.Load(); err != nil {
+		return err
+	}
+	*d = x
+	Datasets.mut.Lock()
+	Datasets.Current = name
+	Datasets.mut.Unlock()
+	return Datasets.Save()
+}
+
+// DatasetCheckName refuses a name that is not a plain folder name, "" is the
+// unnamed dataset.
+func DatasetCheckName(name string) error {
+	if name != "" && (filepath.Base(name) != name || name == "." || name == "..") {
+		return fmt.Errorf("%q is not a folder name", name)
+	}
+	return nil
+}
+
+func (d *DatasetStruct) Load() error {
+	data, err := os.ReadFile(d.Path(datasetFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		d.SampleSize = Config.Common.SampleSize
+		d.Channels = Config.Common.SampleChannels
+		if d.SampleSize == 0 {
+			d.SampleSize = datasetDefaultSampleSize
+		}
+		if d.Channels == "" {
+			d.Channels = datasetDefaultChannels
+		}
+		if err := d.validate(); err != nil {
+			return fmt.Errorf("Dataset load error: %v", err)
+		}
+		return d.Save()
+	}
+	if err != nil {
+		return fmt.Errorf("Dataset load error: %v", err)
+	}
+	if err := json.Unmarshal(data, d); err != nil {
+		return fmt.Errorf("Dataset load error: %v", err)
+	}
+	if err := d.validate(); err != nil {
+		return fmt.Errorf("Dataset load error: %v", err)
+	}
+	if (Config.Common.SampleSize != 0 && Config.Common.SampleSize != d.SampleSize) ||
+		(Config.Common.SampleChannels != "" && Config.Common.SampleChannels != d.Channels) {
+		log.Printf("Dataset is %v, SampleSize and SampleChannels of config.txt apply to new datasets only", d)
+	}
+	return nil
+}
+
+func (d *DatasetStruct) Save() error {
+	data, err := json.MarshalIndent(d, "", "    ")
+	if err != nil {
+		return fmt.Errorf("Dataset save error: %v", err)
+	}
+	if err := os.MkdirAll(d.Path(), os.ModeDir); err != nil {
+		return fmt.Errorf("Dataset save error: %v", err)
+	}
+	if err := os.WriteFile(d.Path(datasetFileName), data, 0644); err != nil {
+		return fmt.Errorf("Dataset save error: %v", err)
+	}
+	return nil
+}
+
+func (d *DatasetStruct) validate() error {
+	if d.SampleSize < 8 || d.SampleSize > 1024 {
+		return fmt.Errorf("sample size %d is out of 8..1024", d.SampleSize)
+	}
+	if d.NumChannels() == 0 {
+		return fmt.Errorf("unknown channel mode %q, %q, %q or %q expected", d.Channels, DatasetChannelsRGB, DatasetChannelsGray, DatasetChannelsRGBA)
+	}
+	return nil
+}
+
+// NumChannels returns the bytes per pixel of a sample array, 0 for an unknown
+// mode.
+func (d *DatasetStruct) NumChannels() int {
+	switch d.Channels {
+	case DatasetChannelsGray:
+		return 1
+	case DatasetChannelsRGB:
+		return 3
+	case DatasetChannelsRGBA:
+		return 4
+	}
+	return 0
+}
+
+func (d *DatasetStruct) String() string {
+	return fmt.Sprintf("%dx%d %v", d.SampleSize, d.SampleSize, d.Channels)
+}
+
+// CheckSamples refuses a training on marked files of another shape, left
+// from a dataset with other settings. Only the PNG headers are read.
+func (d *DatasetStruct) CheckSamples(paths []string) error {
+	bad := 0
+	example := ""
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return &MlError{Op: "CheckSamples", Code: ErrMlBadSample, Message: err.Error()}
+		}
+		c, _, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			return &MlError{Op: "CheckSamples", Code: ErrMlBadSample, Message: fmt.Sprintf("%v: %v", p, err)}
+		}
+		gray := c.ColorModel == color.GrayModel
+		if c.Width != d.SampleSize || c.Height != d.SampleSize || gray != (d.Channels == DatasetChannelsGray) {
+			if bad == 0 {
+				mode := "color"
+				if gray {
+					mode = "gray"
+				}
+				example = fmt.Sprintf("%v is %dx%d %v", p, c.Width, c.Height, mode)
+			}
+			bad++
+		}
+	}
+	if bad > 0 {
+		return &MlError{Op: "CheckSamples", Code: ErrMlShapeMismatch,
+			Message: fmt.Sprintf("%d of %d samples are not %v, %v", bad, len(paths), d, example)}
+	}
+	return nil
+}
+
+// datasets.json of DataRoot remembers the dataset in use and the dataset of
+// every window title, the window selection switches to it.
+const datasetsFileName = "datasets.json"
+
+type DatasetsStruct struct {
+	mut     sync.Mutex
+	Current string            `json:"Current"`
+	Windows map[string]string `json:"Windows"`
+}
+
+var Datasets DatasetsStruct
+
+// Load reads datasets.json and names Dataset after the current dataset,
+// Dataset of config.txt without one.
+func (m *DatasetsStruct) Load() error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.Current = Config.Common.Dataset
+	m.Windows = make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(Config.Common.DataRoot, datasetsFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Datasets load error: %v", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, m); err != nil {
+			return fmt.Errorf("Datasets load error: %v", err)
+		}
+		if m.Windows == nil {
+			m.Windows = make(map[string]string)
+		}
+	}
+	if err := DatasetCheckName(m.Current); err != nil {
+		return fmt.Errorf("Datasets load error: %v", err)
+	}
+	Dataset.Name = m.Current
+	return nil
+}
+
+func (m *DatasetsStruct) Save() error {
+	m.mut.Lock()
+	data, err := json.MarshalIndent(m, "", "    ")
+	m.mut.Unlock()
+	if err != nil {
+		return fmt.Errorf("Datasets save error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(Config.Common.DataRoot, "."), os.ModeDir); err != nil {
+		return fmt.Errorf("Datasets save error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(Config.Common.DataRoot, datasetsFileName), data, 0644); err != nil {
+		return fmt.Errorf("Datasets save error: %v", err)
+	}
+	return nil
+}
+
+// List returns the datasets of DataRoot, the unnamed one first if it has a
+// dataset.json.
+func (m *DatasetsStruct) List() []string {
+	s := make([]string, 0)
+	root := filepath.Join(Config.Common.DataRoot, ".")
+	if _, err := os.Stat(filepath.Join(root, datasetFileName)); err == nil {
+		s = append(s, "")
+	}
+	l, err := os.ReadDir(root)
+	if err != nil {
+		log.Println(fmt.Errorf("Datasets list error: %v", err))
+		return s
+	}
+	named := make([]string, 0)
+	for _, x := range l {
+		if !x.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, x.Name(), datasetFileName)); err == nil {
+			named = append(named, x.Name())
+		}
+	}
+	sort.Strings(named)
+	return append(s, named...)
+}
+
+// ForWindow returns the dataset remembered for the window title.
+func (m *DatasetsStruct) ForWindow(title string) (string, bool) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	name, ok := m.Windows[title]
+	return name, ok
+}
+
+// Remember makes name the dataset of the window title.
+func (m *DatasetsStruct) Remember(title string, name string) error {
+	m.mut.Lock()
+	if x, ok := m.Windows[title]; ok && x == name {
+		m.mut.Unlock()
+		return nil
+	}
+	if m.Windows == nil {
+		m.Windows = make(map[string]string)
+	}
+	m.Windows[title] = name
+	m.mut.Unlock()
+	return m.Save()
+}
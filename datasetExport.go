@@ -0,0 +1,286 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The export writes the marked data to DatasetExportFolder for other tools:
+//
+//	imagefolder/<split>/<label>/<sample>.png  class per directory trees
+//	samples.npz                               x_<split>.npy and y_<split>.npy
+//	<split>.csv                               path,label of the tree
+//	manifest.json                             shapes, labels and counts
+//
+// Samples go to a split by the hash of their source screenshot, so the tiles
+// of a frame never end up on both sides and a frame keeps its split between
+// exports.
+const (
+	DatasetExportFolder      = "datasetexport"
+	DatasetExportValPercent  = 10
+	DatasetExportTestPercent = 10
+
+	datasetExportImageFolder = "imagefolder"
+	datasetExportNpzFile     = "samples.npz"
+	datasetExportManifest    = "manifest.json"
+)
+
+var DatasetExportSplits = []string{"train", "val", "test"}
+
+type DatasetExportSplit struct {
+	Samples int   `json:"Samples"`
+	Sources int   `json:"Sources"`
+	Classes []int `json:"Classes"`
+}
+
+type DatasetExportManifest struct {
+	Created      string                         `json:"Created"`
+	Dataset      string                         `json:"Dataset"`
+	SampleSize   int                            `json:"SampleSize"`
+	Channels     string                         `json:"Channels"`
+	ClassNames   []string                       `json:"ClassNames"`
+	ClassFolders []string                       `json:"ClassFolders"`
+	SplitBy      string                         `json:"SplitBy"`
+	ValPercent   int                            `json:"ValPercent"`
+	TestPercent  int                            `json:"TestPercent"`
+	Splits       map[string]*DatasetExportSplit `json:"Splits"`
+	Skipped      int                            `json:"Skipped"`
+	ImageFolder  string                         `json:"ImageFolder"`
+	Npz          string                         `json:"Npz"`
+	Csv          []string                       `json:"Csv"`
+}
+
+type datasetExportSample struct {
+	path   string
+	class  int
+	source string
+}
+
+// DatasetSplitOf returns the split of the samples cut from source.
+func DatasetSplitOf(source string) string {
+	h := sha256.Sum256([]byte(source))
+	switch n := binary.BigEndian.Uint32(h[:]) % 100; {
+	case n < DatasetExportTestPercent:
+		return "test"
+	case n < DatasetExportTestPercent+DatasetExportValPercent:
+		return "val"
+	}
+	return "train"
+}
+
+// datasetExportClassFolders names the label folders <index>_<name>, sorted
+// by name they keep the order of config.txt, as ImageFolder numbers them.
+func datasetExportClassFolders() []string {
+	a := make([]string, len(Config.Labels))
+	for i, l := range Config.Labels {
+		name := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`<>:"/\|?*`, r) || r < ' ' {
+				return '_'
+			}
+			return r
+		}, l.Name)
+		a[i] = fmt.Sprintf("%02d_%v", i, name)
+	}
+	return a
+}
+
+// DatasetExport writes the marked samples of paths to dir. The files of an
+// earlier export in dir are replaced.
+func DatasetExport(paths []string, dir string) (*DatasetExportManifest, error) {
+	m := &DatasetExportManifest{
+		Created:      time.Now().UTC().Format(fileMetaTimeFormat),
+		Dataset:      Dataset.String(),
+		SampleSize:   Dataset.SampleSize,
+		Channels:     Dataset.Channels,
+		ClassNames:   MlConfigClassNames(),
+		ClassFolders: datasetExportClassFolders(),
+		SplitBy:      "source",
+		ValPercent:   DatasetExportValPercent,
+		TestPercent:  DatasetExportTestPercent,
+		Splits:       make(map[string]*DatasetExportSplit),
+		ImageFolder:  datasetExportImageFolder,
+		Npz:          datasetExportNpzFile,
+	}
+	bySplit := make(map[string][]datasetExportSample)
+	sources := make(map[string]map[string]bool)
+	for _, x := range DatasetExportSplits {
+		m.Splits[x] = &DatasetExportSplit{Classes: make([]int, len(Config.Labels))}
+		sources[x] = make(map[string]bool)
+	}
+	for _, p := range paths {
+		meta, err := File.MarkedMeta(p)
+		if err != nil {
+			log.Println(err)
+			m.Skipped++
+			continue
+		}
+		x := DatasetSplitOf(meta.Source)
+		bySplit[x] = append(bySplit[x], datasetExportSample{path: p, class: meta.Class, source: meta.Source})
+		sources[x][meta.Source] = true
+	}
+	for _, x := range DatasetExportSplits {
+		sort.Slice(bySplit[x], func(i, j int) bool { return bySplit[x][i].path < bySplit[x][j].path })
+		m.Splits[x].Sources = len(sources[x])
+	}
+
+	if err := os.RemoveAll(filepath.Join(dir, datasetExportImageFolder)); err != nil {
+		return nil, fmt.Errorf("Dataset export error: %v", err)
+	}
+	if err := os.MkdirAll(dir, os.ModeDir); err != nil {
+		return nil, fmt.Errorf("Dataset export error: %v", err)
+	}
+	for _, x := range DatasetExportSplits {
+		l, err := m.writeImageFolder(dir, x, bySplit[x])
+		if err != nil {
+			return nil, err
+		}
+		bySplit[x] = l
+		if err := m.writeCsv(dir, x, l); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.writeNpz(dir, bySplit); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("Dataset export error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, datasetExportManifest), data, 0644); err != nil {
+		return nil, fmt.Errorf("Dataset export error: %v", err)
+	}
+	return m, nil
+}
+
+// writeImageFolder copies the samples of split to its tree and returns the
+// ones copied, a sample that cannot be read is skipped.
+func (m *DatasetExportManifest) writeImageFolder(dir string, split string, l []datasetExportSample) ([]datasetExportSample, error) {
+	res := make([]datasetExportSample, 0, len(l))
+	for _, s := range l {
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			log.Println(fmt.Errorf("Dataset export error: %v", err))
+			m.Skipped++
+			continue
+		}
+		folder := filepath.Join(dir, datasetExportImageFolder, split, m.ClassFolders[s.class])
+		if err := os.MkdirAll(folder, os.ModeDir); err != nil {
+			return nil, fmt.Errorf("Dataset export error: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(folder, filepath.Base(s.path)), data, 0644); err != nil {
+			return nil, fmt.Errorf("Dataset export error: %v", err)
+		}
+		res = append(res, s)
+		m.Splits[split].Samples++
+		m.Splits[split].Classes[s.class]++
+	}
+	return res, nil
+}
+
+// writeCsv lists the tree of split with paths relative to dir.
+func (m *DatasetExportManifest) writeCsv(dir string, split string, l []datasetExportSample) error {
+	name := split + ".csv"
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf("Dataset export error: %v", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	w.Write([]string{"path", "label"})
+	for _, s := range l {
+		p := path.Join(datasetExportImageFolder, split, m.ClassFolders[s.class], filepath.Base(s.path))
+		w.Write([]string{p, m.ClassNames[s.class]})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("Dataset export error: %v", err)
+	}
+	m.Csv = append(m.Csv, name)
+	return nil
+}
+
+// writeNpz writes the samples as numpy.savez_compressed does: x_<split> is
+// uint8 N x SampleSize x SampleSize x channels, y_<split> the int64 labels.
+func (m *DatasetExportManifest) writeNpz(dir string, bySplit map[string][]datasetExportSample) error {
+	f, err := os.Create(filepath.Join(dir, datasetExportNpzFile))
+	if err != nil {
+		return fmt.Errorf("Dataset export error: %v", err)
+	}
+	defer f.Close()
+	z := zip.NewWriter(f)
+	size, n := Dataset.SampleSize, Dataset.NumChannels()
+	for _, x := range DatasetExportSplits {
+		l := bySplit[x]
+		w, err := z.Create("x_" + x + ".npy")
+		if err != nil {
+			return fmt.Errorf("Dataset export error: %v", err)
+		}
+		if err := datasetWriteNpyHeader(w, "|u1", len(l), size, size, n); err != nil {
+			return fmt.Errorf("Dataset export error: %v", err)
+		}
+		for _, s := range l {
+			img, _, _, err := File.LoadMarked(s.path)
+			if err != nil {
+				// The tree has it already, the arrays have to keep its place.
+				return fmt.Errorf("Dataset export error: %v", err)
+			}
+			if _, err := w.Write(*MlImageToArray(img)); err != nil {
+				return fmt.Errorf("Dataset export error: %v", err)
+			}
+		}
+		w, err = z.Create("y_" + x + ".npy")
+		if err != nil {
+			return fmt.Errorf("Dataset export error: %v", err)
+		}
+		if err := datasetWriteNpyHeader(w, "<i8", len(l)); err != nil {
+			return fmt.Errorf("Dataset export error: %v", err)
+		}
+		for _, s := range l {
+			if err := binary.Write(w, binary.LittleEndian, int64(s.class)); err != nil {
+				return fmt.Errorf("Dataset export error: %v", err)
+			}
+		}
+	}
+	if err := z.Close(); err != nil {
+		return fmt.Errorf("Dataset export error: %v", err)
+	}
+	return nil
+}
+
+// datasetWriteNpyHeader writes an NPY 1.0 header, the header is padded so the
+// data starts at a multiple of 64 bytes.
+func datasetWriteNpyHeader(w io.Writer, descr string, shape ...int) error {
+	dims := make([]string, len(shape))
+	for i, x := range shape {
+		dims[i] = fmt.Sprint(x)
+	}
+	s := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		s += ","
+	}
+	h := fmt.Sprintf("{'descr': '%v', 'fortran_order': False, 'shape': (%v), }", descr, s)
+	pad := 64 - (10+len(h)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	h += strings.Repeat(" ", pad) + "\n"
+	var b bytes.Buffer
+	b.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&b, binary.LittleEndian, uint16(len(h)))
+	b.WriteString(h)
+	_, err := w.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DatasetImportResult counts the images of an import: saved as new marked
+// data, dropped as similar to a persistent sample or to one imported before,
+// or skipped for an unknown label or an unreadable file.
+type DatasetImportResult struct {
+	Imported int
+	Similar  int
+	Skipped  int
+}
+
+type datasetImportItem struct {
+	path  string
+	label string
+}
+
+// DatasetImport adds the labelled images of src to the new marked data. src
+// is a class per directory tree, the label is the folder an image is in, or
+// a CSV of path,label with paths relative to it, as export-data writes them.
+// A label is the name of a label or its export folder.
+func DatasetImport(stop context.Context, src string) (*DatasetImportResult, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, fmt.Errorf("Dataset import error: %v", err)
+	}
+	var items []datasetImportItem
+	if info.IsDir() {
+		items, err = datasetImportTree(src)
+	} else {
+		items, err = datasetImportCsv(src)
+	}
+	if err != nil {
+		return nil, err
+	}
+	classes := make(map[string]int)
+	for i, x := range datasetExportClassFolders() {
+		classes[x] = i
+	}
+	for i, l := range Config.Labels {
+		classes[l.Name] = i
+	}
+
+	res := &DatasetImportResult{}
+	last := ""
+	// The samples of this import, the persistent ones do not know them.
+	imported := make([]*image.RGBA, 0)
+	for _, it := range items {
+		select {
+		case <-stop.Done():
+			return res, nil
+		default:
+		}
+		class, ok := classes[it.label]
+		if !ok {
+			log.Println(fmt.Errorf("Dataset import error: %v has no label %q", it.path, it.label))
+			res.Skipped++
+			continue
+		}
+		img, err := datasetImportLoad(it.path)
+		if err != nil {
+			log.Println(err)
+			res.Skipped++
+			continue
+		}
+		sample := Image.Sample(img)
+		if datasetImportSimilar(imported, sample) || Image.IsSimilarImageInPersistentMarked(stop, sample) {
+			res.Similar++
+			continue
+		}
+		source := File.CreateBaseName()
+		for source == last {
+			source = File.CreateBaseName()
+		}
+		last = source
+		origin, err := filepath.Abs(it.path)
+		if err != nil {
+			origin = it.path
+		}
+		meta := &SampleMeta{
+			Class:  class,
+			Source: source,
+			Rect:   image.Rectangle{Max: img.Rect.Size()},
+			Grid:   image.Point{X: 1, Y: 1},
+			Frame:  img.Rect.Size(),
+			Import: origin,
+		}
+		if err := File.SaveNewMarked(sample, meta); err != nil {
+			return res, fmt.Errorf("Dataset import error: %v", err)
+		}
+		imported = append(imported, sample)
+		res.Imported++
+	}
+	return res, nil
+}
+
+func datasetImportSimilar(imported []*image.RGBA, sample *image.RGBA) bool {
+	for _, x := range imported {
+		if Image.DiffRGBARMSSameSize(x, sample) < imageSimilarRMS {
+			return true
+		}
+	}
+	return false
+}
+
+func datasetImportTree(dir string) ([]datasetImportItem, error) {
+	items := make([]datasetImportItem, 0)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !datasetImportIsImage(p) || filepath.Dir(p) == filepath.Clean(dir) {
+			return nil
+		}
+		items = append(items, datasetImportItem{path: p, label: filepath.Base(filepath.Dir(p))})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Dataset import error: %v", err)
+	}
+	return items, nil
+}
+
+func datasetImportCsv(file string) ([]datasetImportItem, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Dataset import error: %v", err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	items := make([]datasetImportItem, 0)
+	for n := 1; ; n++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Dataset import error: %v", err)
+		}
+		if len(rec) < 2 {
+			return nil, fmt.Errorf("Dataset import error: %v line %d: path,label expected", file, n)
+		}
+		p, label := strings.TrimSpace(rec[0]), strings.TrimSpace(rec[1])
+		if n == 1 && p == "path" && label == "label" {
+			continue
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(file), filepath.FromSlash(p))
+		}
+		items = append(items, datasetImportItem{path: p, label: label})
+	}
+	return items, nil
+}
+
+func datasetImportIsImage(p string) bool {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	}
+	return false
+}
+
+// datasetImportLoad decodes an image of another tool, it does not go to the
+// image cache as the samples do.
+func datasetImportLoad(p string) (*image.RGBA, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("Dataset import error: %v", err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("Dataset import error: %v: %v", p, err)
+	}
+	v := image.NewRGBA(image.Rectangle{Max: img.Bounds().Size()})
+	draw.Draw(v, v.Rect, img, img.Bounds().Min, draw.Src)
+	return v, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DatasetStatsReportFile = "datasetstats.txt"
+
+// DatasetStats counts the persistent samples per label, in total and by
+// source screenshot, tile index and capture date.
+type DatasetStats struct {
+	ClassNames []string
+	Classes    []int
+	BySource   map[string][]int
+	ByTile     map[string][]int
+	ByDate     map[string][]int
+	Samples    int
+	Unreadable int
+}
+
+func DatasetCollectStats(paths []string) *DatasetStats {
+	s := &DatasetStats{
+		ClassNames: MlConfigClassNames(),
+		Classes:    make([]int, len(Config.Labels)),
+		BySource:   make(map[string][]int),
+		ByTile:     make(map[string][]int),
+		ByDate:     make(map[string][]int),
+	}
+	add := func(m map[string][]int, key string, class int) {
+		if m[key] == nil {
+			m[key] = make([]int, len(s.Classes))
+		}
+		m[key][class]++
+	}
+	for _, p := range paths {
+		meta, err := File.MarkedMeta(p)
+		if err != nil {
+			log.Println(err)
+			s.Unreadable++
+			continue
+		}
+		s.Samples++
+		s.Classes[meta.Class]++
+		add(s.BySource, meta.Source, meta.Class)
+		add(s.ByTile, fmt.Sprintf("%4d", meta.Index), meta.Class)
+		// Time is when the sample was labelled, the source tells when
+		// it was captured.
+		date := "(unknown)"
+		if t, ok := File.SourceTime(meta.Source); ok {
+			date = t.Format("2006-01-02")
+		}
+		add(s.ByDate, date, meta.Class)
+	}
+	return s
+}
+
+// Imbalance is the ratio of the largest label to the smallest one present.
+func (s *DatasetStats) Imbalance() float64 {
+	lo, hi := 0, 0
+	for _, n := range s.Classes {
+		if n > 0 && (lo == 0 || n < lo) {
+			lo = n
+		}
+		if n > hi {
+			hi = n
+		}
+	}
+	if lo == 0 {
+		return 0
+	}
+	return float64(hi) / float64(lo)
+}
+
+// Lines is the summary shown in GuiTextView and at the top of the report.
+func (s *DatasetStats) Lines() []string {
+	a := make([]string, 0)
+	a = append(a, fmt.Sprintf("Dataset %v: %d samples, %d unreadable, largest to smallest label %.1f:1", &Dataset, s.Samples, s.Unreadable, s.Imbalance()))
+	for c, name := range s.ClassNames {
+		share := 0.0
+		if s.Samples > 0 {
+			share = float64(s.Classes[c]) * 100 / float64(s.Samples)
+		}
+		a = append(a, fmt.Sprintf("   %-16s %8d %6.2f%%", name, s.Classes[c], share))
+	}
+	a = append(a, fmt.Sprintf("%d source screenshots, %d tile indexes, %d capture dates", len(s.BySource), len(s.ByTile), len(s.ByDate)))
+	return a
+}
+
+func (s *DatasetStats) table(b *strings.Builder, title string, m map[string][]int) {
+	keys := make([]string, 0, len(m))
+	w := len(title)
+	for k := range m {
+		keys = append(keys, k)
+		if len(k) > w {
+			w = len(k)
+		}
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(b, "\n%-*s", w, title)
+	for _, name := range s.ClassNames {
+		fmt.Fprintf(b, " %10s", name)
+	}
+	fmt.Fprintf(b, " %10s\n", "total")
+	for _, k := range keys {
+		fmt.Fprintf(b, "%-*s", w, k)
+		total := 0
+		for _, n := range m[k] {
+			fmt.Fprintf(b, " %10d", n)
+			total += n
+		}
+		fmt.Fprintf(b, " %10d\n", total)
+	}
+}
+
+func (s *DatasetStats) WriteReport(fileName string) error {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "Collected %v\n", time.Now().Format("2006-01-02 15:04:05"))
+	for _, l := range s.Lines() {
+		b.WriteString(l + "\n")
+	}
+	s.table(&b, "capture date", s.ByDate)
+	s.table(&b, "tile", s.ByTile)
+	s.table(&b, "source screenshot", s.BySource)
+	if err := os.WriteFile(fileName, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("WriteReport error: %v", err)
+	}
+	return nil
+}
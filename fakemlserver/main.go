@@ -0,0 +1,151 @@
+// fakemlserver stands in for mlserver.py when testing the ML server
+// lifecycle. Point MlServerInterpreter at the binary and leave MlServerScript
+// empty. It answers Test and Shutdown, keeps uploaded sample hashes, predicts
+// the background class for every tile and can be told to start slowly, to
+// crash or to drop sample uploads. The app passes only --port, so each flag
+// also takes its default from the environment, see envDefault.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"scrml/protos"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeServer struct {
+	protos.UnimplementedMessagerServer
+	shutdown       chan struct{}
+	mut            sync.Mutex
+	samples        map[string]bool
+	dropAfterChunk int
+}
+
+func (s *fakeServer) Test(ctx context.Context, in *protos.VoidMsg) (*protos.MsgError, error) {
+	return &protos.MsgError{}, nil
+}
+
+func (s *fakeServer) Shutdown(ctx context.Context, in *protos.VoidMsg) (*protos.VoidMsg, error) {
+	select {
+	case <-s.shutdown:
+	default:
+		close(s.shutdown)
+	}
+	return &protos.VoidMsg{}, nil
+}
+
+func (s *fakeServer) OpenSession(ctx context.Context, in *protos.VoidMsg) (*protos.MsgSession, error) {
+	return &protos.MsgSession{Id: 1}, nil
+}
+
+func (s *fakeServer) CloseSession(ctx context.Context, in *protos.MsgSession) (*protos.MsgError, error) {
+	return &protos.MsgError{}, nil
+}
+
+func (s *fakeServer) PredictFrame(ctx context.Context, in *protos.MsgPredFrameIn) (*protos.MsgPredFrameOut, error) {
+	out := &protos.MsgPredFrameOut{NumClasses: in.NumClasses, Probs: make([]float32, int64(len(in.Tiles))*in.NumClasses)}
+	for i := range in.Tiles {
+		out.Probs[int64(i)*in.NumClasses] = 1
+	}
+	return out, nil
+}
+
+func (s *fakeServer) SyncSamples(ctx context.Context, in *protos.MsgSampleHashes) (*protos.MsgSampleHashes, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	out := &protos.MsgSampleHashes{Session: in.Session}
+	for _, h := range in.Hashes {
+		if !s.samples[string(h)] {
+			out.Hashes = append(out.Hashes, h)
+		}
+	}
+	return out, nil
+}
+
+func (s *fakeServer) UploadSamples(stream protos.Messager_UploadSamplesServer) error {
+	n := 0
+	for chunks := 0; ; chunks++ {
+		if s.dropAfterChunk > 0 && chunks == s.dropAfterChunk {
+			return status.Error(codes.Unavailable, "dropped by -drop-after-chunk")
+		}
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&protos.MsgUploadAck{Received: int64(n)})
+		}
+		if err != nil {
+			return err
+		}
+		s.mut.Lock()
+		for _, x := range chunk.Samples {
+			s.samples[string(x.Hash)] = true
+		}
+		s.mut.Unlock()
+		n += len(chunk.Samples)
+	}
+}
+
+// envDefault is the value of FAKEMLSERVER_<NAME> for the flag name, so
+// -ready-delay is FAKEMLSERVER_READY_DELAY.
+func envDefault(name string) string {
+	return os.Getenv("FAKEMLSERVER_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")))
+}
+
+func durationFlag(name string, usage string) *time.Duration {
+	d, err := time.ParseDuration(envDefault(name))
+	if err != nil && envDefault(name) != "" {
+		log.Fatalf("%v: %v", name, err)
+	}
+	return flag.Duration(name, d, usage)
+}
+
+func intFlag(name string, usage string) *int {
+	n, err := strconv.Atoi(envDefault(name))
+	if err != nil && envDefault(name) != "" {
+		log.Fatalf("%v: %v", name, err)
+	}
+	return flag.Int(name, n, usage)
+}
+
+func main() {
+	port := flag.Int("port", 50555, "listen port")
+	readyDelay := durationFlag("ready-delay", "sleep before listening")
+	crashAfter := durationFlag("crash-after", "exit with an error after this time")
+	dropAfterChunk := intFlag("drop-after-chunk", "fail every sample upload after this many chunks")
+	flag.Parse()
+
+	time.Sleep(*readyDelay)
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	s := &fakeServer{shutdown: make(chan struct{}), samples: make(map[string]bool), dropAfterChunk: *dropAfterChunk}
+	protos.RegisterMessagerServer(srv, s)
+	go srv.Serve(lis)
+	fmt.Println("READY on port", *port)
+
+	var crash <-chan time.Time
+	if *crashAfter > 0 {
+		crash = time.After(*crashAfter)
+	}
+	select {
+	case <-s.shutdown:
+		fmt.Println("Shutdown")
+		srv.GracefulStop()
+	case <-crash:
+		fmt.Println("Crash")
+		os.Exit(1)
+	}
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"image"
+	"image/draw"
 	"image/png"
+	"log"
 	"os"
-	"path"
+	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -17,6 +20,9 @@ const fileMarkedDataFolder = "markeddata"
 const fileNewMarkedDataFolder = "newmarkeddata"
 const fileScreenshotDataSuffix = "png"
 
+// Sample times are UTC, like the base names.
+const fileMetaTimeFormat = "2006-01-02 15:04:05"
+
 type FileStruct struct{}
 
 var File FileStruct
@@ -35,11 +41,11 @@ func (m *FileStruct) CreateBaseName() string {
 
 func (m *FileStruct) SaveCapturedImage(img *image.RGBA) error {
 	s_name := fmt.Sprintf("%v.%v", m.CreateBaseName(), fileScreenshotDataSuffix)
-	err := os.MkdirAll(fileScreenshotDataFolder, os.ModeDir)
+	err := os.MkdirAll(Dataset.Path(fileScreenshotDataFolder), os.ModeDir)
 	if err != nil {
 		return fmt.Errorf("SaveCapturedImage error: %v", err)
 	}
-	err = m.SaveImage(img, path.Join(fileScreenshotDataFolder, s_name))
+	err = m.SaveImage(img, Dataset.Path(fileScreenshotDataFolder, s_name))
 	if err != nil {
 		return fmt.Errorf("SaveCapturedImage error: %v", err)
 	}
@@ -60,13 +66,33 @@ func (*FileStruct) LoadImage(filePath string) (*image.RGBA, error) {
 	if err != nil {
 		return nil, fmt.Errorf("LoadImage error: %v", err)
 	}
-	v, _ := img.(*image.RGBA)
+	v, ok := img.(*image.RGBA)
+	if !ok {
+		v = image.NewRGBA(img.Bounds())
+		draw.Draw(v, v.Rect, img, img.Bounds().Min, draw.Src)
+	}
 	FileImageCache.Put(filePath, v)
 	return v, nil
 }
 
-func (*FileStruct) SaveImage(img *image.RGBA, filePath string) error {
+func (m *FileStruct) SaveImage(img *image.RGBA, filePath string) error {
+	FileImageCache.Put(filePath, img)
+	return m.encodeImage(img, filePath)
+}
+
+// SaveSample saves a marked sample made by Image.Sample. A grayscale dataset
+// gets grayscale PNGs, so Dataset.CheckSamples can tell its samples apart.
+func (m *FileStruct) SaveSample(img *image.RGBA, filePath string) error {
+	if Dataset.Channels != DatasetChannelsGray {
+		return m.SaveImage(img, filePath)
+	}
 	FileImageCache.Put(filePath, img)
+	g := image.NewGray(img.Rect)
+	draw.Draw(g, g.Rect, img, img.Rect.Min, draw.Src)
+	return m.encodeImage(g, filePath)
+}
+
+func (*FileStruct) encodeImage(img image.Image, filePath string) error {
 	f, err := os.Create(filePath)
 	if err != nil {
 		return fmt.Errorf("SaveImage error: %v", err)
@@ -81,12 +107,15 @@ func (*FileStruct) SaveImage(img *image.RGBA, filePath string) error {
 
 func (*FileStruct) DeleteFile(path string) error {
 	FileImageCache.Delete(path)
-	return os.Remove(path)
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return SampleIndex.Forget(path)
 }
 
 func (*FileStruct) GetScreenshotList() []string {
 	s := make([]string, 0)
-	filepath.Walk(fileScreenshotDataFolder,
+	filepath.Walk(Dataset.Path(fileScreenshotDataFolder),
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
@@ -101,12 +130,12 @@ func (*FileStruct) GetScreenshotList() []string {
 
 func (*FileStruct) GetMarkedDataList() []string {
 	s := make([]string, 0)
-	filepath.Walk(fileMarkedDataFolder,
+	filepath.Walk(Dataset.Path(fileMarkedDataFolder),
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
-			if !info.IsDir() {
+			if !info.IsDir() && filepath.Ext(path) == "."+fileScreenshotDataSuffix {
 				s = append(s, path)
 			}
 			return nil
@@ -125,12 +154,12 @@ func (m *FileStruct) GetMarkedDataMap() map[string]byte {
 
 func (*FileStruct) GetNewMarkedDataList() []string {
 	s := make([]string, 0)
-	filepath.Walk(fileNewMarkedDataFolder,
+	filepath.Walk(Dataset.Path(fileNewMarkedDataFolder),
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
-			if !info.IsDir() {
+			if !info.IsDir() && filepath.Ext(path) == "."+fileScreenshotDataSuffix {
 				s = append(s, path)
 			}
 			return nil
@@ -147,75 +176,246 @@ func (m *FileStruct) GetNewMarkedDataMap() map[string]byte {
 	return x
 }
 
-// image, index, selected, error
+// image, index, class, error
 func (m *FileStruct) LoadMarked(fPath string) (*image.RGBA, int, int, error) {
+	meta, err := m.MarkedMeta(fPath)
+	if err != nil {
+		return nil, 0, 0, err
+	}
 	img, err := m.LoadImage(fPath)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("LoadMarking error: %v", err)
 	}
+	return img, meta.Index, meta.Class, nil
+}
+
+// MarkedMeta returns the metadata of a marked sample from the index, or
+// what its name tells for a sample the index does not know.
+func (m *FileStruct) MarkedMeta(fPath string) (*SampleMeta, error) {
+	if meta, ok := SampleIndex.Get(fPath); ok {
+		if meta.Class < 0 || meta.Class >= len(Config.Labels) {
+			return nil, fmt.Errorf("MARKED FILE %v CLASS ERROR: no label %d", filepath.Base(fPath), meta.Class)
+		}
+		return meta, nil
+	}
+	index, class, err := m.ParseMarkedName(fPath)
+	if err != nil {
+		return nil, err
+	}
+	meta := &SampleMeta{File: filepath.Base(fPath), Class: class, Index: index, Source: m.markedSource(fPath), Migrated: true}
+	if t, ok := m.SourceTime(meta.Source); ok {
+		meta.Time = t.Format(fileMetaTimeFormat)
+	}
+	return meta, nil
+}
+
+// SourceTime returns when the screenshot source was captured, from its base
+// name, see CreateBaseName.
+func (m *FileStruct) SourceTime(source string) (time.Time, bool) {
+	if len(source) < 14 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("20060102150405", source[:14])
+	return t, err == nil
+}
+
+// markedSource is the base name of the screenshot a sample was cut from.
+func (m *FileStruct) markedSource(fPath string) string {
 	x := strings.TrimSuffix(filepath.Base(fPath), filepath.Ext(fPath))
-	_selected, err := strconv.Atoi(filepath.Ext(x)[1:])
+	x = strings.TrimSuffix(x, filepath.Ext(x))
+	return strings.TrimSuffix(x, filepath.Ext(x))
+}
+
+// QueryMarked returns the samples filter accepts, the persistent ones or the
+// new ones.
+func (m *FileStruct) QueryMarked(newMarked bool, filter func(path string, meta *SampleMeta) bool) []string {
+	l := m.GetMarkedDataList()
+	if newMarked {
+		l = m.GetNewMarkedDataList()
+	}
+	res := make([]string, 0)
+	for _, p := range l {
+		meta, err := m.MarkedMeta(p)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if filter(p, meta) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// RelabelMarked changes the label of a sample in the index, the file keeps
+// its name.
+func (m *FileStruct) RelabelMarked(fPath string, class int) error {
+	if class < 0 || class >= len(Config.Labels) {
+		return fmt.Errorf("RelabelMarked error: no label %d", class)
+	}
+	if _, ok := SampleIndex.Get(fPath); !ok {
+		meta, err := m.MarkedMeta(fPath)
+		if err != nil {
+			return fmt.Errorf("RelabelMarked error: %v", err)
+		}
+		if err := SampleIndex.Put(fPath, meta); err != nil {
+			return fmt.Errorf("RelabelMarked error: %v", err)
+		}
+	}
+	if err := SampleIndex.Relabel(fPath, class, m.labeler(), time.Now().UTC().Format(fileMetaTimeFormat)); err != nil {
+		return fmt.Errorf("RelabelMarked error: %v", err)
+	}
+	return nil
+}
+
+func (m *FileStruct) DeleteMarked(fPath string) error {
+	if err := m.DeleteFile(fPath); err != nil {
+		return fmt.Errorf("DeleteMarked error: %v", err)
+	}
+	return nil
+}
+
+// MigrateMarkedIndex adds the samples of both marked folders the index does
+// not know yet, with what their names tell, and compacts the manifests.
+func (m *FileStruct) MigrateMarkedIndex() (int, error) {
+	n := 0
+	for _, x := range []struct {
+		dir string
+		l   []string
+	}{{Dataset.Path(fileMarkedDataFolder), m.GetMarkedDataList()}, {Dataset.Path(fileNewMarkedDataFolder), m.GetNewMarkedDataList()}} {
+		if len(x.l) == 0 {
+			continue
+		}
+		for _, p := range x.l {
+			if _, ok := SampleIndex.Get(p); ok {
+				continue
+			}
+			meta, err := m.MarkedMeta(p)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if err := SampleIndex.Put(p, meta); err != nil {
+				return n, fmt.Errorf("MigrateMarkedIndex error: %v", err)
+			}
+			n++
+		}
+		if err := SampleIndex.Compact(x.dir); err != nil {
+			return n, fmt.Errorf("MigrateMarkedIndex error: %v", err)
+		}
+	}
+	return n, nil
+}
+
+// labeler is Labeler of config.txt or the user name.
+func (m *FileStruct) labeler() string {
+	if Config.Common.Labeler != "" {
+		return Config.Common.Labeler
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// ParseMarkedName returns the index and class from a base.index.class.png name.
+func (m *FileStruct) ParseMarkedName(fPath string) (int, int, error) {
+	x := strings.TrimSuffix(filepath.Base(fPath), filepath.Ext(fPath))
+	if filepath.Ext(x) == "" {
+		return 0, 0, fmt.Errorf("MARKED FILE %v CLASS ERROR: no class", filepath.Base(fPath))
+	}
+	_class, err := strconv.Atoi(filepath.Ext(x)[1:])
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("MARKED FILE %v SELECTED SIGN ERROR: %v", filepath.Base(fPath), err)
+		return 0, 0, fmt.Errorf("MARKED FILE %v CLASS ERROR: %v", filepath.Base(fPath), err)
+	}
+	if _class < 0 || _class >= len(Config.Labels) {
+		return 0, 0, fmt.Errorf("MARKED FILE %v CLASS ERROR: no label %d", filepath.Base(fPath), _class)
 	}
 	x = strings.TrimSuffix(x, filepath.Ext(x))
+	if filepath.Ext(x) == "" {
+		return 0, 0, fmt.Errorf("MARKED FILE %v INDEX ERROR: no index", filepath.Base(fPath))
+	}
 	_index, err := strconv.Atoi(filepath.Ext(x)[1:])
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("MARKED FILE %v INDEX ERROR: %v", filepath.Base(fPath), err)
+		return 0, 0, fmt.Errorf("MARKED FILE %v INDEX ERROR: %v", filepath.Base(fPath), err)
 	}
-	return img, _index, _selected, nil
+	return _index, _class, nil
+}
+
+// HashMarked hashes the dataset shape, the class and the file bytes without
+// decoding the image, so an unchanged sample keeps its hash between trainings
+// and gets a new one when the same file is sent in another channel mode or
+// relabelled.
+func (m *FileStruct) HashMarked(fPath string) ([]byte, int, error) {
+	meta, err := m.MarkedMeta(fPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	_class := meta.Class
+	data, err := os.ReadFile(fPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("HashMarked error: %v", err)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%v:%d:", &Dataset, _class)
+	h.Write(data)
+	return h.Sum(nil), _class, nil
 }
 
 func (m *FileStruct) SaveImageToPersistent(img *image.RGBA, name string) error {
-	err := os.MkdirAll(fileMarkedDataFolder, os.ModeDir)
+	err := os.MkdirAll(Dataset.Path(fileMarkedDataFolder), os.ModeDir)
 	if err != nil {
 		return fmt.Errorf("SaveMarked error: %v", err)
 	}
-	err = m.SaveImage(img, path.Join(fileMarkedDataFolder, name))
+	err = m.SaveSample(img, Dataset.Path(fileMarkedDataFolder, name))
 	if err != nil {
 		return fmt.Errorf("SaveMarked error: %v", err)
 	}
 	return nil
 }
 
-func (m *FileStruct) SaveMarked(img *image.RGBA, baseName string, index int, selected bool) error {
-	var sel int
-	if selected {
-		sel = 1
-	}
-	s_name := fmt.Sprintf("%s.%d.%d.%s",
-		baseName,
-		index,
-		sel,
-		fileScreenshotDataSuffix)
-	err := os.MkdirAll(fileMarkedDataFolder, os.ModeDir)
+// SaveMarked saves a sample cut from the screenshot meta.Source and indexes
+// it with meta, the file is named source.index.class.png.
+func (m *FileStruct) SaveMarked(img *image.RGBA, meta *SampleMeta) error {
+	s_name := m.markedName(meta)
+	err := os.MkdirAll(Dataset.Path(fileMarkedDataFolder), os.ModeDir)
 	if err != nil {
 		return fmt.Errorf("SaveMarked error: %v", err)
 	}
-	err = m.SaveImage(img, path.Join(fileMarkedDataFolder, s_name))
+	err = m.SaveSample(img, Dataset.Path(fileMarkedDataFolder, s_name))
 	if err != nil {
 		return fmt.Errorf("SaveMarked error: %v", err)
 	}
+	if err := SampleIndex.Put(Dataset.Path(fileMarkedDataFolder, s_name), meta); err != nil {
+		return fmt.Errorf("SaveMarked error: %v", err)
+	}
 	return nil
 }
 
-func (m *FileStruct) SaveNewMarked(img *image.RGBA, baseName string, index int, selected bool) error {
-	var sel int
-	if selected {
-		sel = 1
-	}
-	s_name := fmt.Sprintf("%s.%d.%d.%s",
-		baseName,
-		index,
-		sel,
-		fileScreenshotDataSuffix)
-	err := os.MkdirAll(fileNewMarkedDataFolder, os.ModeDir)
+func (m *FileStruct) SaveNewMarked(img *image.RGBA, meta *SampleMeta) error {
+	s_name := m.markedName(meta)
+	err := os.MkdirAll(Dataset.Path(fileNewMarkedDataFolder), os.ModeDir)
 	if err != nil {
 		return fmt.Errorf("SaveNewMarked error: %v", err)
 	}
-	err = m.SaveImage(img, path.Join(fileNewMarkedDataFolder, s_name))
+	err = m.SaveSample(img, Dataset.Path(fileNewMarkedDataFolder, s_name))
 	if err != nil {
 		return fmt.Errorf("SaveNewMarked error: %v", err)
 	}
+	if err := SampleIndex.Put(Dataset.Path(fileNewMarkedDataFolder, s_name), meta); err != nil {
+		return fmt.Errorf("SaveNewMarked error: %v", err)
+	}
 	return nil
 }
+
+// markedName names the file of meta and fills in its labeler and time.
+func (m *FileStruct) markedName(meta *SampleMeta) string {
+	if meta.Labeler == "" {
+		meta.Labeler = m.labeler()
+	}
+	if meta.Time == "" {
+		meta.Time = time.Now().UTC().Format(fileMetaTimeFormat)
+	}
+	meta.File = fmt.Sprintf("%s.%d.%d.%s", meta.Source, meta.Index, meta.Class, fileScreenshotDataSuffix)
+	return meta.File
+}
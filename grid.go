@@ -14,6 +14,7 @@ const (
 
 type SelectedData struct {
 	Value float64
+	Class int
 }
 
 type SelectableData struct {
@@ -27,9 +28,8 @@ type GridStruct struct {
 	outputScreenWidth  float64
 	outputScreenHeight float64
 	Highlighted        *SelectableData
-	SamplePositive     *SelectableData
-	SampleNegative     *SelectableData
-	SignLockedOuter    bool // for debug
+	Samples            []*SelectableData // markup per label index
+	SignLockedOuter    bool              // for debug
 }
 
 var Grid GridStruct
@@ -40,17 +40,42 @@ func (g *GridStruct) InitGrid() {
 		g:    g,
 		data: make(map[int]*SelectedData),
 	}
-	Grid.SamplePositive = &SelectableData{
-		g:    g,
-		data: make(map[int]*SelectedData),
-	}
-	Grid.SampleNegative = &SelectableData{
-		g:    g,
-		data: make(map[int]*SelectedData),
+	Grid.Samples = make([]*SelectableData, len(Config.Labels))
+	for i := range Grid.Samples {
+		Grid.Samples[i] = &SelectableData{
+			g:    g,
+			data: make(map[int]*SelectedData),
+		}
 	}
 	g.mut.Unlock()
 }
 
+// SampleClass returns the label the tile is marked with or -1.
+func (g *GridStruct) SampleClass(n int) int {
+	for c, s := range g.Samples {
+		if s.IsSelected(n) {
+			return c
+		}
+	}
+	return -1
+}
+
+// SelectSample marks the tile with the label, a tile has one label at most.
+func (g *GridStruct) SelectSample(n int, class int, data *SelectedData) {
+	for c, s := range g.Samples {
+		if c != class && s.IsSelected(n) {
+			s.Deselect(n)
+		}
+	}
+	g.Samples[class].Select(n, data)
+}
+
+func (g *GridStruct) DeselectAllSamples() {
+	for _, s := range g.Samples {
+		s.DeselectAll()
+	}
+}
+
 func (g *GridStruct) LockOuter() {
 	g.SignLockedOuter = true
 	g.mutOuter.Lock()
@@ -70,12 +95,48 @@ func (g *GridStruct) UnlockOuter() {
 }
 
 func (*GridStruct) GetDomainBoundsPixels() *image.Point {
-	p := image.Point{}
-	p.Y = ImageBuffer.Get().Rect.Dy() / verticalDomains
-	p.X = int(float64(ImageBuffer.Get().Rect.Dx()) / math.Round(float64(ImageBuffer.Get().Rect.Dx()/p.Y)))
+	p := GridTileSize(ImageBuffer.Get().Rect.Size(), verticalDomains)
 	return &p
 }
 
+// GridTileSize is the tile size of a frame of size cut into vertical rows of
+// nearly square tiles.
+func GridTileSize(size image.Point, vertical int) image.Point {
+	p := image.Point{}
+	p.Y = size.Y / vertical
+	if p.Y == 0 {
+		return p
+	}
+	p.X = int(float64(size.X) / math.Round(float64(size.X/p.Y)))
+	return p
+}
+
+// GridNumXYRects is the number of columns and rows of tiles of tile size.
+func GridNumXYRects(size image.Point, tile image.Point) image.Point {
+	if tile.X == 0 || tile.Y == 0 {
+		return image.Point{}
+	}
+	return image.Point{X: size.X / tile.X, Y: size.Y / tile.Y}
+}
+
+// GridSourceRect is the tile index of a frame of size in frame pixels.
+func GridSourceRect(size image.Point, tile image.Point, index int) image.Rectangle {
+	cols := math.Round(float64(size.X) / float64(tile.X))
+
+	row := math.Floor(float64(index) / cols)
+	col := math.Floor(float64(index) - (row * cols))
+
+	x := col * float64(tile.X)
+	y := row * float64(tile.Y)
+	x_max := x + float64(tile.X)
+	y_max := y + float64(tile.Y)
+
+	return image.Rectangle{
+		image.Point{X: int(x), Y: int(y)},
+		image.Point{X: int(x_max), Y: int(y_max)},
+	}
+}
+
 func (g *GridStruct) SetOutputScreenSize(screenWidth int, screenHeight int) {
 	g.mut.Lock()
 	g.outputScreenWidth = float64(screenWidth)
@@ -86,10 +147,7 @@ func (g *GridStruct) SetOutputScreenSize(screenWidth int, screenHeight int) {
 func (g *GridStruct) NumXYRects() image.Point {
 	g.mut.Lock()
 	dsize := g.GetDomainBoundsPixels()
-	x := image.Point{
-		X: int(ImageBuffer.Get().Rect.Dx() / dsize.X),
-		Y: int(ImageBuffer.Get().Rect.Dy() / dsize.Y),
-	}
+	x := GridNumXYRects(ImageBuffer.Get().Rect.Size(), *dsize)
 	g.mut.Unlock()
 	return x
 }
@@ -102,21 +160,10 @@ func (g *GridStruct) NumRects() int {
 func (g *GridStruct) SourceRect(index int) *image.Rectangle {
 	dsize := g.GetDomainBoundsPixels()
 	g.mut.Lock()
-	cols := math.Round(float64(ImageBuffer.Get().Rect.Dx()) / float64(dsize.X))
+	size := ImageBuffer.Get().Rect.Size()
 	g.mut.Unlock()
 
-	row := math.Floor(float64(index) / cols)
-	col := math.Floor(float64(index) - (row * cols))
-
-	x := col * float64(dsize.X)
-	y := row * float64(dsize.Y)
-	x_max := x + float64(dsize.X)
-	y_max := y + float64(dsize.Y)
-
-	r := image.Rectangle{
-		image.Point{X: int(x), Y: int(y)},
-		image.Point{X: int(x_max), Y: int(y_max)},
-	}
+	r := GridSourceRect(size, *dsize, index)
 	return &r
 }
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -28,6 +29,10 @@ const (
 	SCREEN_INDEX_HELP
 	SCREEN_INDEX_SELECTWND
 	SCREEN_INDEX_NEWMARKED
+	SCREEN_INDEX_MODELS
+	SCREEN_INDEX_AUGMENT
+	SCREEN_INDEX_REVIEW
+	SCREEN_INDEX_DATASETS
 )
 
 type GuiStruct struct {
@@ -38,10 +43,19 @@ type GuiStruct struct {
 	screenMarkupData    screenMarkupStruct
 	screenHelpData      screenHelpStruct
 	screenNewMarked     screenNewMarkedStruct
+	screenModelsData    screenModelsStruct
+	screenAugmentData   screenAugmentStruct
+	screenReviewData    screenReviewStruct
+	screenDatasetsData  screenDatasetsStruct
 	texUI               GuiSDLTextureMetaStruct
 	texCaptured         GuiSDLTextureMetaStruct
 	background0         *color.RGBA
 	lockPredict         bool
+	predictMut          sync.Mutex
+	predictSession      *MlSession
+	predictBackend      MlBackend
+	predictServerRun    int
+	predictStatus       string
 }
 
 type GuiSDLTextureMetaStruct struct {
@@ -84,6 +98,7 @@ func (gs *GuiStruct) ReturnScreen() {
 func (g *GuiStruct) Init() {
 	g.screenStack = make(map[int]*GuiStackItem)
 	g.background0 = &color.RGBA{10, 10, 10, 220}
+	g.screenReviewData.reviewClassFilter = -1
 }
 
 func (g *GuiStruct) RenderAndHandle(r *sdl.Renderer) {
@@ -100,6 +115,14 @@ func (g *GuiStruct) RenderAndHandle(r *sdl.Renderer) {
 		g.renderGuiSelectWnd(r)
 	case SCREEN_INDEX_NEWMARKED:
 		g.renderGuiNewMarked(r)
+	case SCREEN_INDEX_MODELS:
+		g.renderGuiModels(r)
+	case SCREEN_INDEX_AUGMENT:
+		g.renderGuiAugment(r)
+	case SCREEN_INDEX_REVIEW:
+		g.renderGuiReview(r)
+	case SCREEN_INDEX_DATASETS:
+		g.renderGuiDatasets(r)
 	}
 }
 
@@ -110,8 +133,18 @@ type screenMainStruct struct {
 	mainEnterSelectWnd CallbackHandle
 	mainMakeScreenshot CallbackHandle
 	mainLearn          CallbackHandle
+	mainCancelLearn    CallbackHandle
 	mainNewMarked      CallbackHandle
+	mainEnterModels    CallbackHandle
+	mainEvaluate       CallbackHandle
+	mainReviewQueue    CallbackHandle
+	mainEnterAugment   CallbackHandle
+	mainStats          CallbackHandle
+	mainEnterReview    CallbackHandle
+	mainEnterDatasets  CallbackHandle
 	mainLearnLock      bool
+	mainLearnSession   *MlSession
+	mainCancelUpload   context.CancelFunc
 	mainAction         string
 }
 
@@ -119,10 +152,19 @@ func (g *GuiStruct) setGuiMain() {
 	fEnterMarkup := func(cbData InputCallbackDataI) {
 		t, _ := cbData.(*KeyboardCallbackData)
 		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.screenMarkupData.markupReview = false
 			g.CallScreen(SCREEN_INDEX_MARKUP, g.setGuiMain, g.unsetGuiMain, g.setGuiMarkup, g.unsetGuiMarkup)
 		}
 	}
 	g.screenMainData.mainEnterMarkup = UserInput.PutKeyboardCallback(Config.Keybindings.Main.Markup[0], fEnterMarkup, false)
+	fEnterReviewQueue := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.screenMarkupData.markupReview = true
+			g.CallScreen(SCREEN_INDEX_MARKUP, g.setGuiMain, g.unsetGuiMain, g.setGuiMarkup, g.unsetGuiMarkup)
+		}
+	}
+	g.screenMainData.mainReviewQueue = UserInput.PutKeyboardCallback(Config.Keybindings.Main.ReviewQueue[0], fEnterReviewQueue, false)
 	fEnterHelp := func(cbData InputCallbackDataI) {
 		t, _ := cbData.(*KeyboardCallbackData)
 		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
@@ -144,6 +186,34 @@ func (g *GuiStruct) setGuiMain() {
 		}
 	}
 	g.screenMainData.mainNewMarked = UserInput.PutKeyboardCallback(Config.Keybindings.Main.SaveNewMarked[0], fEnterNewMarked, false)
+	fEnterModels := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.CallScreen(SCREEN_INDEX_MODELS, g.setGuiMain, g.unsetGuiMain, g.setGuiModels, g.unsetGuiModels)
+		}
+	}
+	g.screenMainData.mainEnterModels = UserInput.PutKeyboardCallback(Config.Keybindings.Main.Models[0], fEnterModels, false)
+	fEnterAugment := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.CallScreen(SCREEN_INDEX_AUGMENT, g.setGuiMain, g.unsetGuiMain, g.setGuiAugment, g.unsetGuiAugment)
+		}
+	}
+	g.screenMainData.mainEnterAugment = UserInput.PutKeyboardCallback(Config.Keybindings.Main.Augment[0], fEnterAugment, false)
+	fEnterReview := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.CallScreen(SCREEN_INDEX_REVIEW, g.setGuiMain, g.unsetGuiMain, g.setGuiReview, g.unsetGuiReview)
+		}
+	}
+	g.screenMainData.mainEnterReview = UserInput.PutKeyboardCallback(Config.Keybindings.Main.Review[0], fEnterReview, false)
+	fEnterDatasets := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.CallScreen(SCREEN_INDEX_DATASETS, g.setGuiMain, g.unsetGuiMain, g.setGuiDatasets, g.unsetGuiDatasets)
+		}
+	}
+	g.screenMainData.mainEnterDatasets = UserInput.PutKeyboardCallback(Config.Keybindings.Main.Datasets[0], fEnterDatasets, false)
 	fMakeScreenshot := func(cbData InputCallbackDataI) {
 		g.screenMainData.mainAction = ": SAVING SCREENSHOT"
 		t, _ := cbData.(*KeyboardCallbackData)
@@ -165,62 +235,96 @@ func (g *GuiStruct) setGuiMain() {
 				g.screenMainData.mainLearnLock = true
 				f := func() {
 					captureTickerSetBigInterval()
+					// Cleaned here rather than on exit, so a failed call
+					// stays on the screen.
+					GuiTextView.Clean()
 					defer func() {
 						g.screenMainData.mainAction = ""
 						g.screenMainData.mainLearnLock = false
 						captureTickerSetNormalInterval()
-						GuiTextView.Clean()
 					}()
-					if Ml.ServerConnected() {
+					if Ml.Ready() {
 						g.screenMainData.mainAction = ": LEARNING"
-						Ml.Lock()
-						defer Ml.Unlock()
+						ctx := context.Background()
+						ses, err := Ml.OpenSession(ctx)
+						if err != nil {
+							g.mlError(err)
+							return
+						}
+						defer Ml.CloseSession(ctx, ses)
+						g.screenMainData.mainLearnSession = &ses
+						defer func() { g.screenMainData.mainLearnSession = nil }()
 						l := File.GetMarkedDataList()
-						if err := Ml.GRPCInitMlParams(); err != nil {
-							log.Println(err)
+						if err := Dataset.CheckSamples(l); err != nil {
+							g.mlError(err)
 							return
 						}
 						t0 := time.Now()
+						samples, err := MlMarkedSamples(l)
+						if err != nil {
+							log.Println("LOAD LEARNING DATA ERROR:", err)
+							GuiTextView.PutString(err.Error())
+							return
+						}
+						samples, heldOut := MlSplitSamples(samples)
+						GuiTextView.PutString(fmt.Sprintf("%d samples for train, %d held out for evaluation", len(samples), len(heldOut)))
+						samples, classWeights := MlBalanceSamples(samples)
+						if Config.Balancing.Mode != "" && Config.Balancing.Mode != MlBalanceOff {
+							GuiTextView.PutString(fmt.Sprintf("%d samples for train after %v balancing", len(samples), Config.Balancing.Mode))
+						}
+						if err := Ml.InitParams(ctx, ses, classWeights); err != nil {
+							g.mlError(err)
+							return
+						}
+						if Config.Augmentation.Copies > 0 {
+							samples = Augment.Samples(samples)
+							GuiTextView.PutString(fmt.Sprintf("%d samples with %d augmented copies each", len(samples), Config.Augmentation.Copies))
+						}
 						t1 := time.Now()
-						recvAccSamples := 0
+						lastSent := 0
 						var lo string
-						for k, x := range l {
-							img, _, s, err := File.LoadMarked(x)
-							if err == nil {
-								array := Ml.ImageToArray(img)
-								if err := Ml.GRPCSendTrainingSampleData(array, byte(s)); err != nil {
-									log.Println(err)
-									return
-								}
-								recvAccSamples++
-							} else {
-								log.Println("LOAD LEARNING DATA ERROR:", err)
-								return
-							}
-							s2 := fmt.Sprintf("Loading samples for train... %v%%", int(float64(k+1)*100/float64(len(l))))
+						fUploadProgress := func(sent, total int) {
+							s2 := fmt.Sprintf("Uploading samples for train... %v%% of %d new, %d total", int(float64(sent)*100/math.Max(float64(total), 1)), total, len(samples))
 							if s2 != lo {
-								samplesPerSecond := float64(recvAccSamples) / time.Since(t1).Seconds()
+								samplesPerSecond := float64(sent-lastSent) / time.Since(t1).Seconds()
 								fmt.Println(s2 + fmt.Sprintf(" %.3f smpls/s", samplesPerSecond))
 								GuiTextView.PutString(s2 + fmt.Sprintf(" %.3f smpls/s", samplesPerSecond))
 								t1 = time.Now()
-								recvAccSamples = 0
+								lastSent = sent
 								lo = s2
 							}
 						}
+						uploadCtx, cancelUpload := context.WithCancel(ctx)
+						g.screenMainData.mainCancelUpload = cancelUpload
+						err = Ml.UploadSamples(uploadCtx, ses, samples, fUploadProgress)
+						g.screenMainData.mainCancelUpload = nil
+						cancelUpload()
+						if uploadCtx.Err() != nil {
+							GuiTextView.PutString("Training cancelled")
+							return
+						}
+						if err != nil {
+							g.mlError(err)
+							return
+						}
 						fmt.Printf("Loading OK, %v seconds\n", time.Since(t0).Seconds())
 						GuiTextView.PutString(fmt.Sprintf("Loading OK, %v seconds\n", time.Since(t0).Seconds()))
 						fmt.Printf("Train model... ")
 						GuiTextView.PutString("Train model...")
 						t0 = time.Now()
-						if err := Ml.GRPCTrain(); err != nil {
-							log.Println(err)
+						fProgress := func(p MlTrainProgress) {
+							log.Println(p)
+							GuiTextView.PutString(p.String())
+						}
+						if err := Ml.Train(ctx, ses, fProgress); err != nil {
+							g.mlError(err)
 							return
 						}
 						s := fmt.Sprintf("Ok, %v seconds", time.Since(t0).Seconds())
 						fmt.Println(s)
 						GuiTextView.PutString(s)
 					} else {
-						s := fmt.Sprintf("No server connection")
+						s := fmt.Sprintf("ML backend is not ready")
 						log.Println(s)
 						GuiTextView.PutString(s)
 					}
@@ -230,6 +334,42 @@ func (g *GuiStruct) setGuiMain() {
 		}
 	}
 	g.screenMainData.mainLearn = UserInput.PutKeyboardCallback(Config.Keybindings.Main.Learn[0], fLearn, false)
+	fCancelLearn := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			if cancel := g.screenMainData.mainCancelUpload; cancel != nil {
+				cancel()
+			}
+			if ses := g.screenMainData.mainLearnSession; ses != nil {
+				if err := Ml.CancelTrain(context.Background(), *ses); err != nil {
+					g.mlError(err)
+				} else {
+					GuiTextView.PutString("Cancel training...")
+				}
+			}
+		}
+	}
+	g.screenMainData.mainCancelLearn = UserInput.PutKeyboardCallback(Config.Keybindings.Main.CancelLearn[0], fCancelLearn, false)
+	fEvaluate := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			if !g.screenMainData.mainLearnLock {
+				g.screenMainData.mainLearnLock = true
+				go g.mainEvaluate()
+			}
+		}
+	}
+	g.screenMainData.mainEvaluate = UserInput.PutKeyboardCallback(Config.Keybindings.Main.Evaluate[0], fEvaluate, false)
+	fStats := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			if !g.screenMainData.mainLearnLock {
+				g.screenMainData.mainLearnLock = true
+				go g.mainStats()
+			}
+		}
+	}
+	g.screenMainData.mainStats = UserInput.PutKeyboardCallback(Config.Keybindings.Main.Stats[0], fStats, false)
 	GuiTextView.SetNumLines(TextDrawer.GetNumLines() - 3)
 	GuiTextView.Clean()
 }
@@ -241,6 +381,14 @@ func (g *GuiStruct) unsetGuiMain() {
 	UserInput.RemoveKeyboardCallback(g.screenMainData.mainMakeScreenshot)
 	UserInput.RemoveKeyboardCallback(g.screenMainData.mainNewMarked)
 	UserInput.RemoveKeyboardCallback(g.screenMainData.mainLearn)
+	UserInput.RemoveKeyboardCallback(g.screenMainData.mainCancelLearn)
+	UserInput.RemoveKeyboardCallback(g.screenMainData.mainEnterModels)
+	UserInput.RemoveKeyboardCallback(g.screenMainData.mainEvaluate)
+	UserInput.RemoveKeyboardCallback(g.screenMainData.mainReviewQueue)
+	UserInput.RemoveKeyboardCallback(g.screenMainData.mainEnterAugment)
+	UserInput.RemoveKeyboardCallback(g.screenMainData.mainStats)
+	UserInput.RemoveKeyboardCallback(g.screenMainData.mainEnterReview)
+	UserInput.RemoveKeyboardCallback(g.screenMainData.mainEnterDatasets)
 }
 
 func (g *GuiStruct) renderGuiMain(renderer *sdl.Renderer) {
@@ -251,18 +399,22 @@ func (g *GuiStruct) renderGuiMain(renderer *sdl.Renderer) {
 	}
 
 	if Grid.TryLockOuter() {
-		for i := range Grid.Highlighted.Selected() {
+		for _, i := range Grid.Highlighted.Selected() {
 			r := Grid.TargetSdlRect(i)
 			d := Grid.Highlighted.DataFromSelected(i)
-			v := d.Value
-			renderer.SetDrawColor(0xff, 0x00, 0x00, byte(v*0.9*255))
+			c := Config.Labels[d.Class].RGBA()
+			renderer.SetDrawColor(c.R, c.G, c.B, byte(d.Value*0.9*255))
 			renderer.FillRect(r)
 		}
 		Grid.UnlockOuter()
 	}
+	renderer.SetDrawColor(0xff, 0xff, 0xff, 0xff)
+	for _, i := range TileFilter.Changed() {
+		renderer.DrawRect(Grid.TargetSdlRect(i))
+	}
 
 	TextDrawer.PrepareDrawing()
-	TextDrawer.Draw(fmt.Sprintf("PROCESSING%v", g.screenMainData.mainAction), 1, 0)
+	TextDrawer.Draw(fmt.Sprintf("PROCESSING%v%v", g.screenMainData.mainAction, g.predictStatus), 1, 0)
 	n := 2
 	for _, l := range GuiTextView.GetLines() {
 		n++
@@ -280,42 +432,225 @@ func (g *GuiStruct) predictCycle() {
 		g.lockPredict = true
 		f := func() {
 			defer func() { g.lockPredict = false }()
-			if Ml.ServerConnected() && ImageBuffer.Get() != nil {
-				Ml.Lock()
-				if err := Ml.GRPCInitMlParams(); err == nil {
-					fmt.Printf("Loading samples... ")
-					t0 := time.Now()
-					for i := 0; i < Grid.NumRects(); i++ {
-						rect := Grid.SourceRect(i)
-						sub_img := ImageBuffer.GetSub(rect)
-						array := Ml.ImageToArray(sub_img)
-						if err := Ml.GRPCSendPredictSampleData(array); err != nil {
-							break
-						}
+			frame := ImageBuffer.Get()
+			if Ml.Ready() && frame != nil {
+				ctx := context.Background()
+				ses, err := g.predictSessionOpen(ctx)
+				if err != nil {
+					g.mlError(err)
+					return
+				}
+				// Tiles come from one frame, the review queue saves it.
+				tiles := make([]*image.RGBA, 0, Grid.NumRects())
+				rects := make([]*image.Rectangle, 0, Grid.NumRects())
+				for i := 0; i < Grid.NumRects(); i++ {
+					rect := Grid.SourceRect(i)
+					tile, _ := frame.SubImage(*rect).(*image.RGBA)
+					tiles = append(tiles, tile)
+					rects = append(rects, rect)
+				}
+				fmt.Printf("Prediction... ")
+				t0 := time.Now()
+				probs, err := Ml.Predict(ctx, ses, tiles)
+				fmt.Printf("Ok, %.3f seconds\n", time.Since(t0).Seconds())
+				if err != nil {
+					switch {
+					case errors.Is(err, ErrMlNoModel):
+						// Shown in the caption, repeating it every frame
+						// would flood the text view.
+						g.predictStatus = " [NO MODEL]"
+					case errors.Is(err, ErrMlNoSession), errors.Is(err, ErrMlUnavailable):
+						// The next cycle opens another one.
+						g.mlError(err)
+						g.predictSessionDrop()
+					default:
+						g.mlError(err)
 					}
-					fmt.Printf("Ok, %.3f seconds\n", time.Since(t0).Seconds())
-					fmt.Printf("Prediction... ")
-					t0 = time.Now()
-					data, err := Ml.GRPCPredict()
-					fmt.Printf("Ok, %.3f seconds\n", time.Since(t0).Seconds())
-					if err != nil {
-						log.Println(err)
-					} else {
-						Grid.LockOuter()
-						Grid.Highlighted.DeselectAll()
-						for i, v := range data {
-							Grid.Highlighted.Select(i, &SelectedData{Value: float64(v) / 255.0})
-						}
-						Grid.UnlockOuter()
+				} else {
+					g.predictStatus = ""
+					predicted := make([]*SelectedData, len(probs))
+					for i, p := range probs {
+						predicted[i] = MlTopLabel(p)
+					}
+					// The review queue wants the model's own doubts, the
+					// tiles show the filtered ones.
+					lit := TileFilter.Filter(probs)
+					Grid.LockOuter()
+					Grid.Highlighted.DeselectAll()
+					for i, d := range lit {
+						Grid.Highlighted.Select(i, d)
+					}
+					Grid.UnlockOuter()
+					if g.screenIndex == SCREEN_INDEX_MAIN {
+						ReviewQueue.Collect(frame, rects, predicted)
+						Rules.Check(frame, rects, probs)
+						Api.Publish(frame, rects, probs, lit)
 					}
 				}
-				Ml.Unlock()
 			}
 		}
 		go f()
 	}
 }
 
+// predictSessionOpen returns the prediction session, opened on first use. A
+// session of another backend is closed, one of an earlier run of the ML
+// server is dropped: the new server does not know it and may give its id to
+// another session.
+func (g *GuiStruct) predictSessionOpen(ctx context.Context) (MlSession, error) {
+	g.predictMut.Lock()
+	defer g.predictMut.Unlock()
+	if g.predictSession != nil && g.predictBackend != Ml {
+		if err := g.predictBackend.CloseSession(ctx, *g.predictSession); err != nil {
+			log.Println(err)
+		}
+		g.predictSession = nil
+	}
+	if g.predictSession != nil && g.predictServerRun != MlGRPC.Run() {
+		g.predictSession = nil
+	}
+	if g.predictSession == nil {
+		ses, err := Ml.OpenSession(ctx)
+		if err != nil {
+			return 0, err
+		}
+		g.predictSession, g.predictBackend, g.predictServerRun = &ses, Ml, MlGRPC.Run()
+	}
+	return *g.predictSession, nil
+}
+
+func (g *GuiStruct) predictSessionDrop() {
+	g.predictMut.Lock()
+	g.predictSession = nil
+	g.predictMut.Unlock()
+}
+
+// ClosePredictSession closes the prediction session, main calls it before
+// the ML server stops.
+func (g *GuiStruct) ClosePredictSession() {
+	g.predictMut.Lock()
+	defer g.predictMut.Unlock()
+	if g.predictSession == nil {
+		return
+	}
+	if err := g.predictBackend.CloseSession(context.Background(), *g.predictSession); err != nil {
+		log.Println(err)
+	}
+	g.predictSession = nil
+}
+
+// mlError shows a backend error in the text view too, a timed out call
+// should not look like a frozen screen.
+func (g *GuiStruct) mlError(err error) {
+	log.Println(err)
+	GuiTextView.PutString(err.Error())
+	if h := MlErrorHint(err); h != "" {
+		GuiTextView.PutString("   " + h)
+	}
+}
+
+// mainStats shows the label counts of the persistent samples and writes
+// them by source, tile and date to DatasetStatsReportFile.
+func (g *GuiStruct) mainStats() {
+	GuiTextView.Clean()
+	defer func() {
+		g.screenMainData.mainAction = ""
+		g.screenMainData.mainLearnLock = false
+	}()
+	g.screenMainData.mainAction = ": STATISTICS"
+	s := DatasetCollectStats(File.GetMarkedDataList())
+	for _, l := range s.Lines() {
+		GuiTextView.PutString(l)
+	}
+	if err := s.WriteReport(DatasetStatsReportFile); err != nil {
+		log.Println(err)
+		GuiTextView.PutString(err.Error())
+		return
+	}
+	GuiTextView.PutString(fmt.Sprintf("Per source, tile and date in %v", DatasetStatsReportFile))
+}
+
+// mainEvaluate runs the active model over the held-out samples, shows the
+// metrics and writes the misclassified samples to MlEvaluationReportFile.
+func (g *GuiStruct) mainEvaluate() {
+	captureTickerSetBigInterval()
+	GuiTextView.Clean()
+	defer func() {
+		g.screenMainData.mainAction = ""
+		g.screenMainData.mainLearnLock = false
+		captureTickerSetNormalInterval()
+	}()
+	if !Ml.Ready() {
+		s := fmt.Sprintf("ML backend is not ready")
+		log.Println(s)
+		GuiTextView.PutString(s)
+		return
+	}
+	g.screenMainData.mainAction = ": EVALUATING"
+	ctx := context.Background()
+	l := File.GetMarkedDataList()
+	if err := Dataset.CheckSamples(l); err != nil {
+		g.mlError(err)
+		return
+	}
+	samples, err := MlMarkedSamples(l)
+	if err != nil {
+		log.Println("LOAD EVALUATION DATA ERROR:", err)
+		GuiTextView.PutString(err.Error())
+		return
+	}
+	_, heldOut := MlSplitSamples(samples)
+	if len(heldOut) == 0 {
+		g.mlError(&MlError{Op: "evaluate", Code: ErrMlNoOutData, Message: "no held-out samples, mark more"})
+		return
+	}
+	version := ""
+	if models, err := Ml.ListModels(ctx); err == nil {
+		for _, m := range models {
+			if m.Active {
+				version = m.Version
+			}
+		}
+	}
+	ses, err := Ml.OpenSession(ctx)
+	if err != nil {
+		g.mlError(err)
+		return
+	}
+	defer Ml.CloseSession(ctx, ses)
+	if err := Ml.InitParams(ctx, ses, nil); err != nil {
+		g.mlError(err)
+		return
+	}
+	lo := ""
+	fProgress := func(sent, total int) {
+		s := fmt.Sprintf("Evaluating %d held-out samples... %v%%", len(heldOut), int(float64(sent)*100/math.Max(float64(total), 1)))
+		if s != lo {
+			GuiTextView.PutString(s)
+			lo = s
+		}
+	}
+	t0 := time.Now()
+	predicted, err := Ml.Evaluate(ctx, ses, heldOut, fProgress)
+	if err != nil {
+		g.mlError(err)
+		return
+	}
+	e := MlEvaluate(version, heldOut, predicted)
+	for _, s := range e.Lines() {
+		log.Println(s)
+		GuiTextView.PutString(s)
+	}
+	if err := e.WriteReport(MlEvaluationReportFile); err != nil {
+		log.Println(err)
+		GuiTextView.PutString(err.Error())
+		return
+	}
+	s := fmt.Sprintf("%d misclassified samples are listed in %v, %v seconds", len(e.Misclassified), MlEvaluationReportFile, time.Since(t0).Seconds())
+	log.Println(s)
+	GuiTextView.PutString(s)
+}
+
 // MAIN END
 
 // MARKUP BEGIN
@@ -327,19 +662,34 @@ type screenMarkupStruct struct {
 	markupEnterHelp       CallbackHandle
 	markupSaveMarkupKey   CallbackHandle
 	markupIgnoreMarkupKey CallbackHandle
+	markupNextLabelKey    CallbackHandle
 	markupBrushModePaint  bool
+	markupBrushClass      int
+	markupActiveClass     int
 	markupBrushBrushed    map[int]byte
 	markupScrShotList     []string
 	markupAction          string
+	// markupReview walks the review queue instead of the screenshots,
+	// markupReviewTiles are the uncertain tiles of the current frame.
+	markupReview      bool
+	markupReviewTiles []int
 
 	markupMode int
 }
 
 func (g *GuiStruct) setGuiMarkup() {
 	ImageBuffer.LockOuter()
+	if g.screenMarkupData.markupActiveClass == 0 {
+		g.screenMarkupData.markupActiveClass = 1
+	}
 	localDeleteImage := func() {
 		if len(g.screenMarkupData.markupScrShotList) != 0 {
-			err := File.DeleteFile(g.screenMarkupData.markupScrShotList[0])
+			var err error
+			if g.screenMarkupData.markupReview {
+				err = ReviewQueue.Delete(g.screenMarkupData.markupScrShotList[0])
+			} else {
+				err = File.DeleteFile(g.screenMarkupData.markupScrShotList[0])
+			}
 			if err != nil {
 				log.Println("DELETE FILE ERROR:", err)
 			}
@@ -350,6 +700,15 @@ func (g *GuiStruct) setGuiMarkup() {
 		for {
 			if len(g.screenMarkupData.markupScrShotList) != 0 {
 				img, err := File.LoadImage(g.screenMarkupData.markupScrShotList[0])
+				g.screenMarkupData.markupReviewTiles = nil
+				if err == nil && g.screenMarkupData.markupReview {
+					var e *ReviewEntry
+					if e, err = ReviewQueue.Load(g.screenMarkupData.markupScrShotList[0]); err == nil {
+						for _, t := range e.Tiles {
+							g.screenMarkupData.markupReviewTiles = append(g.screenMarkupData.markupReviewTiles, t.Index)
+						}
+					}
+				}
 				if err != nil {
 					log.Println("LOAD SCREENSHOT ERROR:", err)
 					localDeleteImage()
@@ -357,8 +716,7 @@ func (g *GuiStruct) setGuiMarkup() {
 					ImageBuffer.Put(img)
 					g.screenMarkupData.markupAction = g.screenMarkupData.markupScrShotList[0]
 					Grid.LockOuter()
-					Grid.SamplePositive.DeselectAll()
-					Grid.SampleNegative.DeselectAll()
+					Grid.DeselectAllSamples()
 					Grid.UnlockOuter()
 					return nil
 				}
@@ -370,26 +728,24 @@ func (g *GuiStruct) setGuiMarkup() {
 
 	d := SelectedData{Value: 0xff / 2}
 
-	fBtn1 := func(cbData InputCallbackDataI) {
-		t, _ := cbData.(*MouseCallbackData)
+	// Left button paints the active label, right button the background one.
+	fBrush := func(t *MouseCallbackData, mode int, class int) {
 		if t.CbEvType == CALLBACK_EVENT_MOUSEBTNPUSH {
-			g.screenMarkupData.markupMode = 1
+			g.screenMarkupData.markupMode = mode
+			g.screenMarkupData.markupBrushClass = class
 			g.screenMarkupData.markupBrushBrushed = make(map[int]byte)
 			n := Grid.RectAtTarget(int(t.X), int(t.Y))
 			if n != -1 {
 				Grid.LockOuter()
 				g.screenMarkupData.markupBrushBrushed[n] = 0
-				if Grid.SampleNegative.IsSelected(n) {
-					Grid.SampleNegative.Deselect(n)
-				}
-				if Grid.SamplePositive.IsSelected(n) {
+				if Grid.Samples[class].IsSelected(n) {
 					g.screenMarkupData.markupBrushModePaint = false
-					Grid.SamplePositive.Deselect(n)
+					Grid.Samples[class].Deselect(n)
 					Grid.UnlockOuter()
 					return
 				}
 				g.screenMarkupData.markupBrushModePaint = true
-				Grid.SamplePositive.Select(n, &d)
+				Grid.SelectSample(n, class, &d)
 				Grid.UnlockOuter()
 			}
 		} else if t.CbEvType == CALLBACK_EVENT_MOUSEBTNRELEASE {
@@ -399,36 +755,16 @@ func (g *GuiStruct) setGuiMarkup() {
 			}
 		}
 	}
+
+	fBtn1 := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*MouseCallbackData)
+		fBrush(t, 1, g.screenMarkupData.markupActiveClass)
+	}
 	g.screenMarkupData.markupPosBtn = UserInput.PutMouseBtnCallback(1, fBtn1)
 
 	fBtn3 := func(cbData InputCallbackDataI) {
 		t, _ := cbData.(*MouseCallbackData)
-		if t.CbEvType == CALLBACK_EVENT_MOUSEBTNPUSH {
-			g.screenMarkupData.markupMode = 3
-			g.screenMarkupData.markupBrushBrushed = make(map[int]byte)
-			n := Grid.RectAtTarget(int(t.X), int(t.Y))
-			if n != -1 {
-				Grid.LockOuter()
-				g.screenMarkupData.markupBrushBrushed[n] = 0
-				if Grid.SamplePositive.IsSelected(n) {
-					Grid.SamplePositive.Deselect(n)
-				}
-				if Grid.SampleNegative.IsSelected(n) {
-					g.screenMarkupData.markupBrushModePaint = false
-					Grid.SampleNegative.Deselect(n)
-					Grid.UnlockOuter()
-					return
-				}
-				g.screenMarkupData.markupBrushModePaint = true
-				Grid.SampleNegative.Select(n, &d)
-				Grid.UnlockOuter()
-			}
-		} else if t.CbEvType == CALLBACK_EVENT_MOUSEBTNRELEASE {
-			g.screenMarkupData.markupMode = 0
-			for k := range g.screenMarkupData.markupBrushBrushed {
-				delete(g.screenMarkupData.markupBrushBrushed, k)
-			}
-		}
+		fBrush(t, 3, 0)
 	}
 	g.screenMarkupData.markupNegBtn = UserInput.PutMouseBtnCallback(3, fBtn3)
 
@@ -437,45 +773,36 @@ func (g *GuiStruct) setGuiMarkup() {
 		n := Grid.RectAtTarget(int(t.X), int(t.Y))
 		if g.screenMarkupData.markupMode != 0 && n != -1 {
 			if _, ok := g.screenMarkupData.markupBrushBrushed[n]; !ok {
+				class := g.screenMarkupData.markupBrushClass
+				Grid.LockOuter()
 				if g.screenMarkupData.markupBrushModePaint {
-					Grid.LockOuter()
-					switch g.screenMarkupData.markupMode {
-					case 1:
-						if !Grid.SamplePositive.IsSelected(n) {
-							Grid.SamplePositive.Select(n, &d)
-						}
-						if Grid.SampleNegative.IsSelected(n) {
-							Grid.SampleNegative.Deselect(n)
-						}
-					case 3:
-						if !Grid.SampleNegative.IsSelected(n) {
-							Grid.SampleNegative.Select(n, &d)
-						}
-						if Grid.SamplePositive.IsSelected(n) {
-							Grid.SamplePositive.Deselect(n)
-						}
+					if !Grid.Samples[class].IsSelected(n) {
+						Grid.SelectSample(n, class, &d)
 					}
-					Grid.UnlockOuter()
 				} else {
-					Grid.LockOuter()
-					switch g.screenMarkupData.markupMode {
-					case 1:
-						if Grid.SamplePositive.IsSelected(n) {
-							Grid.SamplePositive.Deselect(n)
-						}
-					case 3:
-						if Grid.SampleNegative.IsSelected(n) {
-							Grid.SampleNegative.Deselect(n)
-						}
+					if Grid.Samples[class].IsSelected(n) {
+						Grid.Samples[class].Deselect(n)
 					}
-					Grid.UnlockOuter()
 				}
+				Grid.UnlockOuter()
 				g.screenMarkupData.markupBrushBrushed[n] = 0
 			}
 		}
 	}
 	g.screenMarkupData.markupMouseMotion = UserInput.PutMouseMotionCallback(fMMotion)
 
+	fNextLabel := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			c := g.screenMarkupData.markupActiveClass + 1
+			if c >= len(Config.Labels) {
+				c = 1
+			}
+			g.screenMarkupData.markupActiveClass = c
+		}
+	}
+	g.screenMarkupData.markupNextLabelKey = UserInput.PutKeyboardCallback(Config.Keybindings.Markup.NextLabel[0], fNextLabel, false)
+
 	fExitKey := func(cbData InputCallbackDataI) {
 		t, _ := cbData.(*KeyboardCallbackData)
 		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
@@ -493,21 +820,35 @@ func (g *GuiStruct) setGuiMarkup() {
 	g.screenMarkupData.markupEnterHelp = UserInput.PutKeyboardCallback(Config.Keybindings.Markup.Help[0], fEnterHelp, false)
 
 	fSaveMarkup := func(cbData InputCallbackDataI) {
-		saveHelper := func(i int, isPositive bool) {
+		var f_sub_name string
+		if len(g.screenMarkupData.markupScrShotList) != 0 {
+			f_sub_name = strings.TrimSuffix(filepath.Base(g.screenMarkupData.markupScrShotList[0]), filepath.Ext(g.screenMarkupData.markupScrShotList[0]))
+		} else {
+			f_sub_name = File.CreateBaseName()
+		}
+		saveHelper := func(i int, class int) {
 			rect := Grid.SourceRect(i)
 			sub_img := ImageBuffer.GetSub(rect)
-			sub_img_resized := Image.Resize(sub_img, MarkedImageSizePixels)
+			sub_img_resized := Image.Sample(sub_img)
 			sub_img = nil
-			var f_sub_name string
-			if len(g.screenMarkupData.markupScrShotList) != 0 {
-				f_sub_name = strings.TrimSuffix(filepath.Base(g.screenMarkupData.markupScrShotList[0]), filepath.Ext(g.screenMarkupData.markupScrShotList[0]))
-			} else {
-				f_sub_name = File.CreateBaseName()
+			meta := &SampleMeta{
+				Class:  class,
+				Index:  i,
+				Source: f_sub_name,
+				Window: targetWindowTitle,
+				Rect:   *rect,
+				Grid:   Grid.NumXYRects(),
+				Frame:  ImageBuffer.Get().Rect.Size(),
 			}
+			var err error
 			if Config.Common.SaveMarkedToPersistent[0] == '1' {
-				File.SaveMarked(sub_img_resized, f_sub_name, i, isPositive)
+				err = File.SaveMarked(sub_img_resized, meta)
 			} else {
-				File.SaveNewMarked(sub_img_resized, f_sub_name, i, isPositive)
+				err = File.SaveNewMarked(sub_img_resized, meta)
+			}
+			if err != nil {
+				log.Println(err)
+				GuiTextView.PutString(err.Error())
 			}
 		}
 		defer func() {
@@ -518,19 +859,20 @@ func (g *GuiStruct) setGuiMarkup() {
 		g.screenMarkupData.markupAction = g.screenMarkupData.markupAction + ": SAVING MARKUP DATA"
 		t, _ := cbData.(*KeyboardCallbackData)
 		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
-			// if Grid.SampleNegative.SelectedAmount() != 0 {
-			for _, i := range Grid.SamplePositive.Selected() {
-				saveHelper(i, true)
+			marked := make(map[int]int)
+			for c, samples := range Grid.Samples {
+				for _, i := range samples.Selected() {
+					saveHelper(i, c)
+					marked[i] = c
+				}
 			}
-			for _, i := range Grid.SampleNegative.Selected() {
-				saveHelper(i, false)
+			// The project keeps the frame, the screenshot can go.
+			if Project.Enabled() && len(marked) != 0 {
+				if err := Project.SaveFrame(f_sub_name, ImageBuffer.Get(), marked); err != nil {
+					log.Println(err)
+					GuiTextView.PutString(err.Error())
+				}
 			}
-			// } else {
-			// 	for i := 0; i < Grid.NumRects(); i++ {
-			// 		sub_img_sel := Grid.SamplePositive.IsSelected(i)
-			// 		saveHelper(i, sub_img_sel)
-			// 	}
-			// }
 			localDeleteImage()
 			if localLoadImageOrDelete() != nil {
 				g.guiMarkupExit()
@@ -555,7 +897,12 @@ func (g *GuiStruct) setGuiMarkup() {
 	}
 	g.screenMarkupData.markupIgnoreMarkupKey = UserInput.PutKeyboardCallback(Config.Keybindings.Markup.Ignore[0], fIgnoreMarkup, false)
 
-	g.screenMarkupData.markupScrShotList = File.GetScreenshotList()
+	g.screenMarkupData.markupReviewTiles = nil
+	if g.screenMarkupData.markupReview {
+		g.screenMarkupData.markupScrShotList = ReviewQueue.List()
+	} else {
+		g.screenMarkupData.markupScrShotList = File.GetScreenshotList()
+	}
 	if len(g.screenMarkupData.markupScrShotList) != 0 {
 		if localLoadImageOrDelete() != nil {
 			g.guiMarkupExit()
@@ -566,8 +913,7 @@ func (g *GuiStruct) setGuiMarkup() {
 
 func (g *GuiStruct) guiMarkupExit() {
 	Grid.LockOuter()
-	Grid.SamplePositive.DeselectAll()
-	Grid.SampleNegative.DeselectAll()
+	Grid.DeselectAllSamples()
 	Grid.UnlockOuter()
 	g.ReturnScreen()
 }
@@ -580,6 +926,7 @@ func (g *GuiStruct) unsetGuiMarkup() {
 	UserInput.RemoveKeyboardCallback(g.screenMarkupData.markupEnterHelp)
 	UserInput.RemoveKeyboardCallback(g.screenMarkupData.markupSaveMarkupKey)
 	UserInput.RemoveKeyboardCallback(g.screenMarkupData.markupIgnoreMarkupKey)
+	UserInput.RemoveKeyboardCallback(g.screenMarkupData.markupNextLabelKey)
 	ImageBuffer.UnlockOuter()
 }
 
@@ -602,32 +949,37 @@ func (g *GuiStruct) renderGuiMarkup(renderer *sdl.Renderer) {
 	highlightedGrid := Grid.Highlighted.Selected()
 	for _, n := range highlightedGrid {
 		r := Grid.TargetSdlRect(n)
-		expect := Grid.Highlighted.DataFromSelected(n).Value
-		b := byte(float64(0xff) * expect)
-		renderer.SetDrawColor(0xff, 0x00, 0x00, b/2)
+		d := Grid.Highlighted.DataFromSelected(n)
+		c := Config.Labels[d.Class].RGBA()
+		b := byte(float64(0xff) * d.Value)
+		renderer.SetDrawColor(c.R, c.G, c.B, b/2)
 		renderer.FillRect(r)
 	}
-	positiveGrid := Grid.SamplePositive.Selected()
-	for _, n := range positiveGrid {
-		r := Grid.TargetSdlRect(n)
-		expect := Grid.SamplePositive.DataFromSelected(n).Value
-		b := byte(float64(0xff) * expect)
-		renderer.SetDrawColor(0x00, 0xff, 0x00, b/2)
-		renderer.FillRect(r)
+	for class, samples := range Grid.Samples {
+		c := Config.Labels[class].RGBA()
+		for _, n := range samples.Selected() {
+			r := Grid.TargetSdlRect(n)
+			expect := samples.DataFromSelected(n).Value
+			b := byte(float64(0xff) * expect)
+			renderer.SetDrawColor(c.R, c.G, c.B, b/2)
+			renderer.FillRect(r)
+		}
 	}
-	negativeGrid := Grid.SampleNegative.Selected()
-	for _, n := range negativeGrid {
+	Grid.UnlockOuter()
+	renderer.SetDrawColor(0xff, 0xff, 0x00, 0xff)
+	for _, n := range g.screenMarkupData.markupReviewTiles {
 		r := Grid.TargetSdlRect(n)
-		expect := Grid.SampleNegative.DataFromSelected(n).Value
-		b := byte(float64(0xff) * expect)
-		renderer.SetDrawColor(0x00, 0x00, 0xff, b/2)
-		renderer.FillRect(r)
+		renderer.DrawRect(r)
+		renderer.DrawRect(&sdl.Rect{X: r.X + 1, Y: r.Y + 1, W: r.W - 2, H: r.H - 2})
 	}
-	Grid.UnlockOuter()
 
 	// Caption.
 	TextDrawer.PrepareDrawing()
-	TextDrawer.Draw(fmt.Sprintf("MARKUP: %v", g.screenMarkupData.markupAction), 1, 0)
+	mode := "MARKUP"
+	if g.screenMarkupData.markupReview {
+		mode = fmt.Sprintf("REVIEW %d", len(g.screenMarkupData.markupScrShotList))
+	}
+	TextDrawer.Draw(fmt.Sprintf("%v [%v]: %v", mode, Config.Labels[g.screenMarkupData.markupActiveClass].Name, g.screenMarkupData.markupAction), 1, 0)
 	img := TextDrawer.GetResultRBGA()
 	if img == nil {
 		return
@@ -721,6 +1073,17 @@ func (g *GuiStruct) setGuiSelectWnd() {
 				if g.screenSelectWndData.selectWndTitle != -1 {
 					targetWindowTitle = g.screenSelectWndData.selectWndList[g.screenSelectWndData.selectWndTitle].Title
 					breakScreenshot = true
+					TileFilter.Reset()
+					// A known window brings its dataset back, a new one keeps
+					// the dataset in use.
+					name, ok := Datasets.ForWindow(targetWindowTitle)
+					if !ok {
+						name = Dataset.Name
+					}
+					if err := g.datasetSwitch(name); err != nil {
+						log.Println(err)
+						GuiTextView.PutString(err.Error())
+					}
 					g.ReturnScreen()
 				}
 			}
@@ -755,7 +1118,11 @@ func (g *GuiStruct) renderGuiSelectWnd(renderer *sdl.Renderer) {
 
 	for _, l := range g.screenSelectWndData.selectWndList {
 		n++
-		TextDrawer.Draw(l.Title, 1, n)
+		if name, ok := Datasets.ForWindow(l.Title); ok {
+			TextDrawer.Draw(fmt.Sprintf("%v  [%v]", l.Title, datasetTitle(name)), 1, n)
+		} else {
+			TextDrawer.Draw(l.Title, 1, n)
+		}
 	}
 	img := TextDrawer.GetResultRBGA()
 	if img == nil {
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// AUGMENT BEGIN
+type screenAugmentStruct struct {
+	augmentExit      CallbackHandle
+	augmentEnterHelp CallbackHandle
+	augmentNext      CallbackHandle
+	augmentPrevious  CallbackHandle
+	augmentShuffle   CallbackHandle
+	augmentList      []string
+	augmentIndex     int
+	augmentSeed      int64
+	augmentSheet     *image.RGBA
+	augmentStatus    string
+	augmentTex       GuiSDLTextureMetaStruct
+}
+
+// The sheet shows the sample and augmentVariants variants of it, each
+// scaled up to augmentCellPixels.
+const (
+	augmentColumns    = 4
+	augmentVariants   = 11
+	augmentCellPixels = 160
+	augmentGapPixels  = 8
+)
+
+func (g *GuiStruct) setGuiAugment() {
+	d := &g.screenAugmentData
+	fExit := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.ReturnScreen()
+		}
+	}
+	d.augmentExit = UserInput.PutKeyboardCallback(Config.Keybindings.Augment.Quit[0], fExit, false)
+	fEnterHelp := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.CallScreen(SCREEN_INDEX_HELP, g.setGuiAugment, g.unsetGuiAugment, g.setGuiHelp, g.unsetGuiHelp)
+		}
+	}
+	d.augmentEnterHelp = UserInput.PutKeyboardCallback(Config.Keybindings.Augment.Help[0], fEnterHelp, false)
+	fStep := func(step int) func(cbData InputCallbackDataI) {
+		return func(cbData InputCallbackDataI) {
+			t, _ := cbData.(*KeyboardCallbackData)
+			if t.CbEvType == CALLBACK_EVENT_KEYDOWN && len(d.augmentList) != 0 {
+				d.augmentIndex = (d.augmentIndex + step + len(d.augmentList)) % len(d.augmentList)
+				g.augmentUpdate()
+			}
+		}
+	}
+	d.augmentNext = UserInput.PutKeyboardCallback(Config.Keybindings.Augment.Next[0], fStep(1), false)
+	d.augmentPrevious = UserInput.PutKeyboardCallback(Config.Keybindings.Augment.Previous[0], fStep(-1), false)
+	fShuffle := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			d.augmentSeed++
+			g.augmentUpdate()
+		}
+	}
+	d.augmentShuffle = UserInput.PutKeyboardCallback(Config.Keybindings.Augment.Shuffle[0], fShuffle, false)
+
+	d.augmentList = File.GetMarkedDataList()
+	if d.augmentIndex >= len(d.augmentList) {
+		d.augmentIndex = 0
+	}
+	g.augmentUpdate()
+}
+
+func (g *GuiStruct) unsetGuiAugment() {
+	UserInput.RemoveKeyboardCallback(g.screenAugmentData.augmentExit)
+	UserInput.RemoveKeyboardCallback(g.screenAugmentData.augmentEnterHelp)
+	UserInput.RemoveKeyboardCallback(g.screenAugmentData.augmentNext)
+	UserInput.RemoveKeyboardCallback(g.screenAugmentData.augmentPrevious)
+	UserInput.RemoveKeyboardCallback(g.screenAugmentData.augmentShuffle)
+}
+
+// augmentUpdate builds the sheet of the chosen sample.
+func (g *GuiStruct) augmentUpdate() {
+	d := &g.screenAugmentData
+	d.augmentSheet = nil
+	if len(d.augmentList) == 0 {
+		d.augmentStatus = "No marked samples"
+		return
+	}
+	p := d.augmentList[d.augmentIndex]
+	img, _, class, err := File.LoadMarked(p)
+	if err != nil {
+		log.Println(err)
+		d.augmentStatus = err.Error()
+		return
+	}
+	d.augmentStatus = fmt.Sprintf("%d/%d %v [%v], %d copies per sample in training", d.augmentIndex+1, len(d.augmentList), p, Config.Labels[class].Name, Config.Augmentation.Copies)
+
+	images := append([]*image.RGBA{img}, Augment.Preview(img, d.augmentSeed, augmentVariants)...)
+	rows := (len(images) + augmentColumns - 1) / augmentColumns
+	step := augmentCellPixels + augmentGapPixels
+	sheet := image.NewRGBA(image.Rect(0, 0, augmentColumns*step, rows*step))
+	draw.Draw(sheet, sheet.Rect, &image.Uniform{*g.background0}, image.Point{}, draw.Src)
+	for i, x := range images {
+		r := image.Rect(0, 0, augmentCellPixels, augmentCellPixels).Add(image.Pt(i%augmentColumns*step, i/augmentColumns*step))
+		draw.Draw(sheet, r, Image.Resize(x, augmentCellPixels), image.Point{}, draw.Over)
+	}
+	// The original is framed.
+	frame := image.Rect(0, 0, augmentCellPixels, augmentCellPixels).Inset(-augmentGapPixels / 4)
+	for _, r := range []image.Rectangle{
+		image.Rect(frame.Min.X, frame.Min.Y, frame.Max.X, frame.Min.Y+2),
+		image.Rect(frame.Min.X, frame.Max.Y-2, frame.Max.X, frame.Max.Y),
+		image.Rect(frame.Min.X, frame.Min.Y, frame.Min.X+2, frame.Max.Y),
+		image.Rect(frame.Max.X-2, frame.Min.Y, frame.Max.X, frame.Max.Y),
+	} {
+		draw.Draw(sheet, r, &image.Uniform{color.RGBA{255, 255, 0, 255}}, image.Point{}, draw.Src)
+	}
+	d.augmentSheet = sheet
+}
+
+func (g *GuiStruct) renderGuiAugment(renderer *sdl.Renderer) {
+	g.renderImageWithAspect(renderer, &g.texCaptured)
+
+	d := &g.screenAugmentData
+	if d.augmentSheet != nil {
+		w, h := int32(d.augmentSheet.Rect.Dx()), int32(d.augmentSheet.Rect.Dy())
+		r := &sdl.Rect{X: (int32(outScreenSize.X) - w) / 2, Y: (int32(outScreenSize.Y) - h) / 2, W: w, H: h}
+		g.renderImage(d.augmentSheet, renderer, r, &d.augmentTex)
+	}
+
+	TextDrawer.PrepareDrawing()
+	s := fmt.Sprintf("AUGMENTATION: %c/%c SAMPLE, %c OTHER VARIANTS, %c TO QUIT", Config.Keybindings.Augment.Previous[0], Config.Keybindings.Augment.Next[0], Config.Keybindings.Augment.Shuffle[0], Config.Keybindings.Augment.Quit[0])
+	TextDrawer.Draw(s, 1, 0)
+	TextDrawer.Draw(d.augmentStatus, 1, 1)
+	img := TextDrawer.GetResultRBGA()
+	if img == nil {
+		return
+	}
+	g.renderImage(img, renderer, nil, &g.texUI)
+}
+
+// AUGMENT END
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// DATASETS BEGIN
+type screenDatasetsStruct struct {
+	datasetsExit         CallbackHandle
+	datasetsEnterHelp    CallbackHandle
+	datasetsNew          CallbackHandle
+	datasetsMouseMove    CallbackHandle
+	datasetsMouseClick   CallbackHandle
+	datasetsSelectedLine int
+	datasetsSelected     int
+	mut                  sync.Mutex
+	datasetsList         []string
+	datasetsStatus       string
+}
+
+const datasetsFirstLine = 3
+
+func (g *GuiStruct) setGuiDatasets() {
+	d := &g.screenDatasetsData
+	d.datasetsSelectedLine = -1
+	d.datasetsSelected = -1
+
+	fExit := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.ReturnScreen()
+		}
+	}
+	d.datasetsExit = UserInput.PutKeyboardCallback(Config.Keybindings.Datasets.Quit[0], fExit, false)
+	fEnterHelp := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.CallScreen(SCREEN_INDEX_HELP, g.setGuiDatasets, g.unsetGuiDatasets, g.setGuiHelp, g.unsetGuiHelp)
+		}
+	}
+	d.datasetsEnterHelp = UserInput.PutKeyboardCallback(Config.Keybindings.Datasets.Help[0], fEnterHelp, false)
+	fNew := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.datasetsRun(g.datasetsNewName())
+		}
+	}
+	d.datasetsNew = UserInput.PutKeyboardCallback(Config.Keybindings.Datasets.New[0], fNew, false)
+	fMouseMove := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*MouseCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_MOUSEMOTION {
+			d.mut.Lock()
+			d.datasetsSelectedLine = TextDrawer.InLineY(t.Y)
+			d.mut.Unlock()
+		}
+	}
+	d.datasetsMouseMove = UserInput.PutMouseMotionCallback(fMouseMove)
+	fBtn0 := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*MouseCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_MOUSEBTNPUSH {
+			d.mut.Lock()
+			i := d.datasetsSelected
+			name, ok := "", i >= 0 && i < len(d.datasetsList)
+			if ok {
+				name = d.datasetsList[i]
+			}
+			d.mut.Unlock()
+			if ok {
+				g.datasetsRun(name)
+			}
+		}
+	}
+	d.datasetsMouseClick = UserInput.PutMouseBtnCallback(1, fBtn0)
+
+	d.mut.Lock()
+	d.datasetsList = Datasets.List()
+	d.datasetsStatus = ""
+	d.mut.Unlock()
+}
+
+func (g *GuiStruct) unsetGuiDatasets() {
+	UserInput.RemoveKeyboardCallback(g.screenDatasetsData.datasetsExit)
+	UserInput.RemoveKeyboardCallback(g.screenDatasetsData.datasetsEnterHelp)
+	UserInput.RemoveKeyboardCallback(g.screenDatasetsData.datasetsNew)
+	UserInput.RemoveMouseMotionCallback(g.screenDatasetsData.datasetsMouseMove)
+	UserInput.RemoveMouseBtnCallback(g.screenDatasetsData.datasetsMouseClick)
+}
+
+// datasetsRun switches to the dataset name and refreshes the list.
+func (g *GuiStruct) datasetsRun(name string) {
+	d := &g.screenDatasetsData
+	status := ""
+	if err := g.datasetSwitch(name); err != nil {
+		log.Println(err)
+		status = err.Error()
+	} else {
+		status = fmt.Sprintf("Dataset %v is %v", datasetTitle(name), &Dataset)
+		if targetWindowTitle != "" {
+			status += ", remembered for " + targetWindowTitle
+		}
+	}
+	d.mut.Lock()
+	d.datasetsList = Datasets.List()
+	d.datasetsStatus = status
+	d.mut.Unlock()
+}
+
+// datasetSwitch makes name the dataset in use and of the target window.
+// Training and evaluation keep the dataset they started with, so it waits
+// for them.
+func (g *GuiStruct) datasetSwitch(name string) error {
+	// The target window may be new as well.
+	defer Api.PublishState()
+	if g.screenMainData.mainLearnLock {
+		return fmt.Errorf("Dataset switch error: training or evaluation is running")
+	}
+	if name != Dataset.Name {
+		if err := Dataset.Select(name); err != nil {
+			return err
+		}
+		TileFilter.Reset()
+	}
+	if targetWindowTitle != "" {
+		return Datasets.Remember(targetWindowTitle, name)
+	}
+	return nil
+}
+
+// datasetsNewName names a new dataset after the target window.
+func (g *GuiStruct) datasetsNewName() string {
+	base := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(targetWindowTitle))
+	base = strings.Trim(base, "_")
+	if base == "" {
+		base = "dataset"
+	}
+	known := make(map[string]bool)
+	for _, x := range Datasets.List() {
+		known[x] = true
+	}
+	name := base
+	for i := 2; known[name]; i++ {
+		name = fmt.Sprintf("%v_%d", base, i)
+	}
+	return name
+}
+
+// datasetTitle names the unnamed dataset.
+func datasetTitle(name string) string {
+	if name == "" {
+		return "(data root)"
+	}
+	return name
+}
+
+func (g *GuiStruct) renderGuiDatasets(renderer *sdl.Renderer) {
+	g.renderImageWithAspect(renderer, &g.texCaptured)
+
+	d := &g.screenDatasetsData
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	TextDrawer.PrepareDrawing()
+	TextDrawer.SetBackgroundColor(*g.background0)
+	TextDrawer.PrepareBackground()
+	s := fmt.Sprintf("DATASETS: CLICK TO USE, %c NEW FOR THE WINDOW, %c TO QUIT", Config.Keybindings.Datasets.New[0], Config.Keybindings.Datasets.Quit[0])
+	TextDrawer.Draw(s, 1, 0)
+	TextDrawer.Draw(d.datasetsStatus, 1, 1)
+	if d.datasetsSelectedLine >= datasetsFirstLine && d.datasetsSelectedLine < len(d.datasetsList)+datasetsFirstLine {
+		TextDrawer.HighlightLine(d.datasetsSelectedLine, 0, &color.RGBA{255, 255, 0, 255 / 5})
+		d.datasetsSelected = d.datasetsSelectedLine - datasetsFirstLine
+	} else {
+		d.datasetsSelectedLine = -1
+		d.datasetsSelected = -1
+	}
+
+	n := datasetsFirstLine
+	for _, x := range d.datasetsList {
+		s := "  " + datasetTitle(x)
+		if x == Dataset.Name {
+			s = "* " + datasetTitle(x)
+		}
+		TextDrawer.Draw(s, 1, n)
+		n++
+	}
+	if len(d.datasetsList) == 0 {
+		TextDrawer.Draw("No datasets", 1, n)
+	}
+	img := TextDrawer.GetResultRBGA()
+	if img == nil {
+		return
+	}
+	g.renderImage(img, renderer, nil, &g.texUI)
+}
+
+// DATASETS END
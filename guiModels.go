@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"log"
+	"sync"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// MODELS BEGIN
+type screenModelsStruct struct {
+	modelsExit         CallbackHandle
+	modelsEnterHelp    CallbackHandle
+	modelsDelete       CallbackHandle
+	modelsMouseMove    CallbackHandle
+	modelsMouseClick   CallbackHandle
+	modelsSelectedLine int
+	modelsSelected     int
+	mut                sync.Mutex
+	modelsList         []MlModelInfo
+	modelsStatus       string
+	modelsBusy         bool
+}
+
+const modelsFirstLine = 3
+
+func (g *GuiStruct) setGuiModels() {
+	g.screenModelsData.modelsSelectedLine = -1
+	g.screenModelsData.modelsSelected = -1
+
+	fExit := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.ReturnScreen()
+		}
+	}
+	g.screenModelsData.modelsExit = UserInput.PutKeyboardCallback(Config.Keybindings.Models.Quit[0], fExit, false)
+	fEnterHelp := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.CallScreen(SCREEN_INDEX_HELP, g.setGuiModels, g.unsetGuiModels, g.setGuiHelp, g.unsetGuiHelp)
+		}
+	}
+	g.screenModelsData.modelsEnterHelp = UserInput.PutKeyboardCallback(Config.Keybindings.Models.Help[0], fEnterHelp, false)
+	fDelete := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			if version, ok := g.modelsSelectedVersion(); ok {
+				g.modelsRun(fmt.Sprintf("Delete %v", version), func() error { return Ml.DeleteModel(context.Background(), version) })
+			}
+		}
+	}
+	g.screenModelsData.modelsDelete = UserInput.PutKeyboardCallback(Config.Keybindings.Models.Delete[0], fDelete, false)
+	fMouseMove := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*MouseCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_MOUSEMOTION {
+			g.screenModelsData.modelsSelectedLine = TextDrawer.InLineY(t.Y)
+		}
+	}
+	g.screenModelsData.modelsMouseMove = UserInput.PutMouseMotionCallback(fMouseMove)
+	fBtn0 := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*MouseCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_MOUSEBTNPUSH {
+			if version, ok := g.modelsSelectedVersion(); ok {
+				g.modelsRun(fmt.Sprintf("Activate %v", version), func() error {
+					TileFilter.Reset()
+					return Ml.ActivateModel(context.Background(), version)
+				})
+			}
+		}
+	}
+	g.screenModelsData.modelsMouseClick = UserInput.PutMouseBtnCallback(1, fBtn0)
+
+	g.modelsRun("List models", func() error { return nil })
+}
+
+func (g *GuiStruct) unsetGuiModels() {
+	UserInput.RemoveKeyboardCallback(g.screenModelsData.modelsExit)
+	UserInput.RemoveKeyboardCallback(g.screenModelsData.modelsEnterHelp)
+	UserInput.RemoveKeyboardCallback(g.screenModelsData.modelsDelete)
+	UserInput.RemoveMouseMotionCallback(g.screenModelsData.modelsMouseMove)
+	UserInput.RemoveMouseBtnCallback(g.screenModelsData.modelsMouseClick)
+}
+
+// modelsRun does f and refreshes the list in the background, the server may
+// take a while to build the activated model.
+func (g *GuiStruct) modelsRun(action string, f func() error) {
+	d := &g.screenModelsData
+	d.mut.Lock()
+	if d.modelsBusy {
+		d.mut.Unlock()
+		return
+	}
+	d.modelsBusy = true
+	d.modelsStatus = action + "..."
+	d.mut.Unlock()
+
+	go func() {
+		status := ""
+		var models []MlModelInfo
+		if !Ml.Ready() {
+			status = "ML backend is not ready"
+		} else if err := f(); err != nil {
+			status = err.Error()
+			if h := MlErrorHint(err); h != "" {
+				status += ". " + h
+			}
+		} else if models, err = Ml.ListModels(context.Background()); err != nil {
+			status = err.Error()
+		}
+		if status != "" {
+			log.Println(status)
+		}
+		d.mut.Lock()
+		if models != nil || status == "" {
+			d.modelsList = models
+		}
+		d.modelsStatus = status
+		d.modelsBusy = false
+		d.mut.Unlock()
+	}()
+}
+
+func (g *GuiStruct) modelsSelectedVersion() (string, bool) {
+	d := &g.screenModelsData
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	if d.modelsSelected < 0 || d.modelsSelected >= len(d.modelsList) {
+		return "", false
+	}
+	return d.modelsList[d.modelsSelected].Version, true
+}
+
+func (g *GuiStruct) renderGuiModels(renderer *sdl.Renderer) {
+	g.renderImageWithAspect(renderer, &g.texCaptured)
+
+	d := &g.screenModelsData
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	TextDrawer.PrepareDrawing()
+	TextDrawer.SetBackgroundColor(*g.background0)
+	TextDrawer.PrepareBackground()
+	s := fmt.Sprintf("MODELS: CLICK TO ACTIVATE, %c TO DELETE, %c TO QUIT", Config.Keybindings.Models.Delete[0], Config.Keybindings.Models.Quit[0])
+	TextDrawer.Draw(s, 1, 0)
+	TextDrawer.Draw(d.modelsStatus, 1, 1)
+	if d.modelsSelectedLine >= modelsFirstLine && d.modelsSelectedLine < len(d.modelsList)+modelsFirstLine {
+		TextDrawer.HighlightLine(d.modelsSelectedLine, 0, &color.RGBA{255, 255, 0, 255 / 5})
+		d.modelsSelected = d.modelsSelectedLine - modelsFirstLine
+	} else {
+		d.modelsSelectedLine = -1
+		d.modelsSelected = -1
+	}
+
+	n := modelsFirstLine
+	for _, m := range d.modelsList {
+		s := "  " + m.String()
+		if m.Active {
+			s = "* " + m.String()
+		}
+		TextDrawer.Draw(s, 1, n)
+		n++
+	}
+	if len(d.modelsList) == 0 && !d.modelsBusy {
+		TextDrawer.Draw("No trained models", 1, n)
+	}
+	img := TextDrawer.GetResultRBGA()
+	if img == nil {
+		return
+	}
+	g.renderImage(img, renderer, nil, &g.texUI)
+}
+
+// MODELS END
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"sync"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// REVIEW BEGIN
+type screenReviewStruct struct {
+	reviewExit        CallbackHandle
+	reviewEnterHelp   CallbackHandle
+	reviewNext        CallbackHandle
+	reviewPrevious    CallbackHandle
+	reviewClass       CallbackHandle
+	reviewSource      CallbackHandle
+	reviewFolder      CallbackHandle
+	reviewDelete      CallbackHandle
+	reviewMouseMove   CallbackHandle
+	reviewMouseClick1 CallbackHandle
+	reviewMouseClick3 CallbackHandle
+	mut               sync.Mutex
+	reviewNewMarked   bool
+	reviewClassFilter int
+	reviewSrcFilter   string
+	reviewList        []reviewItem
+	reviewPage        int
+	reviewColumns     int
+	reviewRows        int
+	reviewScreen      image.Point
+	reviewHovered     int
+	reviewSheet       *image.RGBA
+	reviewSheetRect   sdl.Rect
+	reviewStatus      string
+	reviewTex         GuiSDLTextureMetaStruct
+}
+
+type reviewItem struct {
+	path string
+	meta *SampleMeta
+}
+
+// The thumbnails are framed with the color of their label, the page fills
+// the screen below reviewTextLines lines of text.
+const (
+	reviewCellPixels  = 96
+	reviewGapPixels   = 10
+	reviewFramePixels = 3
+	reviewTextLines   = 3
+)
+
+func (g *GuiStruct) setGuiReview() {
+	d := &g.screenReviewData
+	d.reviewHovered = -1
+	fExit := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.ReturnScreen()
+		}
+	}
+	d.reviewExit = UserInput.PutKeyboardCallback(Config.Keybindings.Review.Quit[0], fExit, false)
+	fEnterHelp := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			g.CallScreen(SCREEN_INDEX_HELP, g.setGuiReview, g.unsetGuiReview, g.setGuiHelp, g.unsetGuiHelp)
+		}
+	}
+	d.reviewEnterHelp = UserInput.PutKeyboardCallback(Config.Keybindings.Review.Help[0], fEnterHelp, false)
+	fStep := func(step int) func(cbData InputCallbackDataI) {
+		return func(cbData InputCallbackDataI) {
+			t, _ := cbData.(*KeyboardCallbackData)
+			if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+				d.mut.Lock()
+				if pages := d.pages(); pages != 0 {
+					d.reviewPage = (d.reviewPage + step + pages) % pages
+				}
+				g.reviewUpdate()
+				d.mut.Unlock()
+			}
+		}
+	}
+	d.reviewNext = UserInput.PutKeyboardCallback(Config.Keybindings.Review.Next[0], fStep(1), false)
+	d.reviewPrevious = UserInput.PutKeyboardCallback(Config.Keybindings.Review.Previous[0], fStep(-1), false)
+	fClass := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			d.mut.Lock()
+			// All labels, then each of them.
+			d.reviewClassFilter++
+			if d.reviewClassFilter >= len(Config.Labels) {
+				d.reviewClassFilter = -1
+			}
+			g.reviewLoad()
+			d.mut.Unlock()
+		}
+	}
+	d.reviewClass = UserInput.PutKeyboardCallback(Config.Keybindings.Review.Class[0], fClass, false)
+	fSource := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			d.mut.Lock()
+			// Narrows to the screenshot of the hovered sample, or back to all.
+			if d.reviewSrcFilter != "" {
+				d.reviewSrcFilter = ""
+				g.reviewLoad()
+			} else if it := d.hovered(); it != nil {
+				d.reviewSrcFilter = it.meta.Source
+				g.reviewLoad()
+			}
+			d.mut.Unlock()
+		}
+	}
+	d.reviewSource = UserInput.PutKeyboardCallback(Config.Keybindings.Review.Source[0], fSource, false)
+	fFolder := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			d.mut.Lock()
+			d.reviewNewMarked = !d.reviewNewMarked
+			g.reviewLoad()
+			d.mut.Unlock()
+		}
+	}
+	d.reviewFolder = UserInput.PutKeyboardCallback(Config.Keybindings.Review.Folder[0], fFolder, false)
+	fDelete := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*KeyboardCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_KEYDOWN {
+			d.mut.Lock()
+			if it := d.hovered(); it != nil {
+				// it points into reviewList, the removal shifts the next
+				// sample under it.
+				p := it.path
+				if err := File.DeleteMarked(p); err != nil {
+					log.Println(err)
+					d.reviewStatus = err.Error()
+				} else {
+					i := d.reviewPage*d.reviewColumns*d.reviewRows + d.reviewHovered
+					d.reviewList = append(d.reviewList[:i], d.reviewList[i+1:]...)
+					g.reviewUpdate()
+					d.reviewStatus = fmt.Sprintf("Deleted %v", p)
+				}
+			}
+			d.mut.Unlock()
+		}
+	}
+	d.reviewDelete = UserInput.PutKeyboardCallback(Config.Keybindings.Review.Delete[0], fDelete, false)
+	fMouseMove := func(cbData InputCallbackDataI) {
+		t, _ := cbData.(*MouseCallbackData)
+		if t.CbEvType == CALLBACK_EVENT_MOUSEMOTION {
+			d.mut.Lock()
+			d.reviewHovered = d.cellAt(t.X, t.Y)
+			d.mut.Unlock()
+		}
+	}
+	d.reviewMouseMove = UserInput.PutMouseMotionCallback(fMouseMove)
+	// Left button moves the hovered sample to the next label, right button
+	// to the previous one.
+	fRelabel := func(step int) func(cbData InputCallbackDataI) {
+		return func(cbData InputCallbackDataI) {
+			t, _ := cbData.(*MouseCallbackData)
+			if t.CbEvType == CALLBACK_EVENT_MOUSEBTNPUSH {
+				d.mut.Lock()
+				d.reviewHovered = d.cellAt(t.X, t.Y)
+				if it := d.hovered(); it != nil {
+					class := (it.meta.Class + step + len(Config.Labels)) % len(Config.Labels)
+					if err := File.RelabelMarked(it.path, class); err != nil {
+						log.Println(err)
+						d.reviewStatus = err.Error()
+					} else {
+						// The sample stays on the page until the filters are
+						// applied again.
+						it.meta.Class = class
+						g.reviewUpdate()
+					}
+				}
+				d.mut.Unlock()
+			}
+		}
+	}
+	d.reviewMouseClick1 = UserInput.PutMouseBtnCallback(1, fRelabel(1))
+	d.reviewMouseClick3 = UserInput.PutMouseBtnCallback(3, fRelabel(-1))
+
+	d.mut.Lock()
+	g.reviewLoad()
+	d.mut.Unlock()
+}
+
+func (g *GuiStruct) unsetGuiReview() {
+	UserInput.RemoveKeyboardCallback(g.screenReviewData.reviewExit)
+	UserInput.RemoveKeyboardCallback(g.screenReviewData.reviewEnterHelp)
+	UserInput.RemoveKeyboardCallback(g.screenReviewData.reviewNext)
+	UserInput.RemoveKeyboardCallback(g.screenReviewData.reviewPrevious)
+	UserInput.RemoveKeyboardCallback(g.screenReviewData.reviewClass)
+	UserInput.RemoveKeyboardCallback(g.screenReviewData.reviewSource)
+	UserInput.RemoveKeyboardCallback(g.screenReviewData.reviewFolder)
+	UserInput.RemoveKeyboardCallback(g.screenReviewData.reviewDelete)
+	UserInput.RemoveMouseMotionCallback(g.screenReviewData.reviewMouseMove)
+	UserInput.RemoveMouseBtnCallback(g.screenReviewData.reviewMouseClick1)
+	UserInput.RemoveMouseBtnCallback(g.screenReviewData.reviewMouseClick3)
+}
+
+// pages is the number of pages of the list. The caller holds mut.
+func (d *screenReviewStruct) pages() int {
+	n := d.reviewColumns * d.reviewRows
+	if n == 0 {
+		return 0
+	}
+	return (len(d.reviewList) + n - 1) / n
+}
+
+// cellAt returns the cell of the page at screen x, y or -1. The caller
+// holds mut.
+func (d *screenReviewStruct) cellAt(x int, y int) int {
+	r := d.reviewSheetRect
+	x, y = x-int(r.X), y-int(r.Y)
+	if d.reviewSheet == nil || x < 0 || y < 0 || x >= int(r.W) || y >= int(r.H) {
+		return -1
+	}
+	step := reviewCellPixels + reviewGapPixels
+	if x%step >= reviewCellPixels || y%step >= reviewCellPixels {
+		return -1
+	}
+	return y/step*d.reviewColumns + x/step
+}
+
+// hovered returns the sample under the mouse or nil. The caller holds mut.
+func (d *screenReviewStruct) hovered() *reviewItem {
+	if d.reviewHovered < 0 || d.reviewHovered >= d.reviewColumns*d.reviewRows {
+		return nil
+	}
+	i := d.reviewPage*d.reviewColumns*d.reviewRows + d.reviewHovered
+	if i >= len(d.reviewList) {
+		return nil
+	}
+	return &d.reviewList[i]
+}
+
+// reviewLoad lists the samples of the folder the filters accept and goes
+// to the first page. The caller holds mut.
+func (g *GuiStruct) reviewLoad() {
+	d := &g.screenReviewData
+	d.reviewList = make([]reviewItem, 0)
+	File.QueryMarked(d.reviewNewMarked, func(path string, meta *SampleMeta) bool {
+		if (d.reviewClassFilter < 0 || meta.Class == d.reviewClassFilter) && (d.reviewSrcFilter == "" || meta.Source == d.reviewSrcFilter) {
+			d.reviewList = append(d.reviewList, reviewItem{path: path, meta: meta})
+			return true
+		}
+		return false
+	})
+	d.reviewPage = 0
+	d.reviewStatus = ""
+	g.reviewUpdate()
+}
+
+// reviewUpdate lays out the page for the screen size and builds its sheet.
+// The caller holds mut.
+func (g *GuiStruct) reviewUpdate() {
+	d := &g.screenReviewData
+	step := reviewCellPixels + reviewGapPixels
+	top := int(TextDrawer.SymbolSize()) * reviewTextLines
+	d.reviewScreen = outScreenSize
+	d.reviewColumns = (outScreenSize.X - reviewGapPixels) / step
+	d.reviewRows = (outScreenSize.Y - top - reviewGapPixels) / step
+	d.reviewSheet = nil
+	if d.reviewColumns <= 0 || d.reviewRows <= 0 {
+		return
+	}
+	if pages := d.pages(); d.reviewPage >= pages {
+		d.reviewPage = 0
+		if pages > 0 {
+			d.reviewPage = pages - 1
+		}
+	}
+	perPage := d.reviewColumns * d.reviewRows
+	first := d.reviewPage * perPage
+	page := d.reviewList[first:]
+	if len(page) > perPage {
+		page = page[:perPage]
+	}
+	if len(page) == 0 {
+		return
+	}
+
+	rows := (len(page) + d.reviewColumns - 1) / d.reviewColumns
+	sheet := image.NewRGBA(image.Rect(0, 0, d.reviewColumns*step, rows*step))
+	draw.Draw(sheet, sheet.Rect, &image.Uniform{*g.background0}, image.Point{}, draw.Src)
+	for i, it := range page {
+		r := image.Rect(0, 0, reviewCellPixels, reviewCellPixels).Add(image.Pt(i%d.reviewColumns*step, i/d.reviewColumns*step))
+		img, err := File.LoadImage(it.path)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		draw.Draw(sheet, r, Image.Resize(img, reviewCellPixels), image.Point{}, draw.Over)
+		g.reviewFrame(sheet, r, Config.Labels[it.meta.Class].RGBA())
+	}
+	d.reviewSheet = sheet
+	d.reviewSheetRect = sdl.Rect{X: int32(reviewGapPixels), Y: int32(top), W: int32(sheet.Rect.Dx()), H: int32(sheet.Rect.Dy())}
+}
+
+func (g *GuiStruct) reviewFrame(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for _, x := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+reviewFramePixels),
+		image.Rect(r.Min.X, r.Max.Y-reviewFramePixels, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+reviewFramePixels, r.Max.Y),
+		image.Rect(r.Max.X-reviewFramePixels, r.Min.Y, r.Max.X, r.Max.Y),
+	} {
+		draw.Draw(img, x, &image.Uniform{c}, image.Point{}, draw.Src)
+	}
+}
+
+func (g *GuiStruct) renderGuiReview(renderer *sdl.Renderer) {
+	d := &g.screenReviewData
+	d.mut.Lock()
+	if d.reviewScreen != outScreenSize {
+		g.reviewUpdate()
+	}
+	if d.reviewSheet != nil {
+		g.renderImage(d.reviewSheet, renderer, &d.reviewSheetRect, &d.reviewTex)
+		if d.hovered() != nil {
+			step := int32(reviewCellPixels + reviewGapPixels)
+			x := d.reviewSheetRect.X + int32(d.reviewHovered%d.reviewColumns)*step
+			y := d.reviewSheetRect.Y + int32(d.reviewHovered/d.reviewColumns)*step
+			renderer.SetDrawColor(0xff, 0xff, 0xff, 0xff)
+			renderer.DrawRect(&sdl.Rect{X: x - 3, Y: y - 3, W: reviewCellPixels + 6, H: reviewCellPixels + 6})
+		}
+	}
+
+	folder, class, source := fileMarkedDataFolder, "all labels", "all screenshots"
+	if d.reviewNewMarked {
+		folder = fileNewMarkedDataFolder
+	}
+	if d.reviewClassFilter >= 0 && d.reviewClassFilter < len(Config.Labels) {
+		class = Config.Labels[d.reviewClassFilter].Name
+	}
+	if d.reviewSrcFilter != "" {
+		source = d.reviewSrcFilter
+	}
+	status := d.reviewStatus
+	if it := d.hovered(); it != nil {
+		m := it.meta
+		status = fmt.Sprintf("%v [%v] tile %d of %v, labeled by %v at %v", it.path, Config.Labels[m.Class].Name, m.Index, m.Source, m.Labeler, m.Time)
+	}
+	pages := d.pages()
+	if pages == 0 {
+		pages = 1
+	}
+	s1 := fmt.Sprintf("%v: page %d/%d, %d samples, %v, %v", folder, d.reviewPage+1, pages, len(d.reviewList), class, source)
+	d.mut.Unlock()
+
+	TextDrawer.PrepareDrawing()
+	b := &Config.Keybindings.Review
+	s := fmt.Sprintf("REVIEW: CLICK NEXT LABEL, RIGHT CLICK PREVIOUS, %c/%c PAGE, %c LABEL, %c SCREENSHOT, %c FOLDER, %c DELETE, %c TO QUIT", b.Previous[0], b.Next[0], b.Class[0], b.Source[0], b.Folder[0], b.Delete[0], b.Quit[0])
+	TextDrawer.Draw(s, 1, 0)
+	TextDrawer.Draw(s1, 1, 1)
+	TextDrawer.Draw(status, 1, 2)
+	img := TextDrawer.GetResultRBGA()
+	if img == nil {
+		return
+	}
+	g.renderImage(img, renderer, nil, &g.texUI)
+}
+
+// REVIEW END
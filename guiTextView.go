@@ -23,6 +23,7 @@ func (m *GuiTextViewStruct) SetNumLines(linesNum int) {
 func (m *GuiTextViewStruct) PutString(s string) {
 	m.mut.Lock()
 	if m.lineCap == 0 {
+		m.mut.Unlock()
 		return
 	}
 	if len(m.lines) == m.lineCap {
@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"image/color"
 	"log"
 	"math"
-	"path"
 	"path/filepath"
 	"runtime"
 	"sync"
@@ -15,16 +15,36 @@ import (
 	"golang.org/x/image/draw"
 )
 
+// Images of the same size closer than imageSimilarRMS are taken for the
+// same, see DiffRGBARMSSameSize.
+const imageSimilarRMS = 10
+
 type ImageStruct struct{}
 
 var Image ImageStruct
 
 func (m *ImageStruct) Resize(img *image.RGBA, size int) *image.RGBA {
-	ri := image.NewRGBA(image.Rect(0, 0, MarkedImageSizePixels, MarkedImageSizePixels))
+	ri := image.NewRGBA(image.Rect(0, 0, size, size))
 	draw.NearestNeighbor.Scale(ri, ri.Rect, img, img.Rect, draw.Over, nil)
 	return ri
 }
 
+// Sample makes a marked sample of img in the dataset shape: grayscale keeps
+// R, G and B equal and only RGB+alpha keeps the alpha.
+func (m *ImageStruct) Sample(img *image.RGBA) *image.RGBA {
+	ri := m.Resize(img, Dataset.SampleSize)
+	for i := 0; i < len(ri.Pix); i += 4 {
+		switch Dataset.Channels {
+		case DatasetChannelsGray:
+			y := color.GrayModel.Convert(color.RGBA{ri.Pix[i+0], ri.Pix[i+1], ri.Pix[i+2], 0xff}).(color.Gray).Y
+			ri.Pix[i+0], ri.Pix[i+1], ri.Pix[i+2], ri.Pix[i+3] = y, y, y, 0xff
+		case DatasetChannelsRGB:
+			ri.Pix[i+3] = 0xff
+		}
+	}
+	return ri
+}
+
 func (m *ImageStruct) CleanupNewMarkedData(stop context.Context) error {
 	fileList := File.GetNewMarkedDataMap()
 	mut := sync.Mutex{}
@@ -77,7 +97,7 @@ func (m *ImageStruct) CleanupNewMarkedData(stop context.Context) error {
 									GuiTextView.PutString(s)
 								} else {
 									diff := m.DiffRGBARMSSameSize(img0, img1)
-									if diff < 10 {
+									if diff < imageSimilarRMS {
 										mut.Lock()
 										fileList[k] = 1
 										mut.Unlock()
@@ -272,7 +292,7 @@ func (m *ImageStruct) IsSimilarImageInPersistentMarked(stop context.Context, ori
 				return
 			case d := <-data:
 				diff := m.DiffRGBARMSSameSize(origin_img, d)
-				if diff < 10 {
+				if diff < imageSimilarRMS {
 					found <- true
 				} else {
 					found <- false
@@ -387,6 +407,9 @@ func (m *ImageStruct) MoveNewMarkedToPersistent(stop context.Context) error {
 			err := File.SaveImageToPersistent(img, name)
 			GuiTextView.PutString(fmt.Sprintf("Move %v", name))
 			if err == nil {
+				if err := SampleIndex.Move(f, Dataset.Path(fileMarkedDataFolder, name)); err != nil {
+					log.Println(fmt.Errorf("MoveNewMarkedToPersistent error: %v", err))
+				}
 				File.DeleteFile(f)
 			} else {
 				GuiTextView.PutString(fmt.Sprintf("FAIL: move %v, %v", name, err))
@@ -401,8 +424,8 @@ func (m *ImageStruct) MoveNewMarkedToPersistent(stop context.Context) error {
 }
 
 func (m *ImageStruct) DiffTwoMarkedImages(filename0 string, filename1 string) (float64, error) {
-	p0 := path.Join(fileMarkedDataFolder, filename0)
-	p1 := path.Join(fileMarkedDataFolder, filename1)
+	p0 := Dataset.Path(fileMarkedDataFolder, filename0)
+	p1 := Dataset.Path(fileMarkedDataFolder, filename1)
 	img0, err := File.LoadImage(p0)
 	if err != nil {
 		return 0, fmt.Errorf("DiffTwoMarkedImages error: %v", err)
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"image"
 	"log"
 	"os"
@@ -198,28 +197,52 @@ func main() {
 		return
 	}
 
-	// if err := Ml.StartMlServer(); err != nil {
-	// 	log.Println("Start ML server error:", err)
-	// 	return
-	// }
-	// defer Ml.StopMlServer()
-
-	go func() {
-		ticker := time.NewTicker(time.Second)
-		for {
-			select {
-			case <-ticker.C:
-				if !Ml.ServerConnected() {
-					fmt.Println("Connect to server")
-					Ml.ConnectServer()
-				}
+	if err := Datasets.Load(); err != nil {
+		log.Println(err)
+		return
+	}
+
+	if err := Dataset.Load(); err != nil {
+		log.Println(err)
+		return
+	}
+
+	if len(os.Args) > 1 {
+		if err := RunCommand(os.Args[1:]); err != nil {
+			log.Println(err)
+			if hint := MlErrorHint(err); hint != "" {
+				log.Println(hint)
 			}
 		}
-	}()
-	// defer Ml.DisconnectServer()
+		return
+	}
+
+	if err := MlSelectBackend(Config.Common.MlBackend); err != nil {
+		log.Println(err)
+		return
+	}
+
+	if _, ok := Ml.(*MlGRPCStruct); ok {
+		if err := MlGRPC.StartMlServer(); err != nil {
+			log.Println("Start ML server error:", err)
+			return
+		}
+		// Runs on WINDOWEVENT_CLOSE too, main returns there.
+		defer MlGRPC.StopMlServer()
+	}
+
+	if Config.Common.ApiAddress != "" {
+		if err := Api.Start(); err != nil {
+			log.Println(err)
+			return
+		}
+		defer Api.Stop()
+	}
 
 	Grid.InitGrid()
 	UserGui.Init()
+	// Before StopMlServer, deferred above.
+	defer UserGui.ClosePredictSession()
 	err = TextDrawer.Init("arial.ttf")
 	if err != nil {
 		log.Println(err)
@@ -4,138 +4,178 @@ import (
 	"context"
 	"fmt"
 	"image"
-	"log"
-	"os/exec"
-	"scrml/protos"
-	"sync"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/connectivity"
-	"google.golang.org/grpc/credentials/insecure"
+	"strings"
 )
 
 const (
-	MarkedImageSizePixels = 64
+	MlBackendGRPC = "grpc"
+	MlBackendGo   = "go"
 )
 
-type MlStruct struct {
-	mut    sync.Mutex
-	cmd    *exec.Cmd
-	conn   *grpc.ClientConn
-	client *protos.MessagerClient
-}
-
-var (
-	Ml MlStruct
+// MlModelsFolder holds a subfolder per trained model version, see Registry
+// in mlserver.py. MlActiveModelFile names the version used for prediction.
+const (
+	MlModelsFolder    = "models"
+	MlActiveModelFile = "active.txt"
 )
 
-func (m *MlStruct) Lock() {
-	m.mut.Lock()
-}
-
-func (m *MlStruct) Unlock() {
-	m.mut.Unlock()
-}
-
-func (m *MlStruct) ImageToArray(img *image.RGBA) *[]byte {
-	ri := Image.Resize(img, MarkedImageSizePixels)
-
-	b := make([]byte, 0, MarkedImageSizePixels*MarkedImageSizePixels*3)
-	for i := 0; i < int(len(ri.Pix)); i += 4 {
-		b = append(b, ri.Pix[i+0])
-		b = append(b, ri.Pix[i+1])
-		b = append(b, ri.Pix[i+2])
-	}
-
-	return &b
-}
-
-func (m *MlStruct) GRPCSendTrainingSampleData(b *[]byte, y byte) error {
-	_, err := m.getClient().AppendTrainingSample(context.Background(), &protos.MsgSample{XData: *b, YData: int64(y)})
+// MlSession is a handle of the backend side sample buffers. Training and
+// prediction use their own sessions, so they can run side by side.
+type MlSession int64
+
+// MlBackend is a model runner used by the GUI. The gRPC backend talks to
+// mlserver.py, the Go backend runs the exported model in process. The calls
+// give up when ctx is done, the gRPC backend also applies the configured
+// timeouts on top of it.
+type MlBackend interface {
+	Ready() bool
+	OpenSession(ctx context.Context) (MlSession, error)
+	CloseSession(ctx context.Context, ses MlSession) error
+	// InitParams sets up ses for training, classWeights weight the loss of
+	// every label and are nil for an unweighted one.
+	InitParams(ctx context.Context, ses MlSession, classWeights []float64) error
+	// UploadSamples makes samples the training set of ses. Only the samples
+	// the backend does not have yet are loaded and sent, progress gets the
+	// number of them sent so far.
+	UploadSamples(ctx context.Context, ses MlSession, samples []MlSample, progress func(sent, total int)) error
+	Train(ctx context.Context, ses MlSession, progress func(p MlTrainProgress)) error
+	CancelTrain(ctx context.Context, ses MlSession) error
+	// Predict returns a probability per label for every tile.
+	Predict(ctx context.Context, ses MlSession, tiles []*image.RGBA) ([][]float64, error)
+	// Evaluate returns the class the active model predicts for every sample.
+	// The gRPC backend uploads them to ses first, like UploadSamples.
+	Evaluate(ctx context.Context, ses MlSession, samples []MlSample, progress func(sent, total int)) ([]int, error)
+	ListModels(ctx context.Context) ([]MlModelInfo, error)
+	ActivateModel(ctx context.Context, version string) error
+	DeleteModel(ctx context.Context, version string) error
+	// ExportModel writes a model, the active one for "", to folder as ONNX
+	// with a JSON sidecar and returns both file names.
+	ExportModel(ctx context.Context, version string, folder string) (string, string, error)
+}
+
+// MlSample is a marked file, Hash covers its content and class. Source is
+// the screenshot it was cut from. Variant is 0 for the file itself and n for
+// its n-th augmented copy.
+type MlSample struct {
+	Path    string
+	Class   int
+	Hash    []byte
+	Source  string
+	Variant int
+}
+
+func (s *MlSample) Image() (*image.RGBA, error) {
+	img, _, _, err := File.LoadMarked(s.Path)
 	if err != nil {
-		return fmt.Errorf("append training sample error: %v", err)
+		return nil, err
 	}
-	return nil
-}
-
-func (m *MlStruct) GRPCTest() error {
-	_, err := m.getClient().Test(context.Background(), &protos.VoidMsg{})
-	if err != nil {
-		return fmt.Errorf("append training sample error: %v", err)
+	if s.Variant > 0 {
+		img = Augment.Apply(img, s.Hash)
 	}
-	return nil
-}
-
-func (m *MlStruct) GRPCSendPredictSampleData(b *[]byte) error {
-	_, err := m.getClient().AppendPredictSample(context.Background(), &protos.MsgPredIn{Data: *b})
-	if err != nil {
-		return fmt.Errorf("append predict sample error: %v", err)
+	return img, nil
+}
+
+func MlMarkedSamples(paths []string) ([]MlSample, error) {
+	samples := make([]MlSample, 0, len(paths))
+	for _, p := range paths {
+		meta, err := File.MarkedMeta(p)
+		if err != nil {
+			return nil, fmt.Errorf("MlMarkedSamples error: %v", err)
+		}
+		h, class, err := File.HashMarked(p)
+		if err != nil {
+			return nil, fmt.Errorf("MlMarkedSamples error: %v", err)
+		}
+		samples = append(samples, MlSample{Path: p, Class: class, Hash: h, Source: meta.Source})
 	}
-	return nil
+	return samples, nil
 }
 
-func (m *MlStruct) GRPCPredict() ([]byte, error) {
-	data, err := m.getClient().Predict(context.Background(), &protos.VoidMsg{})
-	if err != nil {
-		return nil, fmt.Errorf("predict error: %v", err)
-	}
-	return data.Data, nil
+// MlModelInfo is a model snapshot made by a training run. The json tags match
+// meta.json written by mlserver.py.
+type MlModelInfo struct {
+	Version     string   `json:"Version"`
+	Created     string   `json:"Created"`
+	Samples     int      `json:"Samples"`
+	ClassNames  []string `json:"ClassNames"`
+	Loss        float64  `json:"Loss"`
+	Accuracy    float64  `json:"Accuracy"`
+	ValLoss     float64  `json:"ValLoss"`
+	ValAccuracy float64  `json:"ValAccuracy"`
+	Active      bool     `json:"-"`
 }
 
-func (m *MlStruct) GRPCInitMlParams() error {
-	_, err := m.getClient().InitMlParams(context.Background(), &protos.MsgInit{SampleSize: MarkedImageSizePixels})
-	if err != nil {
-		return fmt.Errorf("init params error: %v", err)
-	}
-	return nil
+func (i MlModelInfo) String() string {
+	return fmt.Sprintf("%v  %v  samples %d  [%v]  loss %.4f  acc %.4f  val_loss %.4f  val_acc %.4f",
+		i.Version, i.Created, i.Samples, strings.Join(i.ClassNames, ","), i.Loss, i.Accuracy, i.ValLoss, i.ValAccuracy)
 }
 
-func (m *MlStruct) GRPCTrain() error {
-	_, err := m.getClient().Train(context.Background(), &protos.VoidMsg{})
-	if err != nil {
-		return fmt.Errorf("train error: %v", err)
-	}
-	return nil
+type MlTrainProgress struct {
+	Epoch       int
+	Epochs      int
+	Loss        float64
+	Accuracy    float64
+	ValLoss     float64
+	ValAccuracy float64
 }
 
-func (m *MlStruct) StartMlServer() error {
-	m.cmd = exec.Command("python", "mlserver.py")
-	err := m.cmd.Start()
-	if err != nil {
-		log.Println("ML:", err)
-		return err
-	}
-	return nil
+func (p MlTrainProgress) String() string {
+	return fmt.Sprintf("Epoch %d/%d: loss %.4f, accuracy %.4f, val_loss %.4f, val_accuracy %.4f",
+		p.Epoch, p.Epochs, p.Loss, p.Accuracy, p.ValLoss, p.ValAccuracy)
 }
 
-func (m *MlStruct) StopMlServer() {
-	m.cmd.Process.Kill()
-}
+var (
+	Ml     MlBackend = &MlGRPC
+	MlGRPC MlGRPCStruct
+	MlGo   MlGoStruct
+)
 
-func (m *MlStruct) ConnectServer() error {
-	mlsrv_conn, err := grpc.Dial(":50555", grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return fmt.Errorf("MlServer connect error: %v", err)
+func MlSelectBackend(name string) error {
+	switch name {
+	case "", MlBackendGRPC:
+		Ml = &MlGRPC
+	case MlBackendGo:
+		if err := MlGo.LoadWeights(MlGo.activeWeightsFile()); err != nil {
+			return fmt.Errorf("MlSelectBackend error: %v", err)
+		}
+		Ml = &MlGo
+	default:
+		return fmt.Errorf("MlSelectBackend error: unknown backend %q", name)
 	}
-	m.conn = mlsrv_conn
-	x := protos.NewMessagerClient(mlsrv_conn)
-	m.client = &x
 	return nil
 }
 
-func (m *MlStruct) ServerConnected() bool {
-	if m.conn == nil {
-		return false
+// MlTopLabel returns the most probable label other than the background one
+// and its probability.
+func MlTopLabel(probs []float64) *SelectedData {
+	d := SelectedData{Class: 1}
+	for c := 1; c < len(probs); c++ {
+		if probs[c] > d.Value {
+			d.Value = probs[c]
+			d.Class = c
+		}
 	}
-	return m.conn.GetState() == connectivity.Ready
+	return &d
 }
 
-func (m *MlStruct) DisconnectServer() {
-	m.client = nil
-	m.conn.Close()
-	m.conn = nil
+func MlConfigClassNames() []string {
+	a := make([]string, len(Config.Labels))
+	for i, l := range Config.Labels {
+		a[i] = l.Name
+	}
+	return a
 }
 
-func (m *MlStruct) getClient() protos.MessagerClient {
-	return (*m.client)
+// MlImageToArray returns the sample bytes of img in the dataset shape,
+// Dataset.NumChannels bytes per pixel.
+func MlImageToArray(img *image.RGBA) *[]byte {
+	ri := Image.Sample(img)
+	n := Dataset.NumChannels()
+
+	b := make([]byte, 0, Dataset.SampleSize*Dataset.SampleSize*n)
+	for i := 0; i < int(len(ri.Pix)); i += 4 {
+		b = append(b, ri.Pix[i:i+n]...)
+	}
+
+	return &b
 }
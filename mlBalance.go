@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"sort"
+)
+
+// Balancing evens out the labels of the training samples, Config.Balancing
+// Mode picks how:
+//
+//	"undersample" drops samples of every label above MaxRatio times the
+//	              smallest one
+//	"oversample"  repeats samples of every label below the largest one
+//	              divided by MaxRatio
+//	"weights"     keeps the samples and weights the loss of every label by
+//	              the inverse of its share
+//
+// Samples are picked by hash, so the same ones are kept or repeated on every
+// run.
+const (
+	MlBalanceOff         = "off"
+	MlBalanceUndersample = "undersample"
+	MlBalanceOversample  = "oversample"
+	MlBalanceWeights     = "weights"
+)
+
+// MlBalanceSamples returns the balanced samples and the class weights for
+// the server, nil unless Mode is "weights".
+func MlBalanceSamples(samples []MlSample) ([]MlSample, []float64) {
+	b := &Config.Balancing
+	byClass := make(map[int][]MlSample)
+	for _, s := range samples {
+		byClass[s.Class] = append(byClass[s.Class], s)
+	}
+	lo, hi := len(samples), 0
+	for _, l := range byClass {
+		if len(l) < lo {
+			lo = len(l)
+		}
+		if len(l) > hi {
+			hi = len(l)
+		}
+	}
+	ratio := math.Max(b.MaxRatio, 1)
+	switch b.Mode {
+	case MlBalanceUndersample:
+		target := int(math.Ceil(float64(lo) * ratio))
+		res := make([]MlSample, 0, len(samples))
+		for _, l := range byClass {
+			res = append(res, mlSortByHash(l)[:mlMin(len(l), target)]...)
+		}
+		return mlSortByHash(res), nil
+	case MlBalanceOversample:
+		target := int(math.Ceil(float64(hi) / ratio))
+		res := append(make([]MlSample, 0, len(samples)), samples...)
+		for _, l := range byClass {
+			l = mlSortByHash(l)
+			for i := len(l); i < target; i++ {
+				res = append(res, l[i%len(l)])
+			}
+		}
+		return res, nil
+	case MlBalanceWeights:
+		weights := make([]float64, len(Config.Labels))
+		for c := range weights {
+			weights[c] = 1
+			if n := len(byClass[c]); n > 0 {
+				weights[c] = float64(len(samples)) / float64(len(byClass)*n)
+			}
+		}
+		return samples, weights
+	}
+	return samples, nil
+}
+
+func mlSortByHash(samples []MlSample) []MlSample {
+	sort.SliceStable(samples, func(i, j int) bool { return bytes.Compare(samples[i].Hash, samples[j].Hash) < 0 })
+	return samples
+}
+
+func mlMin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes of the ML backends. An *MlError wraps one of them, so the GUI
+// can branch with errors.Is.
+var (
+	ErrMlNoOutData       = errors.New("no output data")
+	ErrMlNoSession       = errors.New("no session")
+	ErrMlNoModel         = errors.New("no model")
+	ErrMlModelActive     = errors.New("model is active")
+	ErrMlBadSample       = errors.New("bad sample")
+	ErrMlShapeMismatch   = errors.New("shape mismatch")
+	ErrMlNotInitialized  = errors.New("not initialized")
+	ErrMlTrainingFailed  = errors.New("training failed")
+	ErrMlOutOfMemory     = errors.New("out of memory")
+	ErrMlInternal        = errors.New("internal error")
+	ErrMlTimeout         = errors.New("timed out")
+	ErrMlUnavailable     = errors.New("server unavailable")
+	ErrMlNotSupported    = errors.New("not supported")
+	ErrMlBadModelSpec    = errors.New("bad model spec")
+	ErrMlWeightsMismatch = errors.New("weights do not match the model spec")
+	ErrMlExportFailed    = errors.New("export failed")
+	ErrMlLabelsMismatch  = errors.New("labels do not match the model")
+)
+
+type MlError struct {
+	Op      string
+	Code    error
+	Message string
+}
+
+func (e *MlError) Error() string {
+	switch {
+	case e.Code == nil:
+		return fmt.Sprintf("%v error: %v", e.Op, e.Message)
+	case e.Message == "":
+		return fmt.Sprintf("%v error: %v", e.Op, e.Code)
+	default:
+		return fmt.Sprintf("%v error: %v: %v", e.Op, e.Code, e.Message)
+	}
+}
+
+func (e *MlError) Unwrap() error {
+	return e.Code
+}
+
+// MlErrorHint suggests what to do about err, or returns "".
+func MlErrorHint(err error) string {
+	switch {
+	case errors.Is(err, ErrMlNoModel):
+		return "Train a model or activate one on the models screen"
+	case errors.Is(err, ErrMlModelActive):
+		return "Activate another model first"
+	case errors.Is(err, ErrMlOutOfMemory):
+		return "Close other programs or train on fewer samples"
+	case errors.Is(err, ErrMlShapeMismatch):
+		return "Samples, dataset.json and the active model must agree on sample size and channels"
+	case errors.Is(err, ErrMlTimeout), errors.Is(err, ErrMlUnavailable):
+		return "Check the ML server log"
+	case errors.Is(err, ErrMlBadModelSpec):
+		return fmt.Sprintf("Fix %v", Config.Common.MlModelSpecFile)
+	case errors.Is(err, ErrMlWeightsMismatch):
+		return "Retrain the model or activate another one"
+	case errors.Is(err, ErrMlExportFailed):
+		return "Install tf2onnx for the Python of the ML server"
+	case errors.Is(err, ErrMlLabelsMismatch):
+		return "The labels of config.txt must be the classes of the model, in order"
+	case errors.Is(err, ErrMlNotSupported):
+		return fmt.Sprintf("Switch MlBackend to %q in config.txt", MlBackendGRPC)
+	}
+	return ""
+}
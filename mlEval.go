@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// MlHeldOutPercent of the marked samples are kept out of training and used by
+// the evaluation. The split goes by the hash of the source screenshot, as the
+// test split of DatasetSplitOf does: the tiles of a frame stay on one side and
+// a sample keeps its side when it is relabelled.
+const MlHeldOutPercent = 10
+
+const MlEvaluationReportFile = "evaluation.txt"
+
+func MlSplitSamples(samples []MlSample) ([]MlSample, []MlSample) {
+	train := make([]MlSample, 0, len(samples))
+	heldOut := make([]MlSample, 0, len(samples)*MlHeldOutPercent/100)
+	for _, s := range samples {
+		h := sha256.Sum256([]byte(s.Source))
+		if binary.BigEndian.Uint32(h[:])%100 < MlHeldOutPercent {
+			heldOut = append(heldOut, s)
+		} else {
+			train = append(train, s)
+		}
+	}
+	return train, heldOut
+}
+
+type MlEvaluation struct {
+	Version    string
+	ClassNames []string
+	// Confusion counts samples by true class, then by predicted class.
+	Confusion     [][]int
+	Samples       int
+	Misclassified []MlMisclassified
+}
+
+type MlMisclassified struct {
+	Path      string
+	Class     int
+	Predicted int
+}
+
+func MlEvaluate(version string, samples []MlSample, predicted []int) *MlEvaluation {
+	e := &MlEvaluation{Version: version, ClassNames: MlConfigClassNames(), Samples: len(samples)}
+	n := len(e.ClassNames)
+	e.Confusion = make([][]int, n)
+	for i := range e.Confusion {
+		e.Confusion[i] = make([]int, n)
+	}
+	for i, s := range samples {
+		p := predicted[i]
+		if p < 0 || p >= n {
+			p = 0
+		}
+		e.Confusion[s.Class][p]++
+		if p != s.Class {
+			e.Misclassified = append(e.Misclassified, MlMisclassified{Path: s.Path, Class: s.Class, Predicted: p})
+		}
+	}
+	return e
+}
+
+func (e *MlEvaluation) Accuracy() float64 {
+	if e.Samples == 0 {
+		return 0
+	}
+	return float64(e.Samples-len(e.Misclassified)) / float64(e.Samples)
+}
+
+// Metrics returns precision, recall and F1 of class c, 0 where undefined.
+func (e *MlEvaluation) Metrics(c int) (float64, float64, float64) {
+	tp := e.Confusion[c][c]
+	predicted, actual := 0, 0
+	for i := range e.Confusion {
+		predicted += e.Confusion[i][c]
+		actual += e.Confusion[c][i]
+	}
+	var p, r, f1 float64
+	if predicted > 0 {
+		p = float64(tp) / float64(predicted)
+	}
+	if actual > 0 {
+		r = float64(tp) / float64(actual)
+	}
+	if p+r > 0 {
+		f1 = 2 * p * r / (p + r)
+	}
+	return p, r, f1
+}
+
+// Lines is the summary shown in GuiTextView and at the top of the report.
+func (e *MlEvaluation) Lines() []string {
+	a := make([]string, 0)
+	version := e.Version
+	if version == "" {
+		version = "(unknown)"
+	}
+	a = append(a, fmt.Sprintf("Model %v, %d held-out samples, accuracy %.4f", version, e.Samples, e.Accuracy()))
+	w := 9
+	for _, name := range e.ClassNames {
+		if len(name) > w {
+			w = len(name)
+		}
+	}
+	a = append(a, fmt.Sprintf("%-*s %9s %9s %9s %9s", w, "class", "precision", "recall", "f1", "samples"))
+	for c, name := range e.ClassNames {
+		p, r, f1 := e.Metrics(c)
+		n := 0
+		for _, v := range e.Confusion[c] {
+			n += v
+		}
+		a = append(a, fmt.Sprintf("%-*s %9.4f %9.4f %9.4f %9d", w, name, p, r, f1, n))
+	}
+	a = append(a, "Confusion matrix, rows are true classes, columns predicted:")
+	s := fmt.Sprintf("%-*s", w, "")
+	for _, name := range e.ClassNames {
+		s += fmt.Sprintf(" %*s", w, name)
+	}
+	a = append(a, s)
+	for c, name := range e.ClassNames {
+		s := fmt.Sprintf("%-*s", w, name)
+		for _, v := range e.Confusion[c] {
+			s += fmt.Sprintf(" %*d", w, v)
+		}
+		a = append(a, s)
+	}
+	return a
+}
+
+// WriteReport writes the summary and the misclassified samples as
+// path, true class and predicted class separated by tabs.
+func (e *MlEvaluation) WriteReport(fileName string) error {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "Evaluated %v\n", time.Now().Format("2006-01-02 15:04:05"))
+	for _, s := range e.Lines() {
+		b.WriteString(s + "\n")
+	}
+	fmt.Fprintf(&b, "\nMisclassified %d:\n", len(e.Misclassified))
+	for _, m := range e.Misclassified {
+		fmt.Fprintf(&b, "%v\t%v\t%v\n", m.Path, e.ClassNames[m.Class], e.ClassNames[m.Predicted])
+	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("WriteReport error: %v", err)
+	}
+	return nil
+}
+
+func mlArgMax(probs []float64) int {
+	c := 0
+	for i := range probs {
+		if probs[i] > probs[c] {
+			c = i
+		}
+	}
+	return c
+}
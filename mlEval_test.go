@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMlSplitSamplesBySource(t *testing.T) {
+	samples := make([]MlSample, 0)
+	for i := 0; i < 300; i++ {
+		s := MlSample{Source: fmt.Sprintf("src%03d", i/3), Class: i % 2, Hash: []byte{byte(i), byte(i >> 8), 0, 0}}
+		s.Path = fmt.Sprintf("%v.%d.%d.png", s.Source, i%3, s.Class)
+		samples = append(samples, s)
+	}
+	// Relabelling changes the class and the hash, not the source.
+	relabelled := append([]MlSample{}, samples...)
+	for i := range relabelled {
+		relabelled[i].Class = 1 - relabelled[i].Class
+		relabelled[i].Hash = []byte{0xff, byte(i), 0, 0}
+	}
+	for _, l := range [][]MlSample{samples, relabelled} {
+		train, heldOut := MlSplitSamples(l)
+		if len(heldOut) == 0 || len(train)+len(heldOut) != len(l) {
+			t.Fatalf("%d train and %d held-out of %d samples", len(train), len(heldOut), len(l))
+		}
+		for _, s := range heldOut {
+			if DatasetSplitOf(s.Source) != "test" {
+				t.Errorf("%v held out, its source is in the %v split", s.Path, DatasetSplitOf(s.Source))
+			}
+		}
+		for _, s := range train {
+			if DatasetSplitOf(s.Source) == "test" {
+				t.Errorf("%v trained on, its source is in the test split", s.Path)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Exported models go to MlExportFolder as <version>.onnx with the sidecar
+// <version>.json, see ExportModel in mlserver.py. The ONNX model takes
+// float32 NHWC samples scaled to 0..1 and returns a probability per class.
+const MlExportFolder = "export"
+
+type MlExportSidecar struct {
+	Version    string   `json:"Version"`
+	Created    string   `json:"Created"`
+	SampleSize int      `json:"SampleSize"`
+	Channels   string   `json:"Channels"`
+	ClassNames []string `json:"ClassNames"`
+	Input      string   `json:"Input"`
+}
+
+type MlExportedModel struct {
+	Sidecar MlExportSidecar
+	Size    int64
+	Input   MlOnnxValue
+	Output  MlOnnxValue
+}
+
+func mlExportSidecarFile(onnxFile string) string {
+	return strings.TrimSuffix(onnxFile, ".onnx") + ".json"
+}
+
+// MlVerifyExportedModel checks an exported model for other tools: its sidecar
+// must take the samples of the dataset and know the labels of config.txt in
+// the same order, and its ONNX graph must take N float32 samples of that
+// shape and return a probability per label. The app does not run ONNX models
+// itself.
+func MlVerifyExportedModel(onnxFile string) (*MlExportedModel, error) {
+	data, err := os.ReadFile(mlExportSidecarFile(onnxFile))
+	if err != nil {
+		return nil, fmt.Errorf("verify exported model error: %w", err)
+	}
+	m := &MlExportedModel{}
+	if err := json.Unmarshal(data, &m.Sidecar); err != nil {
+		return nil, fmt.Errorf("verify exported model error: %v: %v", mlExportSidecarFile(onnxFile), err)
+	}
+	if err := m.Sidecar.Verify(); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(onnxFile)
+	if err != nil {
+		return nil, fmt.Errorf("verify exported model error: %w", err)
+	}
+	m.Size = info.Size()
+	x, err := MlReadOnnx(onnxFile)
+	if err != nil {
+		return nil, fmt.Errorf("verify exported model error: %w", err)
+	}
+	if len(x.Inputs) != 1 || len(x.Outputs) != 1 {
+		return nil, fmt.Errorf("verify exported model error: %v has %d inputs and %d outputs, 1 and 1 expected", onnxFile, len(x.Inputs), len(x.Outputs))
+	}
+	m.Input, m.Output = x.Inputs[0], x.Outputs[0]
+	size, n := int64(Dataset.SampleSize), int64(Dataset.NumChannels())
+	if in := m.Input.Shape; m.Input.ElemType != onnxFloat || len(in) != 4 || in[1] != size || in[2] != size || in[3] != n {
+		return nil, &MlError{Op: "verify exported model", Code: ErrMlShapeMismatch,
+			Message: fmt.Sprintf("%v takes %v samples of type %d, the dataset is %v", onnxFile, &m.Input, m.Input.ElemType, &Dataset)}
+	}
+	if out := m.Output.Shape; len(out) != 2 || out[1] != int64(len(m.Sidecar.ClassNames)) {
+		return nil, &MlError{Op: "verify exported model", Code: ErrMlLabelsMismatch,
+			Message: fmt.Sprintf("%v returns %v, %d classes expected", onnxFile, &m.Output, len(m.Sidecar.ClassNames))}
+	}
+	return m, nil
+}
+
+func (s *MlExportSidecar) Verify() error {
+	if s.SampleSize != Dataset.SampleSize || s.Channels != Dataset.Channels {
+		return &MlError{Op: "verify exported model", Code: ErrMlShapeMismatch,
+			Message: fmt.Sprintf("model %v takes %dx%d %v samples, the dataset is %v", s.Version, s.SampleSize, s.SampleSize, s.Channels, &Dataset)}
+	}
+	names := MlConfigClassNames()
+	if len(names) != len(s.ClassNames) {
+		return &MlError{Op: "verify exported model", Code: ErrMlLabelsMismatch,
+			Message: fmt.Sprintf("model %v has %d classes, config.txt %d labels", s.Version, len(s.ClassNames), len(names))}
+	}
+	for i := range names {
+		if names[i] != s.ClassNames[i] {
+			return &MlError{Op: "verify exported model", Code: ErrMlLabelsMismatch,
+				Message: fmt.Sprintf("model %v class %d is %q, label %d is %q", s.Version, i, s.ClassNames[i], i, names[i])}
+		}
+	}
+	return nil
+}
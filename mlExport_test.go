@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func mlExportTestMessage(fields ...func(b []byte) []byte) []byte {
+	var b []byte
+	for _, f := range fields {
+		b = f(b)
+	}
+	return b
+}
+
+func mlExportTestBytes(num protowire.Number, v []byte) func(b []byte) []byte {
+	return func(b []byte) []byte {
+		return protowire.AppendBytes(protowire.AppendTag(b, num, protowire.BytesType), v)
+	}
+}
+
+func mlExportTestVarint(num protowire.Number, v uint64) func(b []byte) []byte {
+	return func(b []byte) []byte {
+		return protowire.AppendVarint(protowire.AppendTag(b, num, protowire.VarintType), v)
+	}
+}
+
+// mlExportTestValue is a float ValueInfoProto, a shape of -1 is the batch
+// dimension "N".
+func mlExportTestValue(name string, shape ...int64) []byte {
+	dims := make([]func(b []byte) []byte, 0)
+	for _, d := range shape {
+		dim := mlExportTestMessage(mlExportTestBytes(2, []byte("N")))
+		if d >= 0 {
+			dim = mlExportTestMessage(mlExportTestVarint(onnxDimValue, uint64(d)))
+		}
+		dims = append(dims, mlExportTestBytes(onnxShapeDim, dim))
+	}
+	tensor := mlExportTestMessage(mlExportTestVarint(onnxTensorElemType, onnxFloat), mlExportTestBytes(onnxTensorShape, mlExportTestMessage(dims...)))
+	return mlExportTestMessage(
+		mlExportTestBytes(onnxValueInfoName, []byte(name)),
+		mlExportTestBytes(onnxValueInfoType, mlExportTestMessage(mlExportTestBytes(onnxTypeTensor, tensor))))
+}
+
+// mlExportTestModel writes a model of the input and output shapes with the
+// sidecar of an 8x8 gray two label dataset and returns the ONNX file.
+func mlExportTestModel(t *testing.T, data []byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	f := filepath.Join(dir, "20260101-000000.onnx")
+	if err := os.WriteFile(f, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sidecar := `{"Version": "20260101-000000", "SampleSize": 8, "Channels": "gray", "ClassNames": ["bg", "obj"]}`
+	if err := os.WriteFile(mlExportSidecarFile(f), []byte(sidecar), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func mlExportTestOnnx(input []int64, output []int64) []byte {
+	graph := mlExportTestMessage(
+		// A weight listed as an input as well, as before IR version 4.
+		mlExportTestBytes(onnxGraphInitializer, mlExportTestMessage(mlExportTestBytes(onnxTensorName, []byte("dense/kernel")))),
+		mlExportTestBytes(onnxGraphInput, mlExportTestValue("input", input...)),
+		mlExportTestBytes(onnxGraphInput, mlExportTestValue("dense/kernel", 16, 2)),
+		mlExportTestBytes(onnxGraphOutput, mlExportTestValue("probs", output...)))
+	return mlExportTestMessage(
+		mlExportTestVarint(onnxModelIrVersion, 8),
+		mlExportTestBytes(onnxModelGraph, graph),
+		mlExportTestBytes(onnxModelOpsetImport, mlExportTestMessage(mlExportTestVarint(2, 13))))
+}
+
+func TestMlVerifyExportedModel(t *testing.T) {
+	mlGoTestSetup(t)
+	good := mlExportTestOnnx([]int64{-1, 8, 8, 1}, []int64{-1, 2})
+	m, err := MlVerifyExportedModel(mlExportTestModel(t, good))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Input.String() != "Nx8x8x1" || m.Output.String() != "Nx2" {
+		t.Errorf("got input %v and output %v", &m.Input, &m.Output)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		code error
+	}{
+		{"truncated", good[:len(good)-20], nil},
+		{"cut after the graph", good[:len(good)-4], nil},
+		{"not ONNX", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), nil},
+		{"empty", nil, nil},
+		{"sample size", mlExportTestOnnx([]int64{-1, 16, 16, 1}, []int64{-1, 2}), ErrMlShapeMismatch},
+		{"channels", mlExportTestOnnx([]int64{-1, 8, 8, 3}, []int64{-1, 2}), ErrMlShapeMismatch},
+		{"classes", mlExportTestOnnx([]int64{-1, 8, 8, 1}, []int64{-1, 3}), ErrMlLabelsMismatch},
+	}
+	for _, tt := range tests {
+		_, err := MlVerifyExportedModel(mlExportTestModel(t, tt.data))
+		if err == nil || (tt.code != nil && !errors.Is(err, tt.code)) {
+			t.Errorf("%v: got %v, want %v", tt.name, err, tt.code)
+		}
+	}
+}
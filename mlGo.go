@@ -0,0 +1,496 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const mlGoWeightsMagic = "SCRW"
+
+type mlGoLayerKind int
+
+const (
+	mlGoLayerConv mlGoLayerKind = iota
+	mlGoLayerMaxPool
+	mlGoLayerDense
+)
+
+// mlGoLayerKinds maps the model spec layers to the ones run here. Flatten is
+// implicit in the HWC tensor layout and Dropout does nothing at inference
+// time, so both are skipped.
+var mlGoLayerKinds = map[string]mlGoLayerKind{
+	MlSpecConv:    mlGoLayerConv,
+	MlSpecMaxPool: mlGoLayerMaxPool,
+	MlSpecDense:   mlGoLayerDense,
+}
+
+type mlGoLayer struct {
+	kind       mlGoLayerKind
+	activation string
+	kh, kw     int
+	in         int
+	out        int
+	kernel     []float32
+	bias       []float32
+}
+
+type mlGoTensor struct {
+	h, w, c int
+	data    []float32
+}
+
+type mlGoWeight struct {
+	shape []int
+	data  []float32
+}
+
+// MlGoStruct runs the BuildModel CNN on the CPU from the weights exported by
+// mlserver.py. It can only predict, training stays on the gRPC backend.
+type MlGoStruct struct {
+	mut    sync.RWMutex
+	layers []mlGoLayer
+}
+
+func (m *MlGoStruct) Ready() bool {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	return m.layers != nil
+}
+
+func (m *MlGoStruct) OpenSession(ctx context.Context) (MlSession, error) {
+	return 0, nil
+}
+
+func (m *MlGoStruct) CloseSession(ctx context.Context, ses MlSession) error {
+	return nil
+}
+
+func (m *MlGoStruct) InitParams(ctx context.Context, ses MlSession, classWeights []float64) error {
+	return nil
+}
+
+func (m *MlGoStruct) UploadSamples(ctx context.Context, ses MlSession, samples []MlSample, progress func(sent, total int)) error {
+	return &MlError{Op: "upload samples", Code: ErrMlNotSupported, Message: "training needs the " + MlBackendGRPC + " backend"}
+}
+
+func (m *MlGoStruct) Train(ctx context.Context, ses MlSession, progress func(p MlTrainProgress)) error {
+	return &MlError{Op: "train", Code: ErrMlNotSupported, Message: "training needs the " + MlBackendGRPC + " backend"}
+}
+
+func (m *MlGoStruct) CancelTrain(ctx context.Context, ses MlSession) error {
+	return &MlError{Op: "cancel train", Code: ErrMlNotSupported, Message: "training needs the " + MlBackendGRPC + " backend"}
+}
+
+func (m *MlGoStruct) Predict(ctx context.Context, ses MlSession, tiles []*image.RGBA) ([][]float64, error) {
+	m.mut.RLock()
+	layers := m.layers
+	m.mut.RUnlock()
+	if layers == nil {
+		return nil, &MlError{Op: "predict frame", Code: ErrMlNoModel, Message: "weights are not loaded"}
+	}
+
+	probs := make([][]float64, len(tiles))
+	idx_ch := make(chan int)
+	wg := sync.WaitGroup{}
+	numWorkers := runtime.NumCPU()
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for n := range idx_ch {
+				if ctx.Err() != nil {
+					continue
+				}
+				out := m.forward(layers, m.imageToTensor(tiles[n]))
+				probs[n] = make([]float64, len(out.data))
+				for c, v := range out.data {
+					probs[n][c] = float64(v)
+				}
+			}
+		}()
+	}
+	for n := range tiles {
+		idx_ch <- n
+	}
+	close(idx_ch)
+	wg.Wait()
+	if err := ctx.Err(); err == context.DeadlineExceeded {
+		return nil, &MlError{Op: "predict frame", Code: ErrMlTimeout}
+	} else if err != nil {
+		return nil, &MlError{Op: "predict frame", Message: err.Error()}
+	}
+	return probs, nil
+}
+
+// Evaluate loads and predicts the samples in batches, there is nothing to
+// upload.
+func (m *MlGoStruct) Evaluate(ctx context.Context, ses MlSession, samples []MlSample, progress func(sent, total int)) ([]int, error) {
+	const batch = 256
+	predicted := make([]int, 0, len(samples))
+	for i := 0; i < len(samples); i += batch {
+		end := i + batch
+		if end > len(samples) {
+			end = len(samples)
+		}
+		tiles := make([]*image.RGBA, 0, batch)
+		for _, s := range samples[i:end] {
+			img, err := File.LoadImage(s.Path)
+			if err != nil {
+				return nil, &MlError{Op: "evaluate", Code: ErrMlBadSample, Message: err.Error()}
+			}
+			tiles = append(tiles, img)
+		}
+		probs, err := m.Predict(ctx, ses, tiles)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range probs {
+			predicted = append(predicted, mlArgMax(p))
+		}
+		progress(len(predicted), len(samples))
+	}
+	return predicted, nil
+}
+
+// ListModels reads the registry folder directly, so the models trained by
+// mlserver.py can be browsed without the server.
+func (m *MlGoStruct) ListModels(ctx context.Context) ([]MlModelInfo, error) {
+	entries, err := os.ReadDir(MlModelsFolder)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list models error: %v", err)
+	}
+	active := m.activeVersion()
+	models := make([]MlModelInfo, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(MlModelsFolder, e.Name(), "meta.json"))
+		if err != nil {
+			continue
+		}
+		i := MlModelInfo{}
+		if err := json.Unmarshal(data, &i); err != nil {
+			return nil, fmt.Errorf("list models error: %v: %v", e.Name(), err)
+		}
+		i.Active = i.Version == active
+		models = append(models, i)
+	}
+	sort.Slice(models, func(a, b int) bool { return models[a].Version < models[b].Version })
+	return models, nil
+}
+
+func (m *MlGoStruct) ActivateModel(ctx context.Context, version string) error {
+	if err := m.LoadWeights(filepath.Join(MlModelsFolder, version, "weights.bin")); errors.Is(err, os.ErrNotExist) {
+		return &MlError{Op: "activate model", Code: ErrMlNoModel, Message: fmt.Sprintf("model %v not found", version)}
+	} else if err != nil {
+		var mlErr *MlError
+		if errors.As(err, &mlErr) {
+			return &MlError{Op: "activate model", Code: mlErr.Code, Message: mlErr.Message}
+		}
+		return &MlError{Op: "activate model", Code: ErrMlWeightsMismatch, Message: err.Error()}
+	}
+	if err := os.WriteFile(filepath.Join(MlModelsFolder, MlActiveModelFile), []byte(version), 0644); err != nil {
+		return fmt.Errorf("activate model error: %v", err)
+	}
+	return nil
+}
+
+func (m *MlGoStruct) DeleteModel(ctx context.Context, version string) error {
+	if version == m.activeVersion() {
+		return &MlError{Op: "delete model", Code: ErrMlModelActive, Message: fmt.Sprintf("model %v is active", version)}
+	}
+	dir := filepath.Join(MlModelsFolder, version)
+	if _, err := os.Stat(filepath.Join(dir, "meta.json")); err != nil {
+		return &MlError{Op: "delete model", Code: ErrMlNoModel, Message: fmt.Sprintf("model %v not found", version)}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("delete model error: %v", err)
+	}
+	return nil
+}
+
+func (m *MlGoStruct) ExportModel(ctx context.Context, version string, folder string) (string, string, error) {
+	return "", "", &MlError{Op: "export model", Code: ErrMlNotSupported, Message: "ONNX export needs the " + MlBackendGRPC + " backend"}
+}
+
+func (m *MlGoStruct) activeVersion() string {
+	data, err := os.ReadFile(filepath.Join(MlModelsFolder, MlActiveModelFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// activeWeightsFile falls back to MlGoWeightsFile until a model is activated.
+func (m *MlGoStruct) activeWeightsFile() string {
+	if v := m.activeVersion(); v != "" {
+		f := filepath.Join(MlModelsFolder, v, "weights.bin")
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return Config.Common.MlGoWeightsFile
+}
+
+func (m *MlGoStruct) LoadWeights(fPath string) error {
+	f, err := os.Open(fPath)
+	if err != nil {
+		return fmt.Errorf("LoadWeights error: %w", err)
+	}
+	defer f.Close()
+	weights, err := m.readWeights(bufio.NewReader(f))
+	if err != nil {
+		return fmt.Errorf("LoadWeights error: %v", err)
+	}
+	spec, err := mlModelSpecOf(fPath)
+	if err != nil {
+		return fmt.Errorf("LoadWeights error: %w", err)
+	}
+	layers, err := m.buildLayers(spec, weights)
+	if err != nil {
+		return &MlError{Op: "load weights", Code: ErrMlWeightsMismatch, Message: fmt.Sprintf("%v: %v", fPath, err)}
+	}
+	m.mut.Lock()
+	m.layers = layers
+	m.mut.Unlock()
+	return nil
+}
+
+func (m *MlGoStruct) readWeights(r io.Reader) ([]mlGoWeight, error) {
+	magic := make([]byte, len(mlGoWeightsMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != mlGoWeightsMagic {
+		return nil, fmt.Errorf("bad magic %q", magic)
+	}
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+	weights := make([]mlGoWeight, 0, count)
+	for i := 0; i < int(count); i++ {
+		var ndim uint32
+		if err := binary.Read(r, binary.LittleEndian, &ndim); err != nil {
+			return nil, err
+		}
+		dims := make([]uint32, ndim)
+		if err := binary.Read(r, binary.LittleEndian, dims); err != nil {
+			return nil, err
+		}
+		w := mlGoWeight{shape: make([]int, ndim)}
+		size := 1
+		for k, d := range dims {
+			w.shape[k] = int(d)
+			size *= int(d)
+		}
+		w.data = make([]float32, size)
+		if err := binary.Read(r, binary.LittleEndian, w.data); err != nil {
+			return nil, err
+		}
+		weights = append(weights, w)
+	}
+	return weights, nil
+}
+
+func (m *MlGoStruct) buildLayers(spec *MlModelSpec, weights []mlGoWeight) ([]mlGoLayer, error) {
+	layers := make([]mlGoLayer, 0, len(spec.Layers))
+	channels := Dataset.NumChannels()
+	side := Dataset.SampleSize
+	for _, sl := range spec.Layers {
+		kind, ok := mlGoLayerKinds[sl.Type]
+		if !ok {
+			continue
+		}
+		l := mlGoLayer{kind: kind, activation: sl.Activation}
+		switch kind {
+		case mlGoLayerMaxPool:
+			side /= 2
+			layers = append(layers, l)
+			continue
+		case mlGoLayerConv:
+			if len(weights) < 2 || len(weights[0].shape) != 4 {
+				return nil, fmt.Errorf("layer %d: conv weights expected", len(layers))
+			}
+			s := weights[0].shape
+			l.kh, l.kw, l.in, l.out = s[0], s[1], s[2], s[3]
+			if l.kh != sl.Kernel || l.kw != sl.Kernel || l.out != sl.Filters {
+				return nil, fmt.Errorf("layer %d: %dx%d kernel of %d filters expected, got %dx%d of %d", len(layers), sl.Kernel, sl.Kernel, sl.Filters, l.kh, l.kw, l.out)
+			}
+		case mlGoLayerDense:
+			if len(weights) < 2 || len(weights[0].shape) != 2 {
+				return nil, fmt.Errorf("layer %d: dense weights expected", len(layers))
+			}
+			s := weights[0].shape
+			l.in, l.out = s[0], s[1]
+			if sl.Units != 0 && l.out != sl.Units {
+				return nil, fmt.Errorf("layer %d: %d units expected, got %d", len(layers), sl.Units, l.out)
+			}
+			// The first dense layer flattens the tensor, the later ones
+			// take the units of the one before.
+			channels *= side * side
+			side = 1
+		}
+		if l.in != channels {
+			return nil, fmt.Errorf("layer %d: %d inputs expected, got %d", len(layers), channels, l.in)
+		}
+		if len(weights[1].data) != l.out {
+			return nil, fmt.Errorf("layer %d: %d biases expected, got %d", len(layers), l.out, len(weights[1].data))
+		}
+		l.kernel = weights[0].data
+		l.bias = weights[1].data
+		weights = weights[2:]
+		channels = l.out
+		layers = append(layers, l)
+	}
+	if len(weights) != 0 {
+		return nil, fmt.Errorf("%d unused weight tensors", len(weights))
+	}
+	if channels != len(Config.Labels) {
+		return nil, fmt.Errorf("%d output classes expected, got %d", len(Config.Labels), channels)
+	}
+	return layers, nil
+}
+
+func (m *MlGoStruct) imageToTensor(img *image.RGBA) *mlGoTensor {
+	b := MlImageToArray(img)
+	t := &mlGoTensor{h: Dataset.SampleSize, w: Dataset.SampleSize, c: Dataset.NumChannels(), data: make([]float32, len(*b))}
+	for i, v := range *b {
+		t.data[i] = float32(v) / 255
+	}
+	return t
+}
+
+func (m *MlGoStruct) forward(layers []mlGoLayer, t *mlGoTensor) *mlGoTensor {
+	for i := range layers {
+		switch layers[i].kind {
+		case mlGoLayerConv:
+			t = layers[i].conv(t)
+		case mlGoLayerMaxPool:
+			t = layers[i].maxPool(t)
+		case mlGoLayerDense:
+			t = layers[i].dense(t)
+		}
+	}
+	return t
+}
+
+// conv is a Conv2D with "same" padding. The kernel layout is the Keras one:
+// (kh, kw, in, out).
+func (l *mlGoLayer) conv(t *mlGoTensor) *mlGoTensor {
+	o := &mlGoTensor{h: t.h, w: t.w, c: l.out, data: make([]float32, t.h*t.w*l.out)}
+	padY := (l.kh - 1) / 2
+	padX := (l.kw - 1) / 2
+	for y := 0; y < t.h; y++ {
+		for x := 0; x < t.w; x++ {
+			acc := o.data[(y*t.w+x)*l.out : (y*t.w+x+1)*l.out]
+			copy(acc, l.bias)
+			for ky := 0; ky < l.kh; ky++ {
+				sy := y + ky - padY
+				if sy < 0 || sy >= t.h {
+					continue
+				}
+				for kx := 0; kx < l.kw; kx++ {
+					sx := x + kx - padX
+					if sx < 0 || sx >= t.w {
+						continue
+					}
+					src := t.data[(sy*t.w+sx)*t.c : (sy*t.w+sx+1)*t.c]
+					k := l.kernel[(ky*l.kw+kx)*l.in*l.out:]
+					for ci, v := range src {
+						row := k[ci*l.out : (ci+1)*l.out]
+						for co := range acc {
+							acc[co] += v * row[co]
+						}
+					}
+				}
+			}
+			l.activate(acc)
+		}
+	}
+	return o
+}
+
+// maxPool is a MaxPool2D with the Keras defaults: 2x2 window, stride 2, valid.
+func (l *mlGoLayer) maxPool(t *mlGoTensor) *mlGoTensor {
+	o := &mlGoTensor{h: t.h / 2, w: t.w / 2, c: t.c}
+	o.data = make([]float32, o.h*o.w*o.c)
+	for y := 0; y < o.h; y++ {
+		for x := 0; x < o.w; x++ {
+			dst := o.data[(y*o.w+x)*o.c : (y*o.w+x+1)*o.c]
+			for c := range dst {
+				dst[c] = float32(math.Inf(-1))
+			}
+			for dy := 0; dy < 2; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					src := t.data[((y*2+dy)*t.w+x*2+dx)*t.c:]
+					for c := range dst {
+						if src[c] > dst[c] {
+							dst[c] = src[c]
+						}
+					}
+				}
+			}
+		}
+	}
+	return o
+}
+
+// dense is a Dense layer over the flattened tensor.
+func (l *mlGoLayer) dense(t *mlGoTensor) *mlGoTensor {
+	o := &mlGoTensor{h: 1, w: 1, c: l.out, data: make([]float32, l.out)}
+	copy(o.data, l.bias)
+	for i, v := range t.data {
+		row := l.kernel[i*l.out : (i+1)*l.out]
+		for j := range o.data {
+			o.data[j] += v * row[j]
+		}
+	}
+	l.activate(o.data)
+	return o
+}
+
+// activate applies the spec activation in place, softmax over x as a whole.
+func (l *mlGoLayer) activate(x []float32) {
+	switch l.activation {
+	case "relu":
+		for i := range x {
+			if x[i] < 0 {
+				x[i] = 0
+			}
+		}
+	case "softmax":
+		max := x[0]
+		for _, v := range x {
+			if v > max {
+				max = v
+			}
+		}
+		var sum float64
+		for j, v := range x {
+			e := math.Exp(float64(v - max))
+			x[j] = float32(e)
+			sum += e
+		}
+		for j := range x {
+			x[j] = float32(float64(x[j]) / sum)
+		}
+	}
+}
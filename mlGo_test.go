@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type mlGoTestTensor struct {
+	shape []uint32
+	data  []float32
+}
+
+// mlGoTestSetup makes an 8x8 gray dataset of two labels.
+func mlGoTestSetup(t *testing.T) {
+	t.Helper()
+	dataset, labels := Dataset, Config.Labels
+	t.Cleanup(func() { Dataset, Config.Labels = dataset, labels })
+	Dataset = DatasetStruct{SampleSize: 8, Channels: DatasetChannelsGray}
+	Config.Labels = []ConfigLabel{{Name: "bg"}, {Name: "obj"}}
+}
+
+// mlGoTestWeights writes the weights file of the tensors with the spec of
+// layers next to it and returns its path.
+func mlGoTestWeights(t *testing.T, layers []MlSpecLayer, tensors ...mlGoTestTensor) string {
+	t.Helper()
+	dir := t.TempDir()
+	b := &bytes.Buffer{}
+	b.WriteString(mlGoWeightsMagic)
+	binary.Write(b, binary.LittleEndian, uint32(len(tensors)))
+	for _, x := range tensors {
+		binary.Write(b, binary.LittleEndian, uint32(len(x.shape)))
+		binary.Write(b, binary.LittleEndian, x.shape)
+		binary.Write(b, binary.LittleEndian, x.data)
+	}
+	f := filepath.Join(dir, "weights.bin")
+	if err := os.WriteFile(f, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec := MlDefaultModelSpec()
+	spec.Layers = layers
+	if err := os.WriteFile(filepath.Join(dir, MlModelSpecFileName), []byte(spec.JSON()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func mlGoTestFill(n int, v float32) []float32 {
+	a := make([]float32, n)
+	for i := range a {
+		a[i] = v
+	}
+	return a
+}
+
+func mlGoTestImage(v uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.RGBA{v, v, v, 0xff})
+		}
+	}
+	return img
+}
+
+// A 1x1 identity conv, a pool to 4x4 and a dense layer that sums the pixels
+// into the second class.
+var mlGoTestLayers = []MlSpecLayer{
+	{Type: MlSpecConv, Filters: 1, Kernel: 1, Activation: "relu"},
+	{Type: MlSpecMaxPool},
+	{Type: MlSpecFlatten},
+	{Type: MlSpecDense, Activation: "softmax"},
+}
+
+func mlGoTestTensors() []mlGoTestTensor {
+	dense := make([]float32, 16*2)
+	for i := 0; i < 16; i++ {
+		dense[i*2+1] = 0.25
+	}
+	return []mlGoTestTensor{
+		{shape: []uint32{1, 1, 1, 1}, data: []float32{1}},
+		{shape: []uint32{1}, data: []float32{0}},
+		{shape: []uint32{16, 2}, data: dense},
+		{shape: []uint32{2}, data: []float32{0, 0}},
+	}
+}
+
+func TestMlGoPredict(t *testing.T) {
+	mlGoTestSetup(t)
+	m := &MlGoStruct{}
+	if err := m.LoadWeights(mlGoTestWeights(t, mlGoTestLayers, mlGoTestTensors()...)); err != nil {
+		t.Fatal(err)
+	}
+	probs, err := m.Predict(context.Background(), 0, []*image.RGBA{mlGoTestImage(0), mlGoTestImage(255)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Black sums to 0, white to 16 * 0.25.
+	want := [][]float64{{0.5, 0.5}, {1 / (1 + math.Exp(4)), 1 / (1 + math.Exp(-4))}}
+	for i := range want {
+		for c := range want[i] {
+			if math.Abs(probs[i][c]-want[i][c]) > 1e-6 {
+				t.Errorf("tile %d class %d: got %v, want %v", i, c, probs[i][c], want[i][c])
+			}
+		}
+	}
+}
+
+func TestMlGoForwardConv(t *testing.T) {
+	mlGoTestSetup(t)
+	// A 3x3 kernel of ones sums the neighbours, the padding adds nothing.
+	l := mlGoLayer{kind: mlGoLayerConv, kh: 3, kw: 3, in: 1, out: 1, kernel: mlGoTestFill(9, 1), bias: []float32{0}}
+	in := &mlGoTensor{h: 3, w: 3, c: 1, data: mlGoTestFill(9, 1)}
+	out := (&MlGoStruct{}).forward([]mlGoLayer{l}, in)
+	want := []float32{4, 6, 4, 6, 9, 6, 4, 6, 4}
+	for i := range want {
+		if out.data[i] != want[i] {
+			t.Fatalf("got %v, want %v", out.data, want)
+		}
+	}
+}
+
+func TestMlGoBuildLayersMismatch(t *testing.T) {
+	mlGoTestSetup(t)
+	tensors := mlGoTestTensors()
+	tests := []struct {
+		name    string
+		tensors []mlGoTestTensor
+	}{
+		{"missing dense", tensors[:2]},
+		{"extra tensor", append(append([]mlGoTestTensor{}, tensors...), tensors[1])},
+		{"dense inputs", []mlGoTestTensor{tensors[0], tensors[1], {shape: []uint32{8, 2}, data: make([]float32, 16)}, tensors[3]}},
+		{"conv filters", []mlGoTestTensor{{shape: []uint32{1, 1, 1, 2}, data: []float32{1, 1}}, {shape: []uint32{2}, data: []float32{0, 0}}, tensors[2], tensors[3]}},
+	}
+	for _, tt := range tests {
+		err := (&MlGoStruct{}).LoadWeights(mlGoTestWeights(t, mlGoTestLayers, tt.tensors...))
+		if !errors.Is(err, ErrMlWeightsMismatch) {
+			t.Errorf("%v: got %v, want %v", tt.name, err, ErrMlWeightsMismatch)
+		}
+	}
+	Config.Labels = append(Config.Labels, ConfigLabel{Name: "other"})
+	err := (&MlGoStruct{}).LoadWeights(mlGoTestWeights(t, mlGoTestLayers, tensors...))
+	if !errors.Is(err, ErrMlWeightsMismatch) {
+		t.Errorf("2 classes for %d labels: got %v, want %v", len(Config.Labels), err, ErrMlWeightsMismatch)
+	}
+}
+
+func TestMlGoPredictWithoutWeights(t *testing.T) {
+	mlGoTestSetup(t)
+	_, err := (&MlGoStruct{}).Predict(context.Background(), 0, []*image.RGBA{mlGoTestImage(0)})
+	if !errors.Is(err, ErrMlNoModel) {
+		t.Errorf("got %v, want %v", err, ErrMlNoModel)
+	}
+}
+
+func TestMlGoTwoDenseLayers(t *testing.T) {
+	mlGoTestSetup(t)
+	layers := []MlSpecLayer{
+		mlGoTestLayers[0], mlGoTestLayers[1], mlGoTestLayers[2],
+		{Type: MlSpecDense, Units: 3, Activation: "relu"},
+		mlGoTestLayers[3],
+	}
+	tensors := mlGoTestTensors()
+	// The hidden layer sums the pixels into its first unit, the output
+	// layer passes that on to the second class.
+	hidden := make([]float32, 16*3)
+	for i := 0; i < 16; i++ {
+		hidden[i*3] = 0.25
+	}
+	out := make([]float32, 3*2)
+	out[1] = 1
+	weights := []mlGoTestTensor{
+		tensors[0], tensors[1],
+		{shape: []uint32{16, 3}, data: hidden}, {shape: []uint32{3}, data: make([]float32, 3)},
+		{shape: []uint32{3, 2}, data: out}, tensors[3],
+	}
+	m := &MlGoStruct{}
+	if err := m.LoadWeights(mlGoTestWeights(t, layers, weights...)); err != nil {
+		t.Fatal(err)
+	}
+	probs, err := m.Predict(context.Background(), 0, []*image.RGBA{mlGoTestImage(255)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 1 / (1 + math.Exp(-4)); math.Abs(probs[0][1]-want) > 1e-6 {
+		t.Errorf("got %v, want %v", probs[0][1], want)
+	}
+}
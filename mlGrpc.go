@@ -0,0 +1,522 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"scrml/protos"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// mlUploadChunkBytes keeps a sample chunk well under the 4MB gRPC message limit.
+const mlUploadChunkBytes = 2 << 20
+
+type MlGRPCStruct struct {
+	mut     sync.Mutex
+	cmd     *exec.Cmd
+	conn    *grpc.ClientConn
+	client  *protos.MessagerClient
+	srvLog  *os.File
+	srvStop chan struct{}
+	srvDone chan struct{}
+	run     int
+}
+
+func (m *MlGRPCStruct) Ready() bool {
+	return m.ServerConnected()
+}
+
+func (m *MlGRPCStruct) OpenSession(ctx context.Context) (MlSession, error) {
+	var ses MlSession
+	err := m.call(ctx, "open session", Config.Common.mlCallTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		out, err := c.OpenSession(ctx, &protos.VoidMsg{})
+		if err != nil {
+			return err
+		}
+		ses = MlSession(out.Id)
+		return nil
+	})
+	return ses, err
+}
+
+func (m *MlGRPCStruct) CloseSession(ctx context.Context, ses MlSession) error {
+	return m.call(ctx, "close session", Config.Common.mlCallTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		out, err := c.CloseSession(ctx, &protos.MsgSession{Id: int64(ses)})
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+}
+
+// UploadSamples asks the server which samples it is missing and streams them
+// in chunks. After a dropped stream it asks again and resumes with what is
+// still missing.
+func (m *MlGRPCStruct) UploadSamples(ctx context.Context, ses MlSession, samples []MlSample, progress func(sent, total int)) error {
+	byHash := make(map[string]*MlSample, len(samples))
+	hashes := make([][]byte, 0, len(samples))
+	for i := range samples {
+		byHash[string(samples[i].Hash)] = &samples[i]
+		hashes = append(hashes, samples[i].Hash)
+	}
+	delay := Config.Common.mlRetryBackoff
+	total := -1
+	for attempt := 0; ; attempt++ {
+		var missing [][]byte
+		err := m.call(ctx, "sync samples", Config.Common.mlPredictTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+			out, err := c.SyncSamples(ctx, &protos.MsgSampleHashes{Session: int64(ses), Hashes: hashes})
+			if err != nil {
+				return err
+			}
+			missing = out.Hashes
+			return m.enumError(out.Err, out.Message)
+		})
+		if err != nil {
+			return err
+		}
+		if total == -1 {
+			total = len(missing)
+		}
+		if len(missing) == 0 {
+			progress(total, total)
+			return nil
+		}
+		if attempt > Config.Common.MlRetries {
+			return &MlError{Op: "upload samples", Code: ErrMlBadSample, Message: fmt.Sprintf("%d samples are still missing", len(missing))}
+		}
+		err = m.uploadChunks(ctx, ses, missing, byHash, func(n int) { progress(total-len(missing)+n, total) })
+		if err == nil {
+			continue
+		}
+		if status.Code(err) != codes.Unavailable {
+			return m.opError("upload samples", err, 0)
+		}
+		m.serverLog(fmt.Sprintf("Upload samples interrupted, resume in %v: %v", delay, err))
+		select {
+		case <-ctx.Done():
+			return m.opError("upload samples", status.FromContextError(ctx.Err()).Err(), 0)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (m *MlGRPCStruct) uploadChunks(ctx context.Context, ses MlSession, missing [][]byte, byHash map[string]*MlSample, sent func(n int)) error {
+	c, err := m.getClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.UploadSamples(ctx)
+	if err != nil {
+		return err
+	}
+	chunk := &protos.MsgSampleChunk{Session: int64(ses)}
+	chunkBytes := 0
+	n := 0
+	flush := func() error {
+		if len(chunk.Samples) == 0 {
+			return nil
+		}
+		if err := stream.Send(chunk); err == io.EOF {
+			// The server ended the stream, its status tells why.
+			ack, err := stream.CloseAndRecv()
+			if err != nil {
+				return err
+			}
+			if err := m.enumError(ack.Err, ack.Message); err != nil {
+				return err
+			}
+			return fmt.Errorf("stream closed by the server")
+		} else if err != nil {
+			return err
+		}
+		n += len(chunk.Samples)
+		sent(n)
+		chunk = &protos.MsgSampleChunk{Session: int64(ses)}
+		chunkBytes = 0
+		return nil
+	}
+	for _, h := range missing {
+		s, ok := byHash[string(h)]
+		if !ok {
+			return fmt.Errorf("server asked for an unknown sample")
+		}
+		img, err := s.Image()
+		if err != nil {
+			return err
+		}
+		b := MlImageToArray(img)
+		chunk.Samples = append(chunk.Samples, &protos.MsgSampleData{Hash: s.Hash, XData: *b, YData: int64(s.Class)})
+		chunkBytes += len(*b)
+		if chunkBytes >= mlUploadChunkBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	ack, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return m.enumError(ack.Err, ack.Message)
+}
+
+func (m *MlGRPCStruct) Test(ctx context.Context) error {
+	return m.call(ctx, "test", Config.Common.mlCallTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		out, err := c.Test(ctx, &protos.VoidMsg{})
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+}
+
+func (m *MlGRPCStruct) Predict(ctx context.Context, ses MlSession, tiles []*image.RGBA) ([][]float64, error) {
+	msg := &protos.MsgPredFrameIn{Tiles: make([][]byte, 0, len(tiles)), Session: int64(ses), NumClasses: int64(len(Config.Labels)), Channels: int64(Dataset.NumChannels())}
+	for _, t := range tiles {
+		msg.Tiles = append(msg.Tiles, *MlImageToArray(t))
+	}
+	var out *protos.MsgPredFrameOut
+	err := m.call(ctx, "predict frame", Config.Common.mlPredictTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		var err error
+		out, err = c.PredictFrame(ctx, msg)
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	n := int(out.NumClasses)
+	if n == 0 || len(out.Probs) != n*len(tiles) {
+		return nil, &MlError{Op: "predict frame", Code: ErrMlShapeMismatch, Message: fmt.Sprintf("%d probabilities for %d tiles of %d classes", len(out.Probs), len(tiles), n)}
+	}
+	probs := make([][]float64, len(tiles))
+	for i := range probs {
+		probs[i] = make([]float64, n)
+		for c := range probs[i] {
+			probs[i][c] = float64(out.Probs[i*n+c])
+		}
+	}
+	return probs, nil
+}
+
+func (m *MlGRPCStruct) Evaluate(ctx context.Context, ses MlSession, samples []MlSample, progress func(sent, total int)) ([]int, error) {
+	if err := m.UploadSamples(ctx, ses, samples, progress); err != nil {
+		return nil, err
+	}
+	var out *protos.MsgEvalResult
+	err := m.call(ctx, "evaluate", Config.Common.mlPredictTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		var err error
+		out, err = c.Evaluate(ctx, &protos.MsgSession{Id: int64(ses)})
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Predicted) != len(samples) {
+		return nil, &MlError{Op: "evaluate", Code: ErrMlShapeMismatch, Message: fmt.Sprintf("%d classes for %d samples", len(out.Predicted), len(samples))}
+	}
+	predicted := make([]int, len(samples))
+	for i, c := range out.Predicted {
+		predicted[i] = int(c)
+	}
+	return predicted, nil
+}
+
+// InitParams sends the model spec of MlModelSpecFile, the server uses its
+// default spec without one.
+func (m *MlGRPCStruct) InitParams(ctx context.Context, ses MlSession, classWeights []float64) error {
+	msg := &protos.MsgInit{SampleSize: int64(Dataset.SampleSize), Channels: int64(Dataset.NumChannels()), Session: int64(ses), NumClasses: int64(len(Config.Labels)), ClassNames: MlConfigClassNames()}
+	for _, w := range classWeights {
+		msg.ClassWeights = append(msg.ClassWeights, float32(w))
+	}
+	if Config.Common.MlModelSpecFile != "" {
+		spec, err := MlLoadModelSpec(Config.Common.MlModelSpecFile)
+		if err != nil {
+			return err
+		}
+		msg.ModelSpec = spec.JSON()
+	}
+	return m.call(ctx, "init params", Config.Common.mlCallTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		out, err := c.InitMlParams(ctx, msg)
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+}
+
+// Train is not retried, a second Train on the same session would start over.
+func (m *MlGRPCStruct) Train(ctx context.Context, ses MlSession, progress func(p MlTrainProgress)) error {
+	c, err := m.getClient()
+	if err != nil {
+		return m.opError("train", err, 0)
+	}
+	if Config.Common.mlTrainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Config.Common.mlTrainTimeout)
+		defer cancel()
+	}
+	stream, err := c.Train(ctx, &protos.MsgSession{Id: int64(ses)})
+	if err != nil {
+		return m.opError("train", err, Config.Common.mlTrainTimeout)
+	}
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return m.opError("train", err, Config.Common.mlTrainTimeout)
+		}
+		if err := m.enumError(p.Err, p.Message); err != nil {
+			return m.opError("train", err, 0)
+		}
+		progress(MlTrainProgress{
+			Epoch:       int(p.Epoch),
+			Epochs:      int(p.Epochs),
+			Loss:        float64(p.Loss),
+			Accuracy:    float64(p.Accuracy),
+			ValLoss:     float64(p.ValLoss),
+			ValAccuracy: float64(p.ValAccuracy),
+		})
+	}
+}
+
+func (m *MlGRPCStruct) CancelTrain(ctx context.Context, ses MlSession) error {
+	return m.call(ctx, "cancel train", Config.Common.mlCallTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		out, err := c.CancelTrain(ctx, &protos.MsgSession{Id: int64(ses)})
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+}
+
+func (m *MlGRPCStruct) ListModels(ctx context.Context) ([]MlModelInfo, error) {
+	var out *protos.MsgModelList
+	err := m.call(ctx, "list models", Config.Common.mlCallTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		var err error
+		out, err = c.ListModels(ctx, &protos.VoidMsg{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	models := make([]MlModelInfo, 0, len(out.Models))
+	for _, i := range out.Models {
+		models = append(models, MlModelInfo{
+			Version:     i.Version,
+			Created:     i.Created,
+			Samples:     int(i.Samples),
+			ClassNames:  i.ClassNames,
+			Loss:        float64(i.Loss),
+			Accuracy:    float64(i.Accuracy),
+			ValLoss:     float64(i.ValLoss),
+			ValAccuracy: float64(i.ValAccuracy),
+			Active:      i.Active,
+		})
+	}
+	return models, nil
+}
+
+// ActivateModel builds the model on the server, so it gets the predict timeout.
+func (m *MlGRPCStruct) ActivateModel(ctx context.Context, version string) error {
+	return m.call(ctx, "activate model", Config.Common.mlPredictTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		out, err := c.ActivateModel(ctx, &protos.MsgModelId{Version: version})
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+}
+
+func (m *MlGRPCStruct) DeleteModel(ctx context.Context, version string) error {
+	return m.call(ctx, "delete model", Config.Common.mlCallTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		out, err := c.DeleteModel(ctx, &protos.MsgModelId{Version: version})
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+}
+
+// ExportModel converts the model on the server, so it gets the train timeout.
+func (m *MlGRPCStruct) ExportModel(ctx context.Context, version string, folder string) (string, string, error) {
+	var out *protos.MsgExportOut
+	err := m.call(ctx, "export model", Config.Common.mlTrainTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		var err error
+		out, err = c.ExportModel(ctx, &protos.MsgExportIn{Version: version, Folder: folder})
+		if err != nil {
+			return err
+		}
+		return m.enumError(out.Err, out.Message)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return out.ModelFile, out.SidecarFile, nil
+}
+
+// call runs f with the timeout, zero means no deadline. While the server is
+// unavailable f is retried MlRetries times with a doubling backoff.
+func (m *MlGRPCStruct) call(ctx context.Context, name string, timeout time.Duration, f func(ctx context.Context, c protos.MessagerClient) error) error {
+	delay := Config.Common.mlRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := m.callOnce(ctx, timeout, f)
+		if err == nil {
+			return nil
+		}
+		if status.Code(err) != codes.Unavailable || attempt >= Config.Common.MlRetries {
+			return m.opError(name, err, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return m.opError(name, status.FromContextError(ctx.Err()).Err(), 0)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (m *MlGRPCStruct) callOnce(ctx context.Context, timeout time.Duration, f func(ctx context.Context, c protos.MessagerClient) error) error {
+	c, err := m.getClient()
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return f(ctx, c)
+}
+
+// opError turns err into an *MlError of the operation op.
+func (m *MlGRPCStruct) opError(op string, err error, timeout time.Duration) error {
+	var e *MlError
+	if errors.As(err, &e) {
+		e.Op = op
+		return e
+	}
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		if timeout > 0 {
+			return &MlError{Op: op, Code: ErrMlTimeout, Message: fmt.Sprintf("no reply in %v", timeout)}
+		}
+		return &MlError{Op: op, Code: ErrMlTimeout}
+	case codes.Unavailable:
+		return &MlError{Op: op, Code: ErrMlUnavailable, Message: status.Convert(err).Message()}
+	}
+	return &MlError{Op: op, Message: err.Error()}
+}
+
+var mlGRPCErrorCodes = map[protos.EnumError]error{
+	protos.EnumError_ENUMERROR_NOOUTDATA:       ErrMlNoOutData,
+	protos.EnumError_ENUMERROR_NOSESSION:       ErrMlNoSession,
+	protos.EnumError_ENUMERROR_NOMODEL:         ErrMlNoModel,
+	protos.EnumError_ENUMERROR_MODELACTIVE:     ErrMlModelActive,
+	protos.EnumError_ENUMERROR_BADSAMPLE:       ErrMlBadSample,
+	protos.EnumError_ENUMERROR_SHAPEMISMATCH:   ErrMlShapeMismatch,
+	protos.EnumError_ENUMERROR_NOTINITIALIZED:  ErrMlNotInitialized,
+	protos.EnumError_ENUMERROR_TRAININGFAILED:  ErrMlTrainingFailed,
+	protos.EnumError_ENUMERROR_OUTOFMEMORY:     ErrMlOutOfMemory,
+	protos.EnumError_ENUMERROR_INTERNAL:        ErrMlInternal,
+	protos.EnumError_ENUMERROR_BADMODELSPEC:    ErrMlBadModelSpec,
+	protos.EnumError_ENUMERROR_WEIGHTSMISMATCH: ErrMlWeightsMismatch,
+	protos.EnumError_ENUMERROR_EXPORTFAILED:    ErrMlExportFailed,
+}
+
+func (m *MlGRPCStruct) enumError(e protos.EnumError, message string) error {
+	if e == protos.EnumError_ENUMERROR_NOERROR {
+		return nil
+	}
+	code, ok := mlGRPCErrorCodes[e]
+	if !ok {
+		return &MlError{Code: ErrMlInternal, Message: fmt.Sprintf("%v %v", e, message)}
+	}
+	return &MlError{Code: code, Message: message}
+}
+
+func (m *MlGRPCStruct) address() string {
+	if Config.Common.MlServerAddress != "" {
+		return Config.Common.MlServerAddress
+	}
+	return fmt.Sprintf("127.0.0.1:%d", Config.Common.MlServerPort)
+}
+
+func (m *MlGRPCStruct) ConnectServer() error {
+	// The default reconnect backoff grows to two minutes, too long to notice
+	// a restarted server.
+	params := grpc.ConnectParams{Backoff: backoff.DefaultConfig, MinConnectTimeout: mlServerTestTimeout}
+	params.Backoff.MaxDelay = mlServerBackoffMin
+	mlsrv_conn, err := grpc.Dial(m.address(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithConnectParams(params))
+	if err != nil {
+		return fmt.Errorf("MlServer connect error: %v", err)
+	}
+	x := protos.NewMessagerClient(mlsrv_conn)
+	m.mut.Lock()
+	if m.conn != nil {
+		m.conn.Close()
+	}
+	m.conn = mlsrv_conn
+	m.client = &x
+	m.mut.Unlock()
+	return nil
+}
+
+func (m *MlGRPCStruct) ServerConnected() bool {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.conn == nil {
+		return false
+	}
+	return m.conn.GetState() == connectivity.Ready
+}
+
+func (m *MlGRPCStruct) DisconnectServer() {
+	m.mut.Lock()
+	m.client = nil
+	if m.conn != nil {
+		m.conn.Close()
+	}
+	m.conn = nil
+	m.mut.Unlock()
+}
+
+func (m *MlGRPCStruct) connected() bool {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.conn != nil
+}
+
+func (m *MlGRPCStruct) getClient() (protos.MessagerClient, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.client == nil {
+		return nil, status.Error(codes.Unavailable, "not connected to "+m.address())
+	}
+	return *m.client, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMlUploadSamplesResume(t *testing.T) {
+	bin := fakeMlServer(t)
+	old, dataset := Config, Dataset
+	t.Cleanup(func() { Config, Dataset = old, dataset })
+	Config.Labels = []ConfigLabel{{Name: "bg"}, {Name: "obj"}}
+	Config.Common.DataRoot = t.TempDir()
+	Config.Common.MlServerPort = mlServerTestPort(t)
+	Config.Common.MlRetries = 5
+	Config.Common.mlRetryBackoff = 10 * time.Millisecond
+	Config.Common.mlCallTimeout = 5 * time.Second
+	Config.Common.mlPredictTimeout = 5 * time.Second
+	Dataset = DatasetStruct{SampleSize: 64, Channels: DatasetChannelsRGB}
+
+	// 12k a sample, a 2M chunk takes 171 of them.
+	for i := 0; i < 400; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+		for k := 3; k < len(img.Pix); k += 4 {
+			img.Pix[k] = 0xff
+		}
+		img.Pix[0], img.Pix[1] = byte(i), byte(i>>8)
+		if err := File.SaveMarked(img, &SampleMeta{Class: i % 2, Source: fmt.Sprintf("src%03d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	samples, err := MlMarkedSamples(File.GetMarkedDataList())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "-drop-after-chunk", "1", "--port", strconv.Itoa(Config.Common.MlServerPort))
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+	m := &MlGRPCStruct{}
+	log, err := os.Create(filepath.Join(t.TempDir(), "mlserver.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	m.srvLog = log
+	if err := m.ConnectServer(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.DisconnectServer()
+	for deadline := time.Now().Add(10 * time.Second); m.Test(context.Background()) != nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("fake server not ready")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	last, total := -1, -1
+	err = m.UploadSamples(context.Background(), 1, samples, func(sent, n int) {
+		if sent < last {
+			t.Errorf("progress went back from %d to %d", last, sent)
+		}
+		last, total = sent, n
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != len(samples) || total != len(samples) {
+		t.Errorf("progress ended at %d of %d, want %d", last, total, len(samples))
+	}
+	data, _ := os.ReadFile(log.Name())
+	// Every stream drops after a chunk: 171, 171 and the last 58 samples.
+	if n := strings.Count(string(data), "Upload samples interrupted"); n != 3 {
+		t.Errorf("%d interrupted uploads, want 3:\n%s", n, data)
+	}
+
+	// All there, a second upload sends nothing.
+	err = m.UploadSamples(context.Background(), 1, samples, func(sent, n int) { total = n })
+	if err != nil || total != 0 {
+		t.Errorf("second upload: %d to send, %v", total, err)
+	}
+}
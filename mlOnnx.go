@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// An exported model is checked by reading just enough of its ONNX file: the
+// element types and shapes of the graph inputs and outputs. The field numbers
+// are the ones of onnx.proto.
+const (
+	onnxModelIrVersion   protowire.Number = 1
+	onnxModelGraph       protowire.Number = 7
+	onnxModelOpsetImport protowire.Number = 8
+	onnxGraphInitializer protowire.Number = 5
+	onnxGraphInput       protowire.Number = 11
+	onnxGraphOutput      protowire.Number = 12
+	onnxTensorName       protowire.Number = 8
+	onnxValueInfoName    protowire.Number = 1
+	onnxValueInfoType    protowire.Number = 2
+	onnxTypeTensor       protowire.Number = 1
+	onnxTensorElemType   protowire.Number = 1
+	onnxTensorShape      protowire.Number = 2
+	onnxShapeDim         protowire.Number = 1
+	onnxDimValue         protowire.Number = 1
+
+	// onnxFloat is the TensorProto.FLOAT element type.
+	onnxFloat = 1
+)
+
+// MlOnnxValue is a graph input or output. A dimension without a value, as
+// the batch size of the exported models, is -1.
+type MlOnnxValue struct {
+	Name     string
+	ElemType int64
+	Shape    []int64
+}
+
+type MlOnnxModel struct {
+	IrVersion int64
+	Inputs    []MlOnnxValue
+	Outputs   []MlOnnxValue
+}
+
+func (v *MlOnnxValue) String() string {
+	s := ""
+	for i, d := range v.Shape {
+		if i > 0 {
+			s += "x"
+		}
+		if d < 0 {
+			s += "N"
+		} else {
+			s += fmt.Sprint(d)
+		}
+	}
+	return s
+}
+
+func MlReadOnnx(file string) (*MlOnnxModel, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read ONNX error: %w", err)
+	}
+	m, err := mlOnnxParse(data)
+	if err != nil {
+		return nil, fmt.Errorf("read ONNX error: %v is not an ONNX model: %v", file, err)
+	}
+	return m, nil
+}
+
+func mlOnnxParse(data []byte) (*MlOnnxModel, error) {
+	m := &MlOnnxModel{}
+	var graph []byte
+	opset := false
+	err := mlOnnxWalk(data, func(num protowire.Number, v uint64, b []byte) error {
+		switch num {
+		case onnxModelIrVersion:
+			m.IrVersion = int64(v)
+		case onnxModelGraph:
+			graph = b
+		case onnxModelOpsetImport:
+			opset = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The graph comes before the opset imports, a file cut short loses them.
+	if m.IrVersion <= 0 || graph == nil || !opset {
+		return nil, errors.New("no graph or opset import")
+	}
+	initializers := make(map[string]bool)
+	var inputs []MlOnnxValue
+	err = mlOnnxWalk(graph, func(num protowire.Number, v uint64, b []byte) error {
+		switch num {
+		case onnxGraphInitializer:
+			return mlOnnxWalk(b, func(num protowire.Number, v uint64, b []byte) error {
+				if num == onnxTensorName {
+					initializers[string(b)] = true
+				}
+				return nil
+			})
+		case onnxGraphInput, onnxGraphOutput:
+			x, err := mlOnnxParseValue(b)
+			if err != nil {
+				return err
+			}
+			if num == onnxGraphInput {
+				inputs = append(inputs, x)
+			} else {
+				m.Outputs = append(m.Outputs, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Before IR version 4 the initializers are graph inputs as well.
+	for _, x := range inputs {
+		if !initializers[x.Name] {
+			m.Inputs = append(m.Inputs, x)
+		}
+	}
+	return m, nil
+}
+
+func mlOnnxParseValue(data []byte) (MlOnnxValue, error) {
+	x := MlOnnxValue{}
+	err := mlOnnxWalk(data, func(num protowire.Number, v uint64, b []byte) error {
+		switch num {
+		case onnxValueInfoName:
+			x.Name = string(b)
+		case onnxValueInfoType:
+			return mlOnnxWalk(b, func(num protowire.Number, v uint64, b []byte) error {
+				if num != onnxTypeTensor {
+					return nil
+				}
+				return mlOnnxWalk(b, func(num protowire.Number, v uint64, b []byte) error {
+					switch num {
+					case onnxTensorElemType:
+						x.ElemType = int64(v)
+					case onnxTensorShape:
+						return mlOnnxWalk(b, func(num protowire.Number, v uint64, b []byte) error {
+							if num != onnxShapeDim {
+								return nil
+							}
+							d := int64(-1)
+							err := mlOnnxWalk(b, func(num protowire.Number, v uint64, b []byte) error {
+								if num == onnxDimValue {
+									d = int64(v)
+								}
+								return nil
+							})
+							x.Shape = append(x.Shape, d)
+							return err
+						})
+					}
+					return nil
+				})
+			})
+		}
+		return nil
+	})
+	return x, err
+}
+
+// mlOnnxWalk calls f for every field of the message data, v is the value of
+// a varint and b the bytes of a length delimited field.
+func mlOnnxWalk(data []byte, f func(num protowire.Number, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		var v uint64
+		var b []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			b, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := f(num, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
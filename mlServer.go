@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"scrml/protos"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The ML server process is owned by MlGRPC. When MlServerManaged is set it is
+// started from MlServerInterpreter and MlServerScript, restarted with a backoff
+// when it exits and asked to shut down when the app closes. Either way the
+// connection is health checked with the Test RPC.
+
+const (
+	mlServerBackoffMin   = time.Second
+	mlServerBackoffMax   = 30 * time.Second
+	mlServerStableRun    = time.Minute
+	mlServerHealthPeriod = time.Second
+	mlServerTestTimeout  = 2 * time.Second
+	mlServerStopTimeout  = 10 * time.Second
+)
+
+func (m *MlGRPCStruct) StartMlServer() error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.srvStop != nil {
+		return fmt.Errorf("StartMlServer error: already started")
+	}
+	if Config.Common.MlServerLogFile != "" {
+		f, err := os.OpenFile(Config.Common.MlServerLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("StartMlServer error: %v", err)
+		}
+		m.srvLog = f
+	}
+	m.srvStop = make(chan struct{})
+	m.srvDone = make(chan struct{})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.watchMlServer(m.srvStop)
+	}()
+	if Config.Common.MlServerManaged {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.superviseMlServer(m.srvStop)
+		}()
+	}
+	go func(done chan struct{}) {
+		wg.Wait()
+		close(done)
+	}(m.srvDone)
+	return nil
+}
+
+// StopMlServer shuts the managed server down gracefully, killing it if it
+// does not exit in time, and drops the connection.
+func (m *MlGRPCStruct) StopMlServer() {
+	m.mut.Lock()
+	stop, done := m.srvStop, m.srvDone
+	m.srvStop, m.srvDone = nil, nil
+	m.mut.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+	m.DisconnectServer()
+	m.mut.Lock()
+	if m.srvLog != nil {
+		m.srvLog.Close()
+		m.srvLog = nil
+	}
+	m.mut.Unlock()
+}
+
+func (m *MlGRPCStruct) watchMlServer(stop chan struct{}) {
+	ticker := time.NewTicker(mlServerHealthPeriod)
+	defer ticker.Stop()
+	ready, lost := false, false
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		var err error
+		if !m.connected() {
+			err = m.ConnectServer()
+		}
+		if err == nil {
+			// No retries here, the next tick is the retry.
+			err = m.callOnce(context.Background(), mlServerTestTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+				_, err := c.Test(ctx, &protos.VoidMsg{})
+				return err
+			})
+		}
+		if (err == nil) != ready {
+			ready = err == nil
+			if ready {
+				if lost {
+					m.mut.Lock()
+					m.run++
+					m.mut.Unlock()
+				}
+				m.serverLog("ML server ready")
+			} else {
+				lost = true
+				m.serverLog(fmt.Sprintf("ML server not ready: %v", err))
+			}
+		}
+	}
+}
+
+// Run counts the times the server got ready again after it was lost. The
+// sessions of an earlier run went with the server process.
+func (m *MlGRPCStruct) Run() int {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.run
+}
+
+func (m *MlGRPCStruct) superviseMlServer(stop chan struct{}) {
+	backoff := mlServerBackoffMin
+	for {
+		started := time.Now()
+		exited, err := m.launchMlServer()
+		if err != nil {
+			m.serverLog(fmt.Sprintf("ML server start error: %v", err))
+		} else {
+			select {
+			case <-stop:
+				m.shutdownMlServer(exited)
+				return
+			case err := <-exited:
+				m.serverLog(fmt.Sprintf("ML server exited: %v", err))
+			}
+		}
+		if time.Since(started) > mlServerStableRun {
+			backoff = mlServerBackoffMin
+		}
+		m.serverLog(fmt.Sprintf("Restart ML server in %v", backoff))
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > mlServerBackoffMax {
+			backoff = mlServerBackoffMax
+		}
+	}
+}
+
+// launchMlServer starts the process and returns a channel getting its exit
+// status. Without a script the interpreter is run directly, so a fake server
+// binary can take its place.
+func (m *MlGRPCStruct) launchMlServer() (chan error, error) {
+	args := make([]string, 0)
+	if Config.Common.MlServerScript != "" {
+		args = append(args, Config.Common.MlServerScript)
+	}
+	args = append(args, "--port", strconv.Itoa(Config.Common.MlServerPort))
+	cmd := exec.Command(Config.Common.MlServerInterpreter, args...)
+	cmd.Env = append(os.Environ(), "PYTHONUNBUFFERED=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	m.mut.Lock()
+	m.cmd = cmd
+	m.mut.Unlock()
+	m.serverLog(fmt.Sprintf("ML server started, pid %d", cmd.Process.Pid))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	for _, r := range []io.Reader{stdout, stderr} {
+		go func(r io.Reader) {
+			defer wg.Done()
+			s := bufio.NewScanner(r)
+			for s.Scan() {
+				m.serverOutput(s.Text())
+			}
+		}(r)
+	}
+	exited := make(chan error, 1)
+	go func() {
+		wg.Wait()
+		exited <- cmd.Wait()
+	}()
+	return exited, nil
+}
+
+func (m *MlGRPCStruct) shutdownMlServer(exited chan error) {
+	m.serverLog("Stop ML server")
+	m.callOnce(context.Background(), mlServerTestTimeout, func(ctx context.Context, c protos.MessagerClient) error {
+		_, err := c.Shutdown(ctx, &protos.VoidMsg{})
+		return err
+	})
+	select {
+	case <-exited:
+	case <-time.After(mlServerStopTimeout):
+		m.serverLog("ML server did not stop, kill it")
+		m.mut.Lock()
+		m.cmd.Process.Kill()
+		m.mut.Unlock()
+		<-exited
+	}
+}
+
+func (m *MlGRPCStruct) serverLog(s string) {
+	log.Println(s)
+	m.serverOutput(s)
+}
+
+func (m *MlGRPCStruct) serverOutput(s string) {
+	m.mut.Lock()
+	if m.srvLog != nil {
+		fmt.Fprintf(m.srvLog, "%v %v\n", time.Now().Format("2006-01-02 15:04:05"), s)
+	}
+	m.mut.Unlock()
+	GuiTextView.PutString("ML: " + s)
+}
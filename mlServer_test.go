@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	fakeMlServerOnce sync.Once
+	fakeMlServerBin  string
+	fakeMlServerErr  error
+)
+
+// fakeMlServer builds fakemlserver once for the test binary.
+func fakeMlServer(t *testing.T) string {
+	t.Helper()
+	fakeMlServerOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "fakemlserver")
+		if err != nil {
+			fakeMlServerErr = err
+			return
+		}
+		fakeMlServerBin = filepath.Join(dir, "fakemlserver")
+		if runtime.GOOS == "windows" {
+			fakeMlServerBin += ".exe"
+		}
+		out, err := exec.Command("go", "build", "-o", fakeMlServerBin, "./fakemlserver").CombinedOutput()
+		if err != nil {
+			fakeMlServerErr = err
+			fakeMlServerBin = string(out)
+		}
+	})
+	if fakeMlServerErr != nil {
+		t.Skipf("fakemlserver build error: %v %v", fakeMlServerErr, fakeMlServerBin)
+	}
+	return fakeMlServerBin
+}
+
+func mlServerTestPort(t *testing.T) int {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+// mlServerTestSetup manages the fake server with the FAKEMLSERVER_ settings
+// of env and returns the server log file.
+func mlServerTestSetup(t *testing.T, env map[string]string) string {
+	t.Helper()
+	bin := fakeMlServer(t)
+	old := Config
+	t.Cleanup(func() { Config = old })
+	log := filepath.Join(t.TempDir(), "mlserver.log")
+	Config.Common.MlServerManaged = true
+	Config.Common.MlServerInterpreter = bin
+	Config.Common.MlServerScript = ""
+	Config.Common.MlServerPort = mlServerTestPort(t)
+	Config.Common.MlServerLogFile = log
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	return log
+}
+
+// mlServerTestWait polls the server log until it has n lines containing s
+// and returns the time the last one showed up.
+func mlServerTestWait(t *testing.T, log string, s string, n int, timeout time.Duration) time.Time {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(log)
+		if strings.Count(string(data), s) >= n {
+			return time.Now()
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	data, _ := os.ReadFile(log)
+	t.Fatalf("no %d %q in %v:\n%s", n, s, timeout, data)
+	return time.Time{}
+}
+
+func TestMlServerReadyDelay(t *testing.T) {
+	const delay = 2 * time.Second
+	log := mlServerTestSetup(t, map[string]string{"FAKEMLSERVER_READY_DELAY": delay.String()})
+	m := &MlGRPCStruct{}
+	t0 := time.Now()
+	if err := m.StartMlServer(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.StopMlServer()
+
+	mlServerTestWait(t, log, "ML server started", 1, 5*time.Second)
+	if m.Ready() {
+		t.Errorf("ready before the server listens")
+	}
+	ready := mlServerTestWait(t, log, "ML server ready", 1, delay+5*time.Second)
+	if d := ready.Sub(t0); d < delay {
+		t.Errorf("ready after %v, the server listens after %v", d, delay)
+	}
+	if !m.Ready() {
+		t.Errorf("not ready after the ready log")
+	}
+	// The first ready is the run the sessions opened so far belong to.
+	if m.Run() != 0 {
+		t.Errorf("run %d after the first ready, want 0", m.Run())
+	}
+}
+
+func TestMlServerRestartBackoff(t *testing.T) {
+	log := mlServerTestSetup(t, map[string]string{"FAKEMLSERVER_CRASH_AFTER": "300ms"})
+	m := &MlGRPCStruct{}
+	if err := m.StartMlServer(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.StopMlServer()
+
+	exited := mlServerTestWait(t, log, "ML server exited", 1, 5*time.Second)
+	mlServerTestWait(t, log, "Restart ML server in "+mlServerBackoffMin.String(), 1, time.Second)
+	restarted := mlServerTestWait(t, log, "ML server started", 2, mlServerBackoffMin+5*time.Second)
+	// The polling may see the exit late, never the restart early.
+	if d := restarted.Sub(exited); d < mlServerBackoffMin-100*time.Millisecond {
+		t.Errorf("restarted %v after the crash, the backoff is %v", d, mlServerBackoffMin)
+	}
+	// The second crash comes before mlServerStableRun, the backoff doubles.
+	mlServerTestWait(t, log, "Restart ML server in "+(2*mlServerBackoffMin).String(), 1, 5*time.Second)
+}
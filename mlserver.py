@@ -8,10 +8,17 @@ from keras import layers
 from keras import datasets
 from keras import utils
 from keras import callbacks
+from keras import optimizers as keras_optimizers
 from datetime import datetime
 import os.path
+import struct
+import sys
+import threading
 import numpy as np
 import os
+import queue
+import json
+import shutil
 # os.environ['CUDA_VISIBLE_DEVICES'] = '-1'
 
 
@@ -25,190 +32,785 @@ import os
 
 
 weights_file_name = "mlserver_weights"
+go_weights_file_name = "mlserver_weights.bin"
+models_dir = "models"
+default_port = 50555
+# grayscale, RGB and RGB with alpha
+sample_channels = (1, 3, 4)
+shutdown_event = threading.Event()
+active_model_file_name = os.path.join(models_dir, "active.txt")
+default_export_dir = "export"
+channel_modes = {1: 'gray', 3: 'rgb', 4: 'rgba'}
+spec_file_name = "spec.json"
+
+# The network used before model specs, for models trained without one. The
+# client validates specs by the same rules in modelSpec.go.
+default_spec = {
+    'Layers': [
+        {'Type': 'conv', 'Filters': 32, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'conv', 'Filters': 32, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'conv', 'Filters': 16, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'conv', 'Filters': 16, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'maxpool'},
+        {'Type': 'conv', 'Filters': 64, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'conv', 'Filters': 64, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'conv', 'Filters': 64, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'maxpool'},
+        {'Type': 'conv', 'Filters': 128, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'maxpool'},
+        {'Type': 'conv', 'Filters': 256, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'maxpool'},
+        {'Type': 'conv', 'Filters': 512, 'Kernel': 3, 'Activation': 'relu'},
+        {'Type': 'flatten'},
+        {'Type': 'dropout', 'Rate': 0.5},
+        {'Type': 'dense', 'Activation': 'softmax'},
+    ],
+    'Optimizer': 'adam',
+    'LearningRate': 0.001,
+    'BatchSize': 100,
+    'Epochs': 100,
+    'ValidationSplit': 0.1,
+    'EarlyStopping': {'Monitor': 'loss', 'Patience': 5, 'MinDelta': 0.01},
+}
+
+layer_fields = ('Type', 'Filters', 'Kernel', 'Units', 'Activation', 'Rate')
+spec_fields = ('Layers', 'Optimizer', 'LearningRate', 'BatchSize', 'Epochs', 'ValidationSplit', 'EarlyStopping')
+
+# parseSpec returns the spec of the JSON text and None, or None and what is
+# wrong with it.
+def parseSpec(text, sample_size):
+    try:
+        spec = json.loads(text)
+    except ValueError as e:
+        return None, str(e)
+    if not isinstance(spec, dict):
+        return None, "the spec is not an object"
+    for k in spec:
+        if k not in spec_fields:
+            return None, "unknown field %s" % k
+    layers_ = spec.get('Layers') or []
+    if len(layers_) == 0:
+        return None, "no layers"
+    side = sample_size
+    flat = False
+    for i, l in enumerate(layers_):
+        for k in l:
+            if k not in layer_fields:
+                return None, "layer %d: unknown field %s" % (i, k)
+        t = l.get('Type')
+        if t == 'conv':
+            if flat:
+                return None, "layer %d: conv after flatten" % i
+            if l.get('Filters', 0) <= 0 or l.get('Kernel', 0) <= 0 or l.get('Kernel', 0) % 2 == 0:
+                return None, "layer %d: conv needs Filters and an odd Kernel" % i
+        elif t == 'maxpool':
+            if flat:
+                return None, "layer %d: maxpool after flatten" % i
+            side //= 2
+            if side == 0:
+                return None, "layer %d: %d px samples are pooled to nothing" % (i, sample_size)
+        elif t == 'flatten':
+            flat = True
+        elif t == 'dropout':
+            if not 0 <= l.get('Rate', 0) < 1:
+                return None, "layer %d: dropout Rate is expected in 0..1" % i
+        elif t == 'dense':
+            if not flat:
+                return None, "layer %d: dense before flatten" % i
+            if l.get('Units', 0) < 0:
+                return None, "layer %d: negative Units" % i
+        else:
+            return None, "layer %d: unknown type %s" % (i, t)
+        if l.get('Activation', '') not in ('', 'linear', 'relu', 'softmax'):
+            return None, "layer %d: unknown activation %s" % (i, l.get('Activation'))
+    last = layers_[-1]
+    if last.get('Type') != 'dense' or last.get('Units', 0) != 0 or last.get('Activation') != 'softmax':
+        return None, "the last layer must be a softmax dense layer with 0 Units"
+    if spec.get('Optimizer') not in ('adam', 'sgd', 'rmsprop'):
+        return None, "unknown optimizer %s" % spec.get('Optimizer')
+    if spec.get('LearningRate', 0) <= 0 or spec.get('BatchSize', 0) <= 0 or spec.get('Epochs', 0) <= 0:
+        return None, "LearningRate, BatchSize and Epochs must be positive"
+    if not 0 <= spec.get('ValidationSplit', 0) < 1:
+        return None, "ValidationSplit is expected in 0..1"
+    es = spec.get('EarlyStopping') or {}
+    if es.get('Patience', 0) < 0:
+        return None, "negative EarlyStopping Patience"
+    if es.get('Patience', 0) > 0:
+        m = es.get('Monitor')
+        if m in ('val_loss', 'val_accuracy'):
+            if spec.get('ValidationSplit', 0) == 0:
+                return None, "EarlyStopping monitors %s without ValidationSplit" % m
+        elif m not in ('loss', 'accuracy'):
+            return None, "unknown EarlyStopping Monitor %s" % m
+    return spec, None
+
+class WeightsMismatch(Exception):
+    pass
 
 def byteToFloat(x):
     return x / 255
 
+def errorMsg(code, message=""):
+    return mlserver_pb2.MsgError(Err=code, Message=message)
+
+def noSession(id):
+    return "session %d not found" % id
+
+# Keras reports an exhausted GPU as tensorflow's ResourceExhaustedError, the
+# name is checked so tensorflow does not have to be imported here.
+def exceptionCode(e):
+    if isinstance(e, MemoryError) or type(e).__name__ == 'ResourceExhaustedError':
+        return mlserver_pb2.ENUMERROR_OUTOFMEMORY
+    return mlserver_pb2.ENUMERROR_INTERNAL
+
+# Weights for the Go backend: b'SCRW', uint32 count, then per tensor
+# uint32 ndim, ndim x uint32 shape and float32 data, all little endian.
+def exportWeights(w, fileName):
+    with open(fileName, 'wb') as f:
+        f.write(b'SCRW')
+        f.write(struct.pack('<I', len(w)))
+        for a in w:
+            a = np.asarray(a, dtype='<f4')
+            f.write(struct.pack('<I', a.ndim))
+            f.write(struct.pack('<%dI' % a.ndim, *a.shape))
+            f.write(a.tobytes())
+
+# Every training run is kept in models/<version> as weights.npy, weights.bin,
+# meta.json and spec.json, models/active.txt names the one used for prediction.
+class Registry:
+    def __init__(self):
+        self.lock = threading.Lock()
+
+    def path(self, version, name):
+        return os.path.join(models_dir, version, name)
+
+    def active(self):
+        try:
+            with open(active_model_file_name) as f:
+                return f.read().strip()
+        except OSError:
+            return None
+
+    def weightsFile(self):
+        v = self.active()
+        if v is not None and os.path.exists(self.path(v, 'weights.npy')):
+            return self.path(v, 'weights.npy')
+        return weights_file_name+'.npy'
+
+    def meta(self, version):
+        with open(self.path(version, 'meta.json')) as f:
+            return json.load(f)
+
+    # activeShape returns the input shape of the active model or None.
+    def activeShape(self):
+        v = self.active()
+        if v is None or not os.path.exists(self.path(v, 'meta.json')):
+            return None
+        return tuple(self.meta(v)['InputShape'])
+
+    # spec returns the spec a model was trained with, the default one for
+    # models trained before specs.
+    def spec(self, version):
+        if version is None or not os.path.exists(self.path(version, spec_file_name)):
+            return default_spec
+        with open(self.path(version, spec_file_name)) as f:
+            return json.load(f)
+
+    def save(self, w, meta, spec):
+        with self.lock:
+            version = datetime.now().strftime('%Y%m%d%H%M%S%f')
+            os.makedirs(os.path.join(models_dir, version))
+            np.save(self.path(version, 'weights.npy'), w)
+            exportWeights(w, self.path(version, 'weights.bin'))
+            meta['Version'] = version
+            with open(self.path(version, 'meta.json'), 'w') as f:
+                json.dump(meta, f, indent=4)
+            with open(self.path(version, spec_file_name), 'w') as f:
+                json.dump(spec, f, indent=4)
+            return version
+
+    def list(self):
+        res = []
+        if not os.path.isdir(models_dir):
+            return res
+        active = self.active()
+        for v in sorted(os.listdir(models_dir)):
+            if os.path.exists(self.path(v, 'meta.json')):
+                m = self.meta(v)
+                m['Active'] = v == active
+                res.append(m)
+        return res
+
+    def activate(self, version):
+        with self.lock:
+            if not os.path.exists(self.path(version, 'meta.json')):
+                return False
+            with open(active_model_file_name, 'w') as f:
+                f.write(version)
+            shutil.copyfile(self.path(version, 'weights.bin'), go_weights_file_name)
+            return True
+
+    def delete(self, version):
+        with self.lock:
+            if not os.path.exists(self.path(version, 'meta.json')):
+                return mlserver_pb2.ENUMERROR_NOMODEL
+            if version == self.active():
+                return mlserver_pb2.ENUMERROR_MODELACTIVE
+            shutil.rmtree(os.path.join(models_dir, version))
+            return mlserver_pb2.ENUMERROR_NOERROR
+
+registry = Registry()
+
+# Uploaded samples by hash, kept across sessions so the next training only
+# gets the samples that changed.
+class SampleStore:
+    def __init__(self):
+        self.lock = threading.Lock()
+        self.samples = {}
+
+    def missing(self, hashes):
+        with self.lock:
+            return [h for h in hashes if h not in self.samples]
+
+    def put(self, h, x, y):
+        with self.lock:
+            self.samples[h] = (x, y)
+
+    def prune(self, keep):
+        with self.lock:
+            for h in [h for h in self.samples if h not in keep]:
+                del self.samples[h]
+
+    def get(self, hashes):
+        with self.lock:
+            items = [self.samples[h] for h in hashes]
+        x = np.stack([i[0] for i in items])
+        y = np.array([i[1] for i in items])
+        return x, y
+
+samples = SampleStore()
+
+class Session:
+    def __init__(self):
+        self.learnXBuf2 = []
+        self.samplesXBuffer = None
+        self.samplesYBuffer = None
+        self.input_shape = None
+        self.num_classes = 2
+        self.class_names = []
+        self.spec = default_spec
+        self.class_weights = None
+        self.hashes = []
+        self.cancel = False
+
+class ProgressCallback(callbacks.Callback):
+    def __init__(self, ses, q):
+        super().__init__()
+        self.ses = ses
+        self.q = q
+
+    def on_epoch_end(self, epoch, logs=None):
+        self.q.put((epoch, logs or {}))
+        if self.ses.cancel:
+            self.model.stop_training = True
+
 class Messager(mlserver_pb2_grpc.MessagerServicer):
-    learnXBuf2 = None
-    samplesXBuffer = None
-    samplesYBuffer = None
-    model = None
-    num_classes = 2
-    startPopSmpl = False
-    popSamplT = None
-    layers = 3
-
-    def fdp(self, d):
-        stride = 3
-        i = 0
-        sq = []
-        for y in range(self.input_shape[1]):
-            row = []
-            for x in range(self.input_shape[0]):
-                # point = (0.3 * byteToFloat(d[i + 0])) + (0.59 * byteToFloat(d[i + 1])) + (0.11 * byteToFloat(d[i + 2]))
-                point = [byteToFloat(d[i + 0]), byteToFloat(d[i + 1]), byteToFloat(d[i + 2])]
-                row.append(point)
-                i += stride
-            sq.append(row)
-        return sq
+    def __init__(self):
+        self.sessions = {}
+        self.lastSessionId = 0
+        self.sessionsLock = threading.Lock()
+        self.model = None
+        self.input_shape = None
+        self.num_classes = None
+        self.modelLock = threading.Lock()
+
+    def getSession(self, id):
+        with self.sessionsLock:
+            return self.sessions.get(id)
+
+    # The client converts samples to the dataset channel mode, grayscale
+    # samples come with one byte per pixel.
+    def fdp(self, d, input_shape):
+        x = np.frombuffer(d, dtype=np.uint8).reshape(input_shape)
+        return byteToFloat(x.astype('float32'))
+
+    def OpenSession(self, request, context):
+        with self.sessionsLock:
+            self.lastSessionId += 1
+            self.sessions[self.lastSessionId] = Session()
+            print("Open session", self.lastSessionId)
+            return mlserver_pb2.MsgSession(Id=self.lastSessionId)
+
+    def CloseSession(self, request, context):
+        with self.sessionsLock:
+            if self.sessions.pop(request.Id, None) is None:
+                return errorMsg(mlserver_pb2.ENUMERROR_NOSESSION, noSession(request.Id))
+        print("Close session", request.Id)
+        return errorMsg(mlserver_pb2.ENUMERROR_NOERROR)
 
     def AppendTrainingSample(self, request, context):
+        ses = self.getSession(request.Session)
+        if ses is None:
+            return errorMsg(mlserver_pb2.ENUMERROR_NOSESSION, noSession(request.Session))
+        if ses.input_shape is None:
+            return errorMsg(mlserver_pb2.ENUMERROR_NOTINITIALIZED, "InitMlParams was not called")
+        if len(request.XData) != np.prod(ses.input_shape):
+            return errorMsg(mlserver_pb2.ENUMERROR_SHAPEMISMATCH, "sample has %d bytes, %s expected" % (len(request.XData), ses.input_shape))
+
         d = request.XData
-        sq = self.fdp(d)
+        sq = self.fdp(d, ses.input_shape)
 
-        self.learnXBuf2.append(sq)
+        ses.learnXBuf2.append(sq)
 
-        if len(self.learnXBuf2) == 1000:
-            if self.samplesXBuffer is None:
-                self.samplesXBuffer = np.array(self.learnXBuf2)
+        if len(ses.learnXBuf2) == 1000:
+            if ses.samplesXBuffer is None:
+                ses.samplesXBuffer = np.array(ses.learnXBuf2)
             else:
-                self.samplesXBuffer = np.append(self.samplesXBuffer, np.array(self.learnXBuf2), axis=0)
-            self.learnXBuf2 = []
-
-        if self.samplesYBuffer is None:
-            self.samplesYBuffer = np.array([request.YData])
-        else:
-            self.samplesYBuffer = np.append(self.samplesYBuffer, np.array([request.YData]), axis=0)
-
-        return mlserver_pb2.MsgError(Err=mlserver_pb2.ENUMERROR_NOERROR)
-
-    def AppendPredictSample(self, request, context):
-        if self.startPopSmpl == False:
-            self.startPopSmpl = True
-            self.popSamplT = datetime.now()
-        d = request.Data
-        sq = self.fdp(d)
+                ses.samplesXBuffer = np.append(ses.samplesXBuffer, np.array(ses.learnXBuf2), axis=0)
+            ses.learnXBuf2 = []
 
-        if self.samplesXBuffer is None:
-            self.samplesXBuffer = np.array([sq])
+        if ses.samplesYBuffer is None:
+            ses.samplesYBuffer = np.array([request.YData])
         else:
-            self.samplesXBuffer = np.append(self.samplesXBuffer, np.array([sq]), axis=0)
-
-        return mlserver_pb2.MsgError(Err=mlserver_pb2.ENUMERROR_NOERROR)
-
-    def Predict(self, request, context):
-        if self.startPopSmpl == True:
-            self.startPopSmpl = False
-            print("Samples load time:", datetime.now() - self.popSamplT)
-
-        if self.model == None:
-            # return mlserver_pb2.MsgPredOut(Data=b'', Err=mlserver_pb2.ENUMERROR_NOOUTDATA)
-            self.model = self.BuildModel()
-
-        # print(self.samplesXBuffer)
+            ses.samplesYBuffer = np.append(ses.samplesYBuffer, np.array([request.YData]), axis=0)
+
+        return errorMsg(mlserver_pb2.ENUMERROR_NOERROR)
+
+    def SyncSamples(self, request, context):
+        ses = self.getSession(request.Session)
+        if ses is None:
+            return mlserver_pb2.MsgSampleHashes(Err=mlserver_pb2.ENUMERROR_NOSESSION, Message=noSession(request.Session))
+
+        ses.hashes = list(request.Hashes)
+        keep = set()
+        with self.sessionsLock:
+            for s in self.sessions.values():
+                keep.update(s.hashes)
+        samples.prune(keep)
+        missing = samples.missing(ses.hashes)
+        print("Sync samples: %d in dataset, %d missing" % (len(ses.hashes), len(missing)))
+
+        return mlserver_pb2.MsgSampleHashes(Session=request.Session, Hashes=missing, Err=mlserver_pb2.ENUMERROR_NOERROR)
+
+    # Every chunk is stored as it arrives, so a dropped stream keeps what it
+    # delivered and the client resumes with the next SyncSamples.
+    def UploadSamples(self, request_iterator, context):
+        n = 0
+        for chunk in request_iterator:
+            ses = self.getSession(chunk.Session)
+            if ses is None:
+                return mlserver_pb2.MsgUploadAck(Received=n, Err=mlserver_pb2.ENUMERROR_NOSESSION, Message=noSession(chunk.Session))
+            if ses.input_shape is None:
+                return mlserver_pb2.MsgUploadAck(Received=n, Err=mlserver_pb2.ENUMERROR_NOTINITIALIZED, Message="InitMlParams was not called")
+            for s in chunk.Samples:
+                x = np.frombuffer(s.XData, dtype=np.uint8)
+                if x.size != np.prod(ses.input_shape):
+                    return mlserver_pb2.MsgUploadAck(Received=n, Err=mlserver_pb2.ENUMERROR_SHAPEMISMATCH,
+                        Message="sample has %d bytes, %s expected" % (x.size, ses.input_shape))
+                samples.put(s.Hash, x.reshape(ses.input_shape), s.YData)
+                n += 1
+        return mlserver_pb2.MsgUploadAck(Received=n, Err=mlserver_pb2.ENUMERROR_NOERROR)
+
+    # tilesToArray returns None when the tiles are not equal squares.
+    def tilesToArray(self, tiles, channels):
+        ss = int(round(np.sqrt(len(tiles[0]) / channels)))
+        input_shape = (ss, ss, channels)
+        if any(len(t) != ss * ss * channels for t in tiles):
+            return None
+        x = np.frombuffer(b''.join(tiles), dtype=np.uint8)
+        x = x.reshape((len(tiles),) + input_shape)
+        return byteToFloat(x.astype('float32'))
+
+    def PredictFrame(self, request, context):
+        if self.getSession(request.Session) is None:
+            return mlserver_pb2.MsgPredFrameOut(Err=mlserver_pb2.ENUMERROR_NOSESSION, Message=noSession(request.Session))
+
+        if len(request.Tiles) == 0:
+            return mlserver_pb2.MsgPredFrameOut(Err=mlserver_pb2.ENUMERROR_NOOUTDATA, Message="no tiles")
+
+        channels = request.Channels or 3
+        if channels not in sample_channels:
+            return mlserver_pb2.MsgPredFrameOut(Err=mlserver_pb2.ENUMERROR_SHAPEMISMATCH, Message="bad channel count %d" % channels)
+        x = self.tilesToArray(request.Tiles, channels)
+        if x is None:
+            return mlserver_pb2.MsgPredFrameOut(Err=mlserver_pb2.ENUMERROR_SHAPEMISMATCH, Message="tiles are not equal squares of %d channels" % channels)
+
+        model, err, message = self.activeModel(x.shape[1:], max(request.NumClasses, 2))
+        if model is None:
+            return mlserver_pb2.MsgPredFrameOut(Err=err, Message=message)
 
         t0 = datetime.now()
-        y = self.model.predict(self.samplesXBuffer)
+        try:
+            y = model.predict(x, verbose=0)
+        except Exception as e:
+            return mlserver_pb2.MsgPredFrameOut(Err=exceptionCode(e), Message=str(e))
         print("Predict time:", datetime.now() - t0)
 
-        y = np.split(y, 2, 1)
-        y = y[1].reshape(1, len(y[1]))[0]
-        y = np.ceil(y * 255)
-
-        b = y.astype('uint8')
-        b2 = b.tobytes()
+        return mlserver_pb2.MsgPredFrameOut(Probs=y.flatten().tolist(), NumClasses=y.shape[1], Err=mlserver_pb2.ENUMERROR_NOERROR)
+
+    # activeModel returns the active model built for the input shape, or None
+    # with an error code and message.
+    def activeModel(self, input_shape, num_classes):
+        with self.modelLock:
+            if self.model == None or self.input_shape != input_shape or self.num_classes != num_classes:
+                if not os.path.exists(registry.weightsFile()):
+                    return None, mlserver_pb2.ENUMERROR_NOMODEL, "no trained model"
+                shape = registry.activeShape()
+                if shape is not None and shape != input_shape:
+                    return None, mlserver_pb2.ENUMERROR_SHAPEMISMATCH, "the active model takes %s samples, got %s" % (shape, input_shape)
+                try:
+                    w = np.load(file=registry.weightsFile(), allow_pickle=True)
+                    self.model = self.BuildModel(input_shape, num_classes, registry.spec(registry.active()), w)
+                except WeightsMismatch as e:
+                    self.model = None
+                    return None, mlserver_pb2.ENUMERROR_WEIGHTSMISMATCH, str(e)
+                except ValueError as e:
+                    self.model = None
+                    return None, mlserver_pb2.ENUMERROR_SHAPEMISMATCH, str(e)
+                self.input_shape = input_shape
+                self.num_classes = num_classes
+            return self.model, mlserver_pb2.ENUMERROR_NOERROR, ""
+
+    # Evaluate predicts the samples of the session with the active model, the
+    # classes come in the order of the SyncSamples hashes.
+    def Evaluate(self, request, context):
+        ses = self.getSession(request.Id)
+        if ses is None:
+            return mlserver_pb2.MsgEvalResult(Err=mlserver_pb2.ENUMERROR_NOSESSION, Message=noSession(request.Id))
+        if ses.input_shape is None:
+            return mlserver_pb2.MsgEvalResult(Err=mlserver_pb2.ENUMERROR_NOTINITIALIZED, Message="InitMlParams was not called")
+        if len(ses.hashes) == 0:
+            return mlserver_pb2.MsgEvalResult(Err=mlserver_pb2.ENUMERROR_NOOUTDATA, Message="no samples to evaluate")
+        try:
+            x, _ = samples.get(ses.hashes)
+        except KeyError:
+            return mlserver_pb2.MsgEvalResult(Err=mlserver_pb2.ENUMERROR_BADSAMPLE, Message="samples are missing, sync them again")
+        except ValueError:
+            return mlserver_pb2.MsgEvalResult(Err=mlserver_pb2.ENUMERROR_SHAPEMISMATCH, Message="samples of different shapes in one evaluation")
+
+        model, err, message = self.activeModel(x.shape[1:], ses.num_classes)
+        if model is None:
+            return mlserver_pb2.MsgEvalResult(Err=err, Message=message)
 
-        # print(b)
+        t0 = datetime.now()
+        try:
+            y = model.predict(byteToFloat(x.astype('float32')), batch_size=100, verbose=0)
+        except Exception as e:
+            return mlserver_pb2.MsgEvalResult(Err=exceptionCode(e), Message=str(e))
+        print("Evaluate %d samples, time:" % x.shape[0], datetime.now() - t0)
 
-        return mlserver_pb2.MsgPredOut(Data=b2, Err=mlserver_pb2.ENUMERROR_NOERROR)
+        return mlserver_pb2.MsgEvalResult(Predicted=np.argmax(y, axis=1).tolist(), Err=mlserver_pb2.ENUMERROR_NOERROR)
 
     def Test(self, request, context):
+        return errorMsg(mlserver_pb2.ENUMERROR_NOERROR)
+
+    def Shutdown(self, request, context):
+        shutdown_event.set()
         return mlserver_pb2.VoidMsg()
 
     def InitMlParams(self, request, context):
-        self.samplesXBuffer = None
-        self.samplesYBuffer = None
-        self.learnXBuf2 = []
+        ses = self.getSession(request.Session)
+        if ses is None:
+            return errorMsg(mlserver_pb2.ENUMERROR_NOSESSION, noSession(request.Session))
+        if request.SampleSize <= 0:
+            return errorMsg(mlserver_pb2.ENUMERROR_SHAPEMISMATCH, "bad sample size %d" % request.SampleSize)
+        channels = request.Channels or 3
+        if channels not in sample_channels:
+            return errorMsg(mlserver_pb2.ENUMERROR_SHAPEMISMATCH, "bad channel count %d" % channels)
+
+        ses.samplesXBuffer = None
+        ses.samplesYBuffer = None
+        ses.learnXBuf2 = []
 
         ss = request.SampleSize
-        self.input_shape = (ss, ss, self.layers)
-
-        return mlserver_pb2.VoidMsg()
+        spec = default_spec
+        if request.ModelSpec != "":
+            spec, message = parseSpec(request.ModelSpec, ss)
+            if spec is None:
+                return errorMsg(mlserver_pb2.ENUMERROR_BADMODELSPEC, message)
+        ses.spec = spec
+        ses.input_shape = (ss, ss, channels)
+        ses.num_classes = max(request.NumClasses, 2)
+        ses.class_names = list(request.ClassNames)
+        ses.class_weights = {i: w for i, w in enumerate(request.ClassWeights)} or None
+        # Reset here, a CancelTrain sent during the upload holds for Train.
+        ses.cancel = False
+
+        return errorMsg(mlserver_pb2.ENUMERROR_NOERROR)
 
     def Train(self, request, context):
-
-        if len(self.learnXBuf2) > 0:
-            if self.samplesXBuffer is None:
-                self.samplesXBuffer = np.array([self.learnXBuf2])
-            else:
-                self.samplesXBuffer = np.append(self.samplesXBuffer, np.array(self.learnXBuf2), axis=0)
-            self.learnXBuf2 = []
-
-        if self.samplesXBuffer.shape[0] == 1:
-            self.samplesXBuffer = self.samplesXBuffer[0]
-
-        print(self.samplesXBuffer.shape)
-
-        x_train = self.samplesXBuffer
-        y_train = self.samplesYBuffer
-
-        x_train = np.expand_dims(x_train, -1)
-        y_train = utils.to_categorical(y_train, self.num_classes)
-
-        self.model = self.BuildModel(l=False)
-
-        callback = callbacks.EarlyStopping(monitor='loss', patience=5, min_delta=0.01)
-        self.model.fit(x_train, y_train, batch_size=100, epochs=100, validation_split=0.1, callbacks=[callback])
+        ses = self.getSession(request.Id)
+        if ses is None:
+            yield mlserver_pb2.MsgTrainProgress(Err=mlserver_pb2.ENUMERROR_NOSESSION, Message=noSession(request.Id))
+            return
+        if ses.input_shape is None:
+            yield mlserver_pb2.MsgTrainProgress(Err=mlserver_pb2.ENUMERROR_NOTINITIALIZED, Message="InitMlParams was not called")
+            return
+
+        if len(ses.hashes) > 0:
+            try:
+                x_train, y_train = samples.get(ses.hashes)
+            except KeyError:
+                yield mlserver_pb2.MsgTrainProgress(Err=mlserver_pb2.ENUMERROR_BADSAMPLE, Message="samples are missing, sync them again")
+                return
+            except ValueError:
+                yield mlserver_pb2.MsgTrainProgress(Err=mlserver_pb2.ENUMERROR_SHAPEMISMATCH, Message="samples of different shapes in one training")
+                return
+            if x_train.shape[1:] != ses.input_shape:
+                yield mlserver_pb2.MsgTrainProgress(Err=mlserver_pb2.ENUMERROR_SHAPEMISMATCH,
+                    Message="samples are %s, the session takes %s" % (x_train.shape[1:], ses.input_shape))
+                return
+            x_train = byteToFloat(x_train.astype('float32'))
+        elif ses.samplesYBuffer is None:
+            yield mlserver_pb2.MsgTrainProgress(Err=mlserver_pb2.ENUMERROR_NOOUTDATA, Message="no training samples")
+            return
+        else:
+            if len(ses.learnXBuf2) > 0:
+                if ses.samplesXBuffer is None:
+                    ses.samplesXBuffer = np.array([ses.learnXBuf2])
+                else:
+                    ses.samplesXBuffer = np.append(ses.samplesXBuffer, np.array(ses.learnXBuf2), axis=0)
+                ses.learnXBuf2 = []
+
+            if ses.samplesXBuffer.shape[0] == 1:
+                ses.samplesXBuffer = ses.samplesXBuffer[0]
+
+            x_train = ses.samplesXBuffer
+            y_train = ses.samplesYBuffer
+
+        print(x_train.shape)
+
+        y_train = utils.to_categorical(y_train, ses.num_classes)
+
+        spec = ses.spec
+        try:
+            model = self.BuildModel(ses.input_shape, ses.num_classes, spec)
+        except ValueError as e:
+            yield mlserver_pb2.MsgTrainProgress(Err=mlserver_pb2.ENUMERROR_BADMODELSPEC, Message=str(e))
+            return
+
+        context.add_callback(lambda: setattr(ses, 'cancel', True))
+
+        epochs = spec['Epochs']
+        q = queue.Queue()
+        result = {}
+        last = {}
+        def fit():
+            try:
+                cbs = [ProgressCallback(ses, q)]
+                es = spec.get('EarlyStopping') or {}
+                if es.get('Patience', 0) > 0:
+                    cbs.append(callbacks.EarlyStopping(monitor=es['Monitor'], patience=es['Patience'], min_delta=es.get('MinDelta', 0)))
+                model.fit(x_train, y_train, batch_size=spec['BatchSize'], epochs=epochs, validation_split=spec.get('ValidationSplit', 0), class_weight=ses.class_weights, callbacks=cbs)
+            except Exception as e:
+                result['error'] = e
+            q.put(None)
+        threading.Thread(target=fit).start()
+
+        while True:
+            item = q.get()
+            if item is None:
+                break
+            epoch, logs = item
+            last = logs
+            yield mlserver_pb2.MsgTrainProgress(
+                Epoch=epoch+1,
+                Epochs=epochs,
+                Loss=logs.get('loss', 0),
+                Accuracy=logs.get('accuracy', 0),
+                ValLoss=logs.get('val_loss', 0),
+                ValAccuracy=logs.get('val_accuracy', 0))
+
+        ses.samplesXBuffer = None
+        ses.samplesYBuffer = None
+
+        if 'error' in result:
+            e = result['error']
+            code = exceptionCode(e)
+            if code == mlserver_pb2.ENUMERROR_INTERNAL:
+                code = mlserver_pb2.ENUMERROR_TRAININGFAILED
+            yield mlserver_pb2.MsgTrainProgress(Err=code, Message=str(e))
+            return
+
+        if ses.cancel:
+            print("Training cancelled, weights are not saved")
+            return
 
         print("Save weights")
-        w = self.model.get_weights()
-        np.save(weights_file_name, w)
-        print("OK")
-
-        self.samplesXBuffer = np.array([])
-        self.samplesYBuffer = np.array([])
-
-        return mlserver_pb2.VoidMsg()
+        w = model.get_weights()
+        version = registry.save(w, {
+            'Created': datetime.now().isoformat(timespec='seconds'),
+            'Samples': int(y_train.shape[0]),
+            'InputShape': list(ses.input_shape),
+            'NumClasses': ses.num_classes,
+            'ClassNames': ses.class_names,
+            'Loss': float(last.get('loss', 0)),
+            'Accuracy': float(last.get('accuracy', 0)),
+            'ValLoss': float(last.get('val_loss', 0)),
+            'ValAccuracy': float(last.get('val_accuracy', 0)),
+        }, spec)
+        registry.activate(version)
+        print("OK, model", version)
+
+        with self.modelLock:
+            self.model = model
+            self.input_shape = ses.input_shape
+            self.num_classes = ses.num_classes
+
+    def CancelTrain(self, request, context):
+        ses = self.getSession(request.Id)
+        if ses is None:
+            return mlserver_pb2.MsgError(Err=mlserver_pb2.ENUMERROR_NOSESSION)
+        ses.cancel = True
+        print("Cancel training in session", request.Id)
+        return mlserver_pb2.MsgError(Err=mlserver_pb2.ENUMERROR_NOERROR)
 
-    def BuildModel(self, l = True):
+    def ListModels(self, request, context):
+        res = mlserver_pb2.MsgModelList()
+        for m in registry.list():
+            res.Models.append(mlserver_pb2.MsgModelInfo(
+                Version=m['Version'],
+                Created=m['Created'],
+                Samples=m['Samples'],
+                ClassNames=m['ClassNames'],
+                Loss=m['Loss'],
+                Accuracy=m['Accuracy'],
+                ValLoss=m['ValLoss'],
+                ValAccuracy=m['ValAccuracy'],
+                Active=m['Active']))
+        return res
+
+    def ActivateModel(self, request, context):
+        if not registry.activate(request.Version):
+            return errorMsg(mlserver_pb2.ENUMERROR_NOMODEL, "model %s not found" % request.Version)
+        meta = registry.meta(request.Version)
+        input_shape = tuple(meta['InputShape'])
+        try:
+            w = np.load(file=registry.weightsFile(), allow_pickle=True)
+            model = self.BuildModel(input_shape, meta['NumClasses'], registry.spec(request.Version), w)
+        except (WeightsMismatch, ValueError) as e:
+            with self.modelLock:
+                self.model = None
+            return errorMsg(mlserver_pb2.ENUMERROR_WEIGHTSMISMATCH, "model %s: %s" % (request.Version, e))
+        with self.modelLock:
+            self.model = model
+            self.input_shape = input_shape
+            self.num_classes = meta['NumClasses']
+        print("Activate model", request.Version)
+        return errorMsg(mlserver_pb2.ENUMERROR_NOERROR)
+
+    def DeleteModel(self, request, context):
+        err = registry.delete(request.Version)
+        messages = {
+            mlserver_pb2.ENUMERROR_NOMODEL: "model %s not found" % request.Version,
+            mlserver_pb2.ENUMERROR_MODELACTIVE: "model %s is active" % request.Version,
+        }
+        if err == mlserver_pb2.ENUMERROR_NOERROR:
+            print("Delete model", request.Version)
+        return errorMsg(err, messages.get(err, ""))
+
+    # ExportModel writes a model, the active one without Version, as
+    # <Folder>/<version>.onnx and the sidecar <version>.json the Go client
+    # checks against its dataset and labels before using the model.
+    def ExportModel(self, request, context):
+        version = request.Version or registry.active()
+        if version is None or not os.path.exists(registry.path(version, 'meta.json')):
+            return mlserver_pb2.MsgExportOut(Err=mlserver_pb2.ENUMERROR_NOMODEL, Message="model %s not found" % (version or "(active)"))
+        meta = registry.meta(version)
+        input_shape = tuple(meta['InputShape'])
+        try:
+            w = np.load(file=registry.path(version, 'weights.npy'), allow_pickle=True)
+            model = self.BuildModel(input_shape, meta['NumClasses'], registry.spec(version), w)
+        except (WeightsMismatch, ValueError) as e:
+            return mlserver_pb2.MsgExportOut(Err=mlserver_pb2.ENUMERROR_WEIGHTSMISMATCH, Message="model %s: %s" % (version, e))
+
+        folder = request.Folder or default_export_dir
+        model_file = os.path.join(folder, version + '.onnx')
+        sidecar_file = os.path.join(folder, version + '.json')
+        try:
+            import tensorflow as tf
+            import tf2onnx
+        except ImportError as e:
+            return mlserver_pb2.MsgExportOut(Err=mlserver_pb2.ENUMERROR_EXPORTFAILED, Message="ONNX export needs tf2onnx: %s" % e)
+        try:
+            os.makedirs(folder, exist_ok=True)
+            signature = (tf.TensorSpec((None,) + input_shape, tf.float32, name='input'),)
+            tf2onnx.convert.from_keras(model, input_signature=signature, output_path=model_file)
+            with open(sidecar_file, 'w') as f:
+                json.dump({
+                    'Version': version,
+                    'Created': meta['Created'],
+                    'SampleSize': input_shape[0],
+                    'Channels': channel_modes[input_shape[2]],
+                    'ClassNames': meta['ClassNames'],
+                    'Input': 'float32 NHWC, 0..1',
+                }, f, indent=4)
+        except Exception as e:
+            code = exceptionCode(e)
+            if code == mlserver_pb2.ENUMERROR_INTERNAL:
+                code = mlserver_pb2.ENUMERROR_EXPORTFAILED
+            return mlserver_pb2.MsgExportOut(Err=code, Message=str(e))
+        print("Export model", version, "to", model_file)
+        return mlserver_pb2.MsgExportOut(ModelFile=model_file, SidecarFile=sidecar_file, Err=mlserver_pb2.ENUMERROR_NOERROR)
+
+    # BuildModel builds the network of spec, weights are checked tensor by
+    # tensor so a model never predicts with weights of another network.
+    def BuildModel(self, input_shape, num_classes, spec, weights=None):
         model = models.Sequential()
-
-        #
-        model.add(layers.Input(shape=self.input_shape)) # 64x64x3
-        model.add(layers.Conv2D(32, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.Conv2D(32, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.Conv2D(16, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.Conv2D(16, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.MaxPool2D()) # 32x32x3
-        model.add(layers.Conv2D(64, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.Conv2D(64, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.Conv2D(64, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.MaxPool2D()) # 16x16x3
-        model.add(layers.Conv2D(128, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.MaxPool2D()) # 8x8x3
-        model.add(layers.Conv2D(256, kernel_size=(3, 3), padding="same", activation="relu"))
-        model.add(layers.MaxPool2D()) # 4x4x3
-        model.add(layers.Conv2D(512, kernel_size=(3, 3), padding="same", activation="relu"))
-
-        model.add(layers.Flatten())
-        model.add(layers.Dropout(0.5))
-        model.add(layers.Dense(self.num_classes, activation="softmax"))
+        model.add(layers.Input(shape=input_shape))
+        for l in spec['Layers']:
+            t = l['Type']
+            activation = l.get('Activation') or None
+            if t == 'conv':
+                k = l['Kernel']
+                model.add(layers.Conv2D(l['Filters'], kernel_size=(k, k), padding="same", activation=activation))
+            elif t == 'maxpool':
+                model.add(layers.MaxPool2D())
+            elif t == 'flatten':
+                model.add(layers.Flatten())
+            elif t == 'dropout':
+                model.add(layers.Dropout(l['Rate']))
+            elif t == 'dense':
+                model.add(layers.Dense(l.get('Units') or num_classes, activation=activation))
+            else:
+                raise ValueError("unknown layer type %s" % t)
 
         model.summary()
 
-        model.compile(loss="categorical_crossentropy", optimizer="adam", metrics=["accuracy"])
+        optimizers = {'adam': 'Adam', 'sgd': 'SGD', 'rmsprop': 'RMSprop'}
+        optimizer = getattr(keras_optimizers, optimizers[spec['Optimizer']])(learning_rate=spec['LearningRate'])
+        model.compile(loss="categorical_crossentropy", optimizer=optimizer, metrics=["accuracy"])
 
-        if l == True:
-            we = os.path.exists(weights_file_name+'.npy')
-            if we:
-                w = np.load(file=weights_file_name+'.npy', allow_pickle=True)
-                try:
-                    model.set_weights(w)
-                except ValueError:
-                    pass # ignore it
+        if weights is not None:
+            have = model.get_weights()
+            if len(have) != len(weights):
+                raise WeightsMismatch("the weights have %d tensors, the model %d" % (len(weights), len(have)))
+            for i, (a, b) in enumerate(zip(have, weights)):
+                if a.shape != np.shape(b):
+                    raise WeightsMismatch("tensor %d is %s, the model takes %s" % (i, np.shape(b), a.shape))
+            model.set_weights(weights)
 
         return model
 
-def serve():
-    server = grpc.server(futures.ThreadPoolExecutor(max_workers=1))
+def serve(port):
+    server = grpc.server(futures.ThreadPoolExecutor(max_workers=4))
     mlserver_pb2_grpc.add_MessagerServicer_to_server(Messager(), server)
-    server.add_insecure_port('[::]:50555')
+    server.add_insecure_port('[::]:%d' % port)
     server.start()
-    server.wait_for_termination()
+    print("READY on port", port)
+    shutdown_event.wait()
+    print("Shutdown")
+    server.stop(grace=5).wait()
 
 
 if __name__ == '__main__':
+    if len(sys.argv) > 1 and sys.argv[1] == 'export':
+        w = np.load(file=registry.weightsFile(), allow_pickle=True)
+        exportWeights(w, go_weights_file_name)
+        print("Exported", go_weights_file_name)
+        sys.exit(0)
+    port = default_port
+    if '--port' in sys.argv:
+        port = int(sys.argv[sys.argv.index('--port')+1])
     logging.basicConfig()
-    print("READY")
-    serve()
+    serve(port)
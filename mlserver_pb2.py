@@ -15,19 +15,43 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0emlserver.proto\"\x16\n\x07Message\x12\x0b\n\x03Msg\x18\x01 \x01(\t\")\n\tMsgSample\x12\r\n\x05XData\x18\x01 \x01(\x0c\x12\r\n\x05YData\x18\x02 \x01(\x03\"\x19\n\tMsgPredIn\x12\x0c\n\x04\x44\x61ta\x18\x01 \x01(\x0c\"3\n\nMsgPredOut\x12\x0c\n\x04\x44\x61ta\x18\x01 \x01(\x0c\x12\x17\n\x03\x45rr\x18\x02 \x01(\x0e\x32\n.EnumError\"\x1d\n\x07MsgInit\x12\x12\n\nSampleSize\x18\x01 \x01(\x03\"#\n\x08MsgError\x12\x17\n\x03\x45rr\x18\x01 \x01(\x0e\x32\n.EnumError\"\t\n\x07VoidMsg*;\n\tEnumError\x12\x15\n\x11\x45NUMERROR_NOERROR\x10\x00\x12\x17\n\x13\x45NUMERROR_NOOUTDATA\x10\x01\x32\xf3\x01\n\x08Messager\x12\x1c\n\x04Test\x12\x08.VoidMsg\x1a\x08.VoidMsg\"\x00\x12/\n\x14\x41ppendTrainingSample\x12\n.MsgSample\x1a\t.MsgError\"\x00\x12%\n\x0cInitMlParams\x12\x08.MsgInit\x1a\t.MsgError\"\x00\x12\x1d\n\x05Train\x12\x08.VoidMsg\x1a\x08.VoidMsg\"\x00\x12.\n\x13\x41ppendPredictSample\x12\n.MsgPredIn\x1a\t.MsgError\"\x00\x12\"\n\x07Predict\x12\x08.VoidMsg\x1a\x0b.MsgPredOut\"\x00\x42\nZ\x08./protosb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0emlserver.proto\"\x16\n\x07Message\x12\x0b\n\x03Msg\x18\x01 \x01(\t\"\x18\n\nMsgSession\x12\n\n\x02Id\x18\x01 \x01(\x03\":\n\tMsgSample\x12\r\n\x05XData\x18\x01 \x01(\x0c\x12\r\n\x05YData\x18\x02 \x01(\x03\x12\x0f\n\x07Session\x18\x03 \x01(\x03\"\\\n\x0fMsgSampleHashes\x12\x0f\n\x07Session\x18\x01 \x01(\x03\x12\x0e\n\x06Hashes\x18\x02 \x03(\x0c\x12\x17\n\x03\x45rr\x18\x03 \x01(\x0e\x32\n.EnumError\x12\x0f\n\x07Message\x18\x04 \x01(\t\";\n\rMsgSampleData\x12\x0c\n\x04Hash\x18\x01 \x01(\x0c\x12\r\n\x05XData\x18\x02 \x01(\x0c\x12\r\n\x05YData\x18\x03 \x01(\x03\"B\n\x0eMsgSampleChunk\x12\x0f\n\x07Session\x18\x01 \x01(\x03\x12\x1f\n\x07Samples\x18\x02 \x03(\x0b\x32\x0e.MsgSampleData\"J\n\x0cMsgUploadAck\x12\x10\n\x08Received\x18\x01 \x01(\x03\x12\x17\n\x03\x45rr\x18\x02 \x01(\x0e\x32\n.EnumError\x12\x0f\n\x07Message\x18\x03 \x01(\t\"\xa1\x01\n\x10MsgTrainProgress\x12\r\n\x05\x45poch\x18\x01 \x01(\x03\x12\x0e\n\x06\x45pochs\x18\x02 \x01(\x03\x12\x0c\n\x04Loss\x18\x03 \x01(\x02\x12\x10\n\x08\x41\x63\x63uracy\x18\x04 \x01(\x02\x12\x0f\n\x07ValLoss\x18\x05 \x01(\x02\x12\x13\n\x0bValAccuracy\x18\x06 \x01(\x02\x12\x17\n\x03\x45rr\x18\x07 \x01(\x0e\x32\n.EnumError\x12\x0f\n\x07Message\x18\x08 \x01(\t\"V\n\x0eMsgPredFrameIn\x12\r\n\x05Tiles\x18\x01 \x03(\x0c\x12\x0f\n\x07Session\x18\x02 \x01(\x03\x12\x12\n\nNumClasses\x18\x03 \x01(\x03\x12\x10\n\x08\x43hannels\x18\x04 \x01(\x03\"^\n\x0fMsgPredFrameOut\x12\r\n\x05Probs\x18\x01 \x03(\x02\x12\x17\n\x03\x45rr\x18\x02 \x01(\x0e\x32\n.EnumError\x12\x12\n\nNumClasses\x18\x03 \x01(\x03\x12\x0f\n\x07Message\x18\x04 \x01(\t\"\x91\x01\n\x07MsgInit\x12\x12\n\nSampleSize\x18\x01 \x01(\x03\x12\x0f\n\x07Session\x18\x02 \x01(\x03\x12\x12\n\nNumClasses\x18\x03 \x01(\x03\x12\x12\n\nClassNames\x18\x04 \x03(\t\x12\x10\n\x08\x43hannels\x18\x05 \x01(\x03\x12\x11\n\tModelSpec\x18\x06 \x01(\t\x12\x14\n\x0c\x43lassWeights\x18\x07 \x03(\x02\"\xab\x01\n\x0cMsgModelInfo\x12\x0f\n\x07Version\x18\x01 \x01(\t\x12\x0f\n\x07\x43reated\x18\x02 \x01(\t\x12\x0f\n\x07Samples\x18\x03 \x01(\x03\x12\x12\n\nClassNames\x18\x04 \x03(\t\x12\x0c\n\x04Loss\x18\x05 \x01(\x02\x12\x10\n\x08\x41\x63\x63uracy\x18\x06 \x01(\x02\x12\x0f\n\x07ValLoss\x18\x07 \x01(\x02\x12\x13\n\x0bValAccuracy\x18\x08 \x01(\x02\x12\x0e\n\x06\x41\x63tive\x18\t \x01(\x08\"-\n\x0cMsgModelList\x12\x1d\n\x06Models\x18\x01 \x03(\x0b\x32\r.MsgModelInfo\"\x1d\n\nMsgModelId\x12\x0f\n\x07Version\x18\x01 \x01(\t\".\n\x0bMsgExportIn\x12\x0f\n\x07Version\x18\x01 \x01(\t\x12\x0e\n\x06\x46older\x18\x02 \x01(\t\"`\n\x0cMsgExportOut\x12\x11\n\tModelFile\x18\x01 \x01(\t\x12\x13\n\x0bSidecarFile\x18\x02 \x01(\t\x12\x17\n\x03\x45rr\x18\x03 \x01(\x0e\x32\n.EnumError\x12\x0f\n\x07Message\x18\x04 \x01(\t\"L\n\rMsgEvalResult\x12\x11\n\tPredicted\x18\x01 \x03(\x03\x12\x17\n\x03\x45rr\x18\x02 \x01(\x0e\x32\n.EnumError\x12\x0f\n\x07Message\x18\x03 \x01(\t\"4\n\x08MsgError\x12\x17\n\x03\x45rr\x18\x01 \x01(\x0e\x32\n.EnumError\x12\x0f\n\x07Message\x18\x02 \x01(\t\"\t\n\x07VoidMsg*\x82\x03\n\tEnumError\x12\x15\n\x11\x45NUMERROR_NOERROR\x10\x00\x12\x17\n\x13\x45NUMERROR_NOOUTDATA\x10\x01\x12\x17\n\x13\x45NUMERROR_NOSESSION\x10\x02\x12\x15\n\x11\x45NUMERROR_NOMODEL\x10\x03\x12\x19\n\x15\x45NUMERROR_MODELACTIVE\x10\x04\x12\x17\n\x13\x45NUMERROR_BADSAMPLE\x10\x05\x12\x1b\n\x17\x45NUMERROR_SHAPEMISMATCH\x10\x06\x12\x1c\n\x18\x45NUMERROR_NOTINITIALIZED\x10\x07\x12\x1c\n\x18\x45NUMERROR_TRAININGFAILED\x10\x08\x12\x19\n\x15\x45NUMERROR_OUTOFMEMORY\x10\t\x12\x16\n\x12\x45NUMERROR_INTERNAL\x10\n\x12\x1a\n\x16\x45NUMERROR_BADMODELSPEC\x10\x0b\x12\x1d\n\x19\x45NUMERROR_WEIGHTSMISMATCH\x10\x0c\x12\x1a\n\x16\x45NUMERROR_EXPORTFAILED\x10\r2\xc0\x05\n\x08Messager\x12\x1d\n\x04Test\x12\x08.VoidMsg\x1a\t.MsgError\"\x00\x12 \n\x08Shutdown\x12\x08.VoidMsg\x1a\x08.VoidMsg\"\x00\x12&\n\x0bOpenSession\x12\x08.VoidMsg\x1a\x0b.MsgSession\"\x00\x12(\n\x0c\x43loseSession\x12\x0b.MsgSession\x1a\t.MsgError\"\x00\x12/\n\x14\x41ppendTrainingSample\x12\n.MsgSample\x1a\t.MsgError\"\x00\x12\x33\n\x0bSyncSamples\x12\x10.MsgSampleHashes\x1a\x10.MsgSampleHashes\"\x00\x12\x33\n\rUploadSamples\x12\x0f.MsgSampleChunk\x1a\r.MsgUploadAck\"\x00(\x01\x12%\n\x0cInitMlParams\x12\x08.MsgInit\x1a\t.MsgError\"\x00\x12+\n\x05Train\x12\x0b.MsgSession\x1a\x11.MsgTrainProgress\"\x00\x30\x01\x12\'\n\x0b\x43\x61ncelTrain\x12\x0b.MsgSession\x1a\t.MsgError\"\x00\x12\x33\n\x0cPredictFrame\x12\x0f.MsgPredFrameIn\x1a\x10.MsgPredFrameOut\"\x00\x12)\n\x08\x45valuate\x12\x0b.MsgSession\x1a\x0e.MsgEvalResult\"\x00\x12\'\n\nListModels\x12\x08.VoidMsg\x1a\r.MsgModelList\"\x00\x12)\n\rActivateModel\x12\x0b.MsgModelId\x1a\t.MsgError\"\x00\x12\'\n\x0b\x44\x65leteModel\x12\x0b.MsgModelId\x1a\t.MsgError\"\x00\x12,\n\x0b\x45xportModel\x12\x0c.MsgExportIn\x1a\r.MsgExportOut\"\x00\x42\nZ\x08./protosb\x06proto3')
 
 _ENUMERROR = DESCRIPTOR.enum_types_by_name['EnumError']
 EnumError = enum_type_wrapper.EnumTypeWrapper(_ENUMERROR)
 ENUMERROR_NOERROR = 0
 ENUMERROR_NOOUTDATA = 1
+ENUMERROR_NOSESSION = 2
+ENUMERROR_NOMODEL = 3
+ENUMERROR_MODELACTIVE = 4
+ENUMERROR_BADSAMPLE = 5
+ENUMERROR_SHAPEMISMATCH = 6
+ENUMERROR_NOTINITIALIZED = 7
+ENUMERROR_TRAININGFAILED = 8
+ENUMERROR_OUTOFMEMORY = 9
+ENUMERROR_INTERNAL = 10
+ENUMERROR_BADMODELSPEC = 11
+ENUMERROR_WEIGHTSMISMATCH = 12
+ENUMERROR_EXPORTFAILED = 13
 
 
 _MESSAGE = DESCRIPTOR.message_types_by_name['Message']
+_MSGSESSION = DESCRIPTOR.message_types_by_name['MsgSession']
 _MSGSAMPLE = DESCRIPTOR.message_types_by_name['MsgSample']
-_MSGPREDIN = DESCRIPTOR.message_types_by_name['MsgPredIn']
-_MSGPREDOUT = DESCRIPTOR.message_types_by_name['MsgPredOut']
+_MSGSAMPLEHASHES = DESCRIPTOR.message_types_by_name['MsgSampleHashes']
+_MSGSAMPLEDATA = DESCRIPTOR.message_types_by_name['MsgSampleData']
+_MSGSAMPLECHUNK = DESCRIPTOR.message_types_by_name['MsgSampleChunk']
+_MSGUPLOADACK = DESCRIPTOR.message_types_by_name['MsgUploadAck']
+_MSGTRAINPROGRESS = DESCRIPTOR.message_types_by_name['MsgTrainProgress']
+_MSGPREDFRAMEIN = DESCRIPTOR.message_types_by_name['MsgPredFrameIn']
+_MSGPREDFRAMEOUT = DESCRIPTOR.message_types_by_name['MsgPredFrameOut']
 _MSGINIT = DESCRIPTOR.message_types_by_name['MsgInit']
+_MSGMODELINFO = DESCRIPTOR.message_types_by_name['MsgModelInfo']
+_MSGMODELLIST = DESCRIPTOR.message_types_by_name['MsgModelList']
+_MSGMODELID = DESCRIPTOR.message_types_by_name['MsgModelId']
+_MSGEXPORTIN = DESCRIPTOR.message_types_by_name['MsgExportIn']
+_MSGEXPORTOUT = DESCRIPTOR.message_types_by_name['MsgExportOut']
+_MSGEVALRESULT = DESCRIPTOR.message_types_by_name['MsgEvalResult']
 _MSGERROR = DESCRIPTOR.message_types_by_name['MsgError']
 _VOIDMSG = DESCRIPTOR.message_types_by_name['VoidMsg']
 Message = _reflection.GeneratedProtocolMessageType('Message', (_message.Message,), {
@@ -37,6 +61,13 @@ Message = _reflection.GeneratedProtocolMessageType('Message', (_message.Message,
   })
 _sym_db.RegisterMessage(Message)
 
+MsgSession = _reflection.GeneratedProtocolMessageType('MsgSession', (_message.Message,), {
+  'DESCRIPTOR' : _MSGSESSION,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgSession)
+  })
+_sym_db.RegisterMessage(MsgSession)
+
 MsgSample = _reflection.GeneratedProtocolMessageType('MsgSample', (_message.Message,), {
   'DESCRIPTOR' : _MSGSAMPLE,
   '__module__' : 'mlserver_pb2'
@@ -44,19 +75,54 @@ MsgSample = _reflection.GeneratedProtocolMessageType('MsgSample', (_message.Mess
   })
 _sym_db.RegisterMessage(MsgSample)
 
-MsgPredIn = _reflection.GeneratedProtocolMessageType('MsgPredIn', (_message.Message,), {
-  'DESCRIPTOR' : _MSGPREDIN,
+MsgSampleHashes = _reflection.GeneratedProtocolMessageType('MsgSampleHashes', (_message.Message,), {
+  'DESCRIPTOR' : _MSGSAMPLEHASHES,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgSampleHashes)
+  })
+_sym_db.RegisterMessage(MsgSampleHashes)
+
+MsgSampleData = _reflection.GeneratedProtocolMessageType('MsgSampleData', (_message.Message,), {
+  'DESCRIPTOR' : _MSGSAMPLEDATA,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgSampleData)
+  })
+_sym_db.RegisterMessage(MsgSampleData)
+
+MsgSampleChunk = _reflection.GeneratedProtocolMessageType('MsgSampleChunk', (_message.Message,), {
+  'DESCRIPTOR' : _MSGSAMPLECHUNK,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgSampleChunk)
+  })
+_sym_db.RegisterMessage(MsgSampleChunk)
+
+MsgUploadAck = _reflection.GeneratedProtocolMessageType('MsgUploadAck', (_message.Message,), {
+  'DESCRIPTOR' : _MSGUPLOADACK,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgUploadAck)
+  })
+_sym_db.RegisterMessage(MsgUploadAck)
+
+MsgTrainProgress = _reflection.GeneratedProtocolMessageType('MsgTrainProgress', (_message.Message,), {
+  'DESCRIPTOR' : _MSGTRAINPROGRESS,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgTrainProgress)
+  })
+_sym_db.RegisterMessage(MsgTrainProgress)
+
+MsgPredFrameIn = _reflection.GeneratedProtocolMessageType('MsgPredFrameIn', (_message.Message,), {
+  'DESCRIPTOR' : _MSGPREDFRAMEIN,
   '__module__' : 'mlserver_pb2'
-  # @@protoc_insertion_point(class_scope:MsgPredIn)
+  # @@protoc_insertion_point(class_scope:MsgPredFrameIn)
   })
-_sym_db.RegisterMessage(MsgPredIn)
+_sym_db.RegisterMessage(MsgPredFrameIn)
 
-MsgPredOut = _reflection.GeneratedProtocolMessageType('MsgPredOut', (_message.Message,), {
-  'DESCRIPTOR' : _MSGPREDOUT,
+MsgPredFrameOut = _reflection.GeneratedProtocolMessageType('MsgPredFrameOut', (_message.Message,), {
+  'DESCRIPTOR' : _MSGPREDFRAMEOUT,
   '__module__' : 'mlserver_pb2'
-  # @@protoc_insertion_point(class_scope:MsgPredOut)
+  # @@protoc_insertion_point(class_scope:MsgPredFrameOut)
   })
-_sym_db.RegisterMessage(MsgPredOut)
+_sym_db.RegisterMessage(MsgPredFrameOut)
 
 MsgInit = _reflection.GeneratedProtocolMessageType('MsgInit', (_message.Message,), {
   'DESCRIPTOR' : _MSGINIT,
@@ -65,6 +131,48 @@ MsgInit = _reflection.GeneratedProtocolMessageType('MsgInit', (_message.Message,
   })
 _sym_db.RegisterMessage(MsgInit)
 
+MsgModelInfo = _reflection.GeneratedProtocolMessageType('MsgModelInfo', (_message.Message,), {
+  'DESCRIPTOR' : _MSGMODELINFO,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgModelInfo)
+  })
+_sym_db.RegisterMessage(MsgModelInfo)
+
+MsgModelList = _reflection.GeneratedProtocolMessageType('MsgModelList', (_message.Message,), {
+  'DESCRIPTOR' : _MSGMODELLIST,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgModelList)
+  })
+_sym_db.RegisterMessage(MsgModelList)
+
+MsgModelId = _reflection.GeneratedProtocolMessageType('MsgModelId', (_message.Message,), {
+  'DESCRIPTOR' : _MSGMODELID,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgModelId)
+  })
+_sym_db.RegisterMessage(MsgModelId)
+
+MsgExportIn = _reflection.GeneratedProtocolMessageType('MsgExportIn', (_message.Message,), {
+  'DESCRIPTOR' : _MSGEXPORTIN,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgExportIn)
+  })
+_sym_db.RegisterMessage(MsgExportIn)
+
+MsgExportOut = _reflection.GeneratedProtocolMessageType('MsgExportOut', (_message.Message,), {
+  'DESCRIPTOR' : _MSGEXPORTOUT,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgExportOut)
+  })
+_sym_db.RegisterMessage(MsgExportOut)
+
+MsgEvalResult = _reflection.GeneratedProtocolMessageType('MsgEvalResult', (_message.Message,), {
+  'DESCRIPTOR' : _MSGEVALRESULT,
+  '__module__' : 'mlserver_pb2'
+  # @@protoc_insertion_point(class_scope:MsgEvalResult)
+  })
+_sym_db.RegisterMessage(MsgEvalResult)
+
 MsgError = _reflection.GeneratedProtocolMessageType('MsgError', (_message.Message,), {
   'DESCRIPTOR' : _MSGERROR,
   '__module__' : 'mlserver_pb2'
@@ -84,22 +192,46 @@ if _descriptor._USE_C_DESCRIPTORS == False:
 
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z\010./protos'
-  _ENUMERROR._serialized_start=244
-  _ENUMERROR._serialized_end=303
+  _ENUMERROR._serialized_start=1465
+  _ENUMERROR._serialized_end=1851
   _MESSAGE._serialized_start=18
   _MESSAGE._serialized_end=40
-  _MSGSAMPLE._serialized_start=42
-  _MSGSAMPLE._serialized_end=83
-  _MSGPREDIN._serialized_start=85
-  _MSGPREDIN._serialized_end=110
-  _MSGPREDOUT._serialized_start=112
-  _MSGPREDOUT._serialized_end=163
-  _MSGINIT._serialized_start=165
-  _MSGINIT._serialized_end=194
-  _MSGERROR._serialized_start=196
-  _MSGERROR._serialized_end=231
-  _VOIDMSG._serialized_start=233
-  _VOIDMSG._serialized_end=242
-  _MESSAGER._serialized_start=306
-  _MESSAGER._serialized_end=549
+  _MSGSESSION._serialized_start=42
+  _MSGSESSION._serialized_end=66
+  _MSGSAMPLE._serialized_start=68
+  _MSGSAMPLE._serialized_end=126
+  _MSGSAMPLEHASHES._serialized_start=128
+  _MSGSAMPLEHASHES._serialized_end=220
+  _MSGSAMPLEDATA._serialized_start=222
+  _MSGSAMPLEDATA._serialized_end=281
+  _MSGSAMPLECHUNK._serialized_start=283
+  _MSGSAMPLECHUNK._serialized_end=349
+  _MSGUPLOADACK._serialized_start=351
+  _MSGUPLOADACK._serialized_end=425
+  _MSGTRAINPROGRESS._serialized_start=428
+  _MSGTRAINPROGRESS._serialized_end=589
+  _MSGPREDFRAMEIN._serialized_start=591
+  _MSGPREDFRAMEIN._serialized_end=677
+  _MSGPREDFRAMEOUT._serialized_start=679
+  _MSGPREDFRAMEOUT._serialized_end=773
+  _MSGINIT._serialized_start=776
+  _MSGINIT._serialized_end=921
+  _MSGMODELINFO._serialized_start=924
+  _MSGMODELINFO._serialized_end=1095
+  _MSGMODELLIST._serialized_start=1097
+  _MSGMODELLIST._serialized_end=1142
+  _MSGMODELID._serialized_start=1144
+  _MSGMODELID._serialized_end=1173
+  _MSGEXPORTIN._serialized_start=1175
+  _MSGEXPORTIN._serialized_end=1221
+  _MSGEXPORTOUT._serialized_start=1223
+  _MSGEXPORTOUT._serialized_end=1319
+  _MSGEVALRESULT._serialized_start=1321
+  _MSGEVALRESULT._serialized_end=1397
+  _MSGERROR._serialized_start=1399
+  _MSGERROR._serialized_end=1451
+  _VOIDMSG._serialized_start=1453
+  _VOIDMSG._serialized_end=1462
+  _MESSAGER._serialized_start=1854
+  _MESSAGER._serialized_end=2558
 # @@protoc_insertion_point(module_scope)
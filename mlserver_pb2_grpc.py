@@ -17,32 +17,82 @@ class MessagerStub(object):
         self.Test = channel.unary_unary(
                 '/Messager/Test',
                 request_serializer=mlserver__pb2.VoidMsg.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgError.FromString,
+                )
+        self.Shutdown = channel.unary_unary(
+                '/Messager/Shutdown',
+                request_serializer=mlserver__pb2.VoidMsg.SerializeToString,
                 response_deserializer=mlserver__pb2.VoidMsg.FromString,
                 )
+        self.OpenSession = channel.unary_unary(
+                '/Messager/OpenSession',
+                request_serializer=mlserver__pb2.VoidMsg.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgSession.FromString,
+                )
+        self.CloseSession = channel.unary_unary(
+                '/Messager/CloseSession',
+                request_serializer=mlserver__pb2.MsgSession.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgError.FromString,
+                )
         self.AppendTrainingSample = channel.unary_unary(
                 '/Messager/AppendTrainingSample',
                 request_serializer=mlserver__pb2.MsgSample.SerializeToString,
                 response_deserializer=mlserver__pb2.MsgError.FromString,
                 )
+        self.SyncSamples = channel.unary_unary(
+                '/Messager/SyncSamples',
+                request_serializer=mlserver__pb2.MsgSampleHashes.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgSampleHashes.FromString,
+                )
+        self.UploadSamples = channel.stream_unary(
+                '/Messager/UploadSamples',
+                request_serializer=mlserver__pb2.MsgSampleChunk.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgUploadAck.FromString,
+                )
         self.InitMlParams = channel.unary_unary(
                 '/Messager/InitMlParams',
                 request_serializer=mlserver__pb2.MsgInit.SerializeToString,
                 response_deserializer=mlserver__pb2.MsgError.FromString,
                 )
-        self.Train = channel.unary_unary(
+        self.Train = channel.unary_stream(
                 '/Messager/Train',
-                request_serializer=mlserver__pb2.VoidMsg.SerializeToString,
-                response_deserializer=mlserver__pb2.VoidMsg.FromString,
+                request_serializer=mlserver__pb2.MsgSession.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgTrainProgress.FromString,
                 )
-        self.AppendPredictSample = channel.unary_unary(
-                '/Messager/AppendPredictSample',
-                request_serializer=mlserver__pb2.MsgPredIn.SerializeToString,
+        self.CancelTrain = channel.unary_unary(
+                '/Messager/CancelTrain',
+                request_serializer=mlserver__pb2.MsgSession.SerializeToString,
                 response_deserializer=mlserver__pb2.MsgError.FromString,
                 )
-        self.Predict = channel.unary_unary(
-                '/Messager/Predict',
+        self.PredictFrame = channel.unary_unary(
+                '/Messager/PredictFrame',
+                request_serializer=mlserver__pb2.MsgPredFrameIn.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgPredFrameOut.FromString,
+                )
+        self.Evaluate = channel.unary_unary(
+                '/Messager/Evaluate',
+                request_serializer=mlserver__pb2.MsgSession.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgEvalResult.FromString,
+                )
+        self.ListModels = channel.unary_unary(
+                '/Messager/ListModels',
                 request_serializer=mlserver__pb2.VoidMsg.SerializeToString,
-                response_deserializer=mlserver__pb2.MsgPredOut.FromString,
+                response_deserializer=mlserver__pb2.MsgModelList.FromString,
+                )
+        self.ActivateModel = channel.unary_unary(
+                '/Messager/ActivateModel',
+                request_serializer=mlserver__pb2.MsgModelId.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgError.FromString,
+                )
+        self.DeleteModel = channel.unary_unary(
+                '/Messager/DeleteModel',
+                request_serializer=mlserver__pb2.MsgModelId.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgError.FromString,
+                )
+        self.ExportModel = channel.unary_unary(
+                '/Messager/ExportModel',
+                request_serializer=mlserver__pb2.MsgExportIn.SerializeToString,
+                response_deserializer=mlserver__pb2.MsgExportOut.FromString,
                 )
 
 
@@ -55,12 +105,42 @@ class MessagerServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Shutdown(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def OpenSession(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CloseSession(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def AppendTrainingSample(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def SyncSamples(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UploadSamples(self, request_iterator, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def InitMlParams(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
@@ -73,13 +153,43 @@ class MessagerServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
-    def AppendPredictSample(self, request, context):
+    def CancelTrain(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def PredictFrame(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Evaluate(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListModels(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ActivateModel(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
-    def Predict(self, request, context):
+    def DeleteModel(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ExportModel(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
@@ -91,32 +201,82 @@ def add_MessagerServicer_to_server(servicer, server):
             'Test': grpc.unary_unary_rpc_method_handler(
                     servicer.Test,
                     request_deserializer=mlserver__pb2.VoidMsg.FromString,
+                    response_serializer=mlserver__pb2.MsgError.SerializeToString,
+            ),
+            'Shutdown': grpc.unary_unary_rpc_method_handler(
+                    servicer.Shutdown,
+                    request_deserializer=mlserver__pb2.VoidMsg.FromString,
                     response_serializer=mlserver__pb2.VoidMsg.SerializeToString,
             ),
+            'OpenSession': grpc.unary_unary_rpc_method_handler(
+                    servicer.OpenSession,
+                    request_deserializer=mlserver__pb2.VoidMsg.FromString,
+                    response_serializer=mlserver__pb2.MsgSession.SerializeToString,
+            ),
+            'CloseSession': grpc.unary_unary_rpc_method_handler(
+                    servicer.CloseSession,
+                    request_deserializer=mlserver__pb2.MsgSession.FromString,
+                    response_serializer=mlserver__pb2.MsgError.SerializeToString,
+            ),
             'AppendTrainingSample': grpc.unary_unary_rpc_method_handler(
                     servicer.AppendTrainingSample,
                     request_deserializer=mlserver__pb2.MsgSample.FromString,
                     response_serializer=mlserver__pb2.MsgError.SerializeToString,
             ),
+            'SyncSamples': grpc.unary_unary_rpc_method_handler(
+                    servicer.SyncSamples,
+                    request_deserializer=mlserver__pb2.MsgSampleHashes.FromString,
+                    response_serializer=mlserver__pb2.MsgSampleHashes.SerializeToString,
+            ),
+            'UploadSamples': grpc.stream_unary_rpc_method_handler(
+                    servicer.UploadSamples,
+                    request_deserializer=mlserver__pb2.MsgSampleChunk.FromString,
+                    response_serializer=mlserver__pb2.MsgUploadAck.SerializeToString,
+            ),
             'InitMlParams': grpc.unary_unary_rpc_method_handler(
                     servicer.InitMlParams,
                     request_deserializer=mlserver__pb2.MsgInit.FromString,
                     response_serializer=mlserver__pb2.MsgError.SerializeToString,
             ),
-            'Train': grpc.unary_unary_rpc_method_handler(
+            'Train': grpc.unary_stream_rpc_method_handler(
                     servicer.Train,
-                    request_deserializer=mlserver__pb2.VoidMsg.FromString,
-                    response_serializer=mlserver__pb2.VoidMsg.SerializeToString,
+                    request_deserializer=mlserver__pb2.MsgSession.FromString,
+                    response_serializer=mlserver__pb2.MsgTrainProgress.SerializeToString,
             ),
-            'AppendPredictSample': grpc.unary_unary_rpc_method_handler(
-                    servicer.AppendPredictSample,
-                    request_deserializer=mlserver__pb2.MsgPredIn.FromString,
+            'CancelTrain': grpc.unary_unary_rpc_method_handler(
+                    servicer.CancelTrain,
+                    request_deserializer=mlserver__pb2.MsgSession.FromString,
                     response_serializer=mlserver__pb2.MsgError.SerializeToString,
             ),
-            'Predict': grpc.unary_unary_rpc_method_handler(
-                    servicer.Predict,
+            'PredictFrame': grpc.unary_unary_rpc_method_handler(
+                    servicer.PredictFrame,
+                    request_deserializer=mlserver__pb2.MsgPredFrameIn.FromString,
+                    response_serializer=mlserver__pb2.MsgPredFrameOut.SerializeToString,
+            ),
+            'Evaluate': grpc.unary_unary_rpc_method_handler(
+                    servicer.Evaluate,
+                    request_deserializer=mlserver__pb2.MsgSession.FromString,
+                    response_serializer=mlserver__pb2.MsgEvalResult.SerializeToString,
+            ),
+            'ListModels': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListModels,
                     request_deserializer=mlserver__pb2.VoidMsg.FromString,
-                    response_serializer=mlserver__pb2.MsgPredOut.SerializeToString,
+                    response_serializer=mlserver__pb2.MsgModelList.SerializeToString,
+            ),
+            'ActivateModel': grpc.unary_unary_rpc_method_handler(
+                    servicer.ActivateModel,
+                    request_deserializer=mlserver__pb2.MsgModelId.FromString,
+                    response_serializer=mlserver__pb2.MsgError.SerializeToString,
+            ),
+            'DeleteModel': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteModel,
+                    request_deserializer=mlserver__pb2.MsgModelId.FromString,
+                    response_serializer=mlserver__pb2.MsgError.SerializeToString,
+            ),
+            'ExportModel': grpc.unary_unary_rpc_method_handler(
+                    servicer.ExportModel,
+                    request_deserializer=mlserver__pb2.MsgExportIn.FromString,
+                    response_serializer=mlserver__pb2.MsgExportOut.SerializeToString,
             ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
@@ -140,11 +300,62 @@ class Messager(object):
             timeout=None,
             metadata=None):
         return grpc.experimental.unary_unary(request, target, '/Messager/Test',
+            mlserver__pb2.VoidMsg.SerializeToString,
+            mlserver__pb2.MsgError.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Shutdown(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/Shutdown',
             mlserver__pb2.VoidMsg.SerializeToString,
             mlserver__pb2.VoidMsg.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def OpenSession(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/OpenSession',
+            mlserver__pb2.VoidMsg.SerializeToString,
+            mlserver__pb2.MsgSession.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CloseSession(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/CloseSession',
+            mlserver__pb2.MsgSession.SerializeToString,
+            mlserver__pb2.MsgError.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
     @staticmethod
     def AppendTrainingSample(request,
             target,
@@ -162,6 +373,40 @@ class Messager(object):
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def SyncSamples(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/SyncSamples',
+            mlserver__pb2.MsgSampleHashes.SerializeToString,
+            mlserver__pb2.MsgSampleHashes.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def UploadSamples(request_iterator,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.stream_unary(request_iterator, target, '/Messager/UploadSamples',
+            mlserver__pb2.MsgSampleChunk.SerializeToString,
+            mlserver__pb2.MsgUploadAck.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
     @staticmethod
     def InitMlParams(request,
             target,
@@ -190,14 +435,14 @@ class Messager(object):
             wait_for_ready=None,
             timeout=None,
             metadata=None):
-        return grpc.experimental.unary_unary(request, target, '/Messager/Train',
-            mlserver__pb2.VoidMsg.SerializeToString,
-            mlserver__pb2.VoidMsg.FromString,
+        return grpc.experimental.unary_stream(request, target, '/Messager/Train',
+            mlserver__pb2.MsgSession.SerializeToString,
+            mlserver__pb2.MsgTrainProgress.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
     @staticmethod
-    def AppendPredictSample(request,
+    def CancelTrain(request,
             target,
             options=(),
             channel_credentials=None,
@@ -207,14 +452,48 @@ class Messager(object):
             wait_for_ready=None,
             timeout=None,
             metadata=None):
-        return grpc.experimental.unary_unary(request, target, '/Messager/AppendPredictSample',
-            mlserver__pb2.MsgPredIn.SerializeToString,
+        return grpc.experimental.unary_unary(request, target, '/Messager/CancelTrain',
+            mlserver__pb2.MsgSession.SerializeToString,
             mlserver__pb2.MsgError.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
     @staticmethod
-    def Predict(request,
+    def PredictFrame(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/PredictFrame',
+            mlserver__pb2.MsgPredFrameIn.SerializeToString,
+            mlserver__pb2.MsgPredFrameOut.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Evaluate(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/Evaluate',
+            mlserver__pb2.MsgSession.SerializeToString,
+            mlserver__pb2.MsgEvalResult.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListModels(request,
             target,
             options=(),
             channel_credentials=None,
@@ -224,8 +503,59 @@ class Messager(object):
             wait_for_ready=None,
             timeout=None,
             metadata=None):
-        return grpc.experimental.unary_unary(request, target, '/Messager/Predict',
+        return grpc.experimental.unary_unary(request, target, '/Messager/ListModels',
             mlserver__pb2.VoidMsg.SerializeToString,
-            mlserver__pb2.MsgPredOut.FromString,
+            mlserver__pb2.MsgModelList.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ActivateModel(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/ActivateModel',
+            mlserver__pb2.MsgModelId.SerializeToString,
+            mlserver__pb2.MsgError.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DeleteModel(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/DeleteModel',
+            mlserver__pb2.MsgModelId.SerializeToString,
+            mlserver__pb2.MsgError.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ExportModel(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/Messager/ExportModel',
+            mlserver__pb2.MsgExportIn.SerializeToString,
+            mlserver__pb2.MsgExportOut.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
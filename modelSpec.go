@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// The model spec describes the network and its training. The one named by
+// MlModelSpecFile goes to the server with MsgInit, every trained model keeps
+// the spec it was trained with in models/<version>/spec.json, and both
+// backends build the model for its weights from that copy. mlserver.py
+// validates it by the same rules.
+const MlModelSpecFileName = "spec.json"
+
+const (
+	MlSpecConv    = "conv"
+	MlSpecMaxPool = "maxpool"
+	MlSpecFlatten = "flatten"
+	MlSpecDropout = "dropout"
+	MlSpecDense   = "dense"
+)
+
+type MlModelSpec struct {
+	Layers          []MlSpecLayer       `json:"Layers"`
+	Optimizer       string              `json:"Optimizer"`
+	LearningRate    float64             `json:"LearningRate"`
+	BatchSize       int                 `json:"BatchSize"`
+	Epochs          int                 `json:"Epochs"`
+	ValidationSplit float64             `json:"ValidationSplit"`
+	EarlyStopping   MlSpecEarlyStopping `json:"EarlyStopping"`
+}
+
+// MlSpecLayer is one layer. Conv layers use "same" padding, max pooling is
+// 2x2, a dense layer with 0 Units gets one per label.
+type MlSpecLayer struct {
+	Type       string  `json:"Type"`
+	Filters    int     `json:"Filters,omitempty"`
+	Kernel     int     `json:"Kernel,omitempty"`
+	Units      int     `json:"Units,omitempty"`
+	Activation string  `json:"Activation,omitempty"`
+	Rate       float64 `json:"Rate,omitempty"`
+}
+
+// MlSpecEarlyStopping stops the training when Monitor improves less than
+// MinDelta for Patience epochs, 0 Patience turns it off.
+type MlSpecEarlyStopping struct {
+	Monitor  string  `json:"Monitor"`
+	Patience int     `json:"Patience"`
+	MinDelta float64 `json:"MinDelta"`
+}
+
+// MlDefaultModelSpec is the network used before model specs, for models
+// trained without one.
+func MlDefaultModelSpec() *MlModelSpec {
+	conv := func(filters int) MlSpecLayer {
+		return MlSpecLayer{Type: MlSpecConv, Filters: filters, Kernel: 3, Activation: "relu"}
+	}
+	pool := MlSpecLayer{Type: MlSpecMaxPool}
+	return &MlModelSpec{
+		Layers: []MlSpecLayer{
+			conv(32), conv(32), conv(16), conv(16), pool,
+			conv(64), conv(64), conv(64), pool,
+			conv(128), pool,
+			conv(256), pool,
+			conv(512),
+			{Type: MlSpecFlatten},
+			{Type: MlSpecDropout, Rate: 0.5},
+			{Type: MlSpecDense, Activation: "softmax"},
+		},
+		Optimizer:       "adam",
+		LearningRate:    0.001,
+		BatchSize:       100,
+		Epochs:          100,
+		ValidationSplit: 0.1,
+		EarlyStopping:   MlSpecEarlyStopping{Monitor: "loss", Patience: 5, MinDelta: 0.01},
+	}
+}
+
+func MlLoadModelSpec(fileName string) (*MlModelSpec, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("model spec error: %w", err)
+	}
+	s := &MlModelSpec{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(s); err != nil {
+		return nil, &MlError{Op: "model spec", Code: ErrMlBadModelSpec, Message: fmt.Sprintf("%v: %v", fileName, err)}
+	}
+	if err := s.Validate(); err != nil {
+		return nil, &MlError{Op: "model spec", Code: ErrMlBadModelSpec, Message: fmt.Sprintf("%v: %v", fileName, err)}
+	}
+	return s, nil
+}
+
+// mlModelSpecOf returns the spec stored next to a weights file, the default
+// one for weights trained before specs.
+func mlModelSpecOf(weightsFile string) (*MlModelSpec, error) {
+	s, err := MlLoadModelSpec(filepath.Join(filepath.Dir(weightsFile), MlModelSpecFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return MlDefaultModelSpec(), nil
+	}
+	return s, err
+}
+
+func (s *MlModelSpec) Validate() error {
+	if len(s.Layers) == 0 {
+		return fmt.Errorf("no layers")
+	}
+	side := Dataset.SampleSize
+	flat := false
+	for i, l := range s.Layers {
+		switch l.Type {
+		case MlSpecConv:
+			if flat {
+				return fmt.Errorf("layer %d: conv after flatten", i)
+			}
+			if l.Filters <= 0 || l.Kernel <= 0 || l.Kernel%2 == 0 {
+				return fmt.Errorf("layer %d: conv needs Filters and an odd Kernel", i)
+			}
+		case MlSpecMaxPool:
+			if flat {
+				return fmt.Errorf("layer %d: maxpool after flatten", i)
+			}
+			if side /= 2; side == 0 {
+				return fmt.Errorf("layer %d: %d px samples are pooled to nothing", i, Dataset.SampleSize)
+			}
+		case MlSpecFlatten:
+			flat = true
+		case MlSpecDropout:
+			if l.Rate < 0 || l.Rate >= 1 {
+				return fmt.Errorf("layer %d: dropout Rate is expected in 0..1", i)
+			}
+		case MlSpecDense:
+			if !flat {
+				return fmt.Errorf("layer %d: dense before flatten", i)
+			}
+			if l.Units < 0 {
+				return fmt.Errorf("layer %d: negative Units", i)
+			}
+		default:
+			return fmt.Errorf("layer %d: unknown type %q", i, l.Type)
+		}
+		switch l.Activation {
+		case "", "linear", "relu", "softmax":
+		default:
+			return fmt.Errorf("layer %d: unknown activation %q", i, l.Activation)
+		}
+	}
+	last := s.Layers[len(s.Layers)-1]
+	if last.Type != MlSpecDense || last.Units != 0 || last.Activation != "softmax" {
+		return fmt.Errorf("the last layer must be a softmax dense layer with 0 Units")
+	}
+	switch s.Optimizer {
+	case "adam", "sgd", "rmsprop":
+	default:
+		return fmt.Errorf("unknown optimizer %q", s.Optimizer)
+	}
+	if s.LearningRate <= 0 || s.BatchSize <= 0 || s.Epochs <= 0 {
+		return fmt.Errorf("LearningRate, BatchSize and Epochs must be positive")
+	}
+	if s.ValidationSplit < 0 || s.ValidationSplit >= 1 {
+		return fmt.Errorf("ValidationSplit is expected in 0..1")
+	}
+	if s.EarlyStopping.Patience < 0 {
+		return fmt.Errorf("negative EarlyStopping Patience")
+	}
+	if s.EarlyStopping.Patience > 0 {
+		switch s.EarlyStopping.Monitor {
+		case "loss", "accuracy":
+		case "val_loss", "val_accuracy":
+			if s.ValidationSplit == 0 {
+				return fmt.Errorf("EarlyStopping monitors %v without ValidationSplit", s.EarlyStopping.Monitor)
+			}
+		default:
+			return fmt.Errorf("unknown EarlyStopping Monitor %q", s.EarlyStopping.Monitor)
+		}
+	}
+	return nil
+}
+
+// JSON is the spec as sent in MsgInit.
+func (s *MlModelSpec) JSON() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
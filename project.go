@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// An annotation project keeps the frames markup was done on, so the samples
+// can be cut again for another grid or sample size. Every frame is saved in
+// projects/<Project> as <source>.png with a <source>.json holding the grid
+// geometry and the labelled tiles. Frames imported from the crop-only
+// folders are Legacy: they have no frame image, only the crops under
+// legacy/, and can only be resampled.
+const (
+	fileProjectFolder       = "projects"
+	fileProjectLegacyFolder = "legacy"
+)
+
+// A new tile takes the label covering at least projectCoverage of it.
+const projectCoverage = 0.5
+
+// ProjectGrid is the geometry the frame was marked up with, Tile is the tile
+// size in frame pixels.
+type ProjectGrid struct {
+	VerticalDomains int         `json:"VerticalDomains"`
+	Columns         int         `json:"Columns"`
+	Rows            int         `json:"Rows"`
+	Tile            image.Point `json:"Tile"`
+}
+
+// ProjectTile keeps the label by name, the frame survives reordered labels.
+type ProjectTile struct {
+	Index int             `json:"Index"`
+	Rect  image.Rectangle `json:"Rect"`
+	Label string          `json:"Label"`
+	Crop  string          `json:"Crop,omitempty"`
+}
+
+// ProjectFrame is the entry of a frame. A Legacy frame has no frame image
+// and a zero Grid if its samples were saved before the sample index.
+type ProjectFrame struct {
+	Frame   string        `json:"Frame"`
+	Size    image.Point   `json:"Size"`
+	Window  string        `json:"Window,omitempty"`
+	Labeler string        `json:"Labeler,omitempty"`
+	Time    string        `json:"Time"`
+	Grid    ProjectGrid   `json:"Grid"`
+	Tiles   []ProjectTile `json:"Tiles"`
+	Legacy  bool          `json:"Legacy,omitempty"`
+}
+
+type ProjectStruct struct{}
+
+var Project ProjectStruct
+
+// Enabled tells if markup keeps its frames, Project of config.txt names the
+// project.
+func (m *ProjectStruct) Enabled() bool {
+	return Config.Common.Project != ""
+}
+
+func (m *ProjectStruct) Folder() string {
+	return Dataset.Path(fileProjectFolder, Config.Common.Project)
+}
+
+func (m *ProjectStruct) entryFile(source string) string {
+	return filepath.Join(m.Folder(), source+".json")
+}
+
+// SaveFrame keeps frame, the screenshot source, with the tiles marked on it
+// by the current grid. A frame saved again replaces the old one.
+func (m *ProjectStruct) SaveFrame(source string, frame *image.RGBA, classes map[int]int) error {
+	err := os.MkdirAll(m.Folder(), os.ModeDir)
+	if err != nil {
+		return fmt.Errorf("Project save error: %v", err)
+	}
+	size := frame.Rect.Size()
+	tile := GridTileSize(size, verticalDomains)
+	xy := GridNumXYRects(size, tile)
+	e := ProjectFrame{
+		Frame:   fmt.Sprintf("%v.%v", source, fileScreenshotDataSuffix),
+		Size:    size,
+		Window:  targetWindowTitle,
+		Labeler: File.labeler(),
+		Time:    time.Now().UTC().Format(fileMetaTimeFormat),
+		Grid:    ProjectGrid{VerticalDomains: verticalDomains, Columns: xy.X, Rows: xy.Y, Tile: tile},
+		Tiles:   make([]ProjectTile, 0, len(classes)),
+	}
+	for i, c := range classes {
+		e.Tiles = append(e.Tiles, ProjectTile{Index: i, Rect: GridSourceRect(size, tile, i), Label: Config.Labels[c].Name})
+	}
+	sort.Slice(e.Tiles, func(i, j int) bool { return e.Tiles[i].Index < e.Tiles[j].Index })
+	// The frame goes first, List only sees frames with an entry.
+	if err := File.SaveImage(frame, filepath.Join(m.Folder(), e.Frame)); err != nil {
+		return fmt.Errorf("Project save error: %v", err)
+	}
+	return m.writeEntry(source, &e)
+}
+
+func (m *ProjectStruct) writeEntry(source string, e *ProjectFrame) error {
+	data, err := json.MarshalIndent(e, "", "    ")
+	if err != nil {
+		return fmt.Errorf("Project save error: %v", err)
+	}
+	if err := os.WriteFile(m.entryFile(source), data, 0644); err != nil {
+		return fmt.Errorf("Project save error: %v", err)
+	}
+	return nil
+}
+
+// List returns the sources of the frames of the project.
+func (m *ProjectStruct) List() []string {
+	s := make([]string, 0)
+	l, err := os.ReadDir(m.Folder())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println(fmt.Errorf("Project list error: %v", err))
+		}
+		return s
+	}
+	for _, x := range l {
+		if !x.IsDir() && filepath.Ext(x.Name()) == ".json" {
+			s = append(s, strings.TrimSuffix(x.Name(), ".json"))
+		}
+	}
+	return s
+}
+
+func (m *ProjectStruct) Load(source string) (*ProjectFrame, error) {
+	data, err := os.ReadFile(m.entryFile(source))
+	if err != nil {
+		return nil, fmt.Errorf("Project load error: %v", err)
+	}
+	e := &ProjectFrame{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("Project load error: %v %v", source, err)
+	}
+	return e, nil
+}
+
+// Regenerate cuts the samples of every frame again for a grid of vertical
+// rows and the sample size of the dataset, and saves them as new marked
+// data. A new tile gets the label that covers most of it if that is at
+// least projectCoverage of it, tiles markup left alone stay unlabelled.
+func (m *ProjectStruct) Regenerate(vertical int) (int, error) {
+	if vertical <= 0 {
+		return 0, fmt.Errorf("Project regenerate error: %d rows", vertical)
+	}
+	classes := make(map[string]int)
+	for i, l := range Config.Labels {
+		classes[l.Name] = i
+	}
+	n := 0
+	for _, source := range m.List() {
+		e, err := m.Load(source)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		var k int
+		if e.Legacy {
+			k, err = m.resampleLegacy(source, e, classes)
+		} else {
+			k, err = m.regenerateFrame(source, e, vertical, classes)
+		}
+		if err != nil {
+			return n, err
+		}
+		n += k
+	}
+	return n, nil
+}
+
+func (m *ProjectStruct) regenerateFrame(source string, e *ProjectFrame, vertical int, classes map[string]int) (int, error) {
+	frame, err := File.LoadImage(filepath.Join(m.Folder(), e.Frame))
+	if err != nil {
+		log.Println(fmt.Errorf("Project regenerate error: %v", err))
+		return 0, nil
+	}
+	tile := GridTileSize(e.Size, vertical)
+	xy := GridNumXYRects(e.Size, tile)
+	n := 0
+	for i := 0; i < xy.X*xy.Y; i++ {
+		r := GridSourceRect(e.Size, tile, i)
+		cover := make(map[int]int)
+		for _, t := range e.Tiles {
+			c, ok := classes[t.Label]
+			if !ok {
+				continue
+			}
+			x := t.Rect.Intersect(r)
+			cover[c] += x.Dx() * x.Dy()
+		}
+		class, best := -1, 0
+		for c, a := range cover {
+			if a > best || (a == best && c < class) {
+				class, best = c, a
+			}
+		}
+		if class < 0 || float64(best) < projectCoverage*float64(r.Dx()*r.Dy()) {
+			continue
+		}
+		sub, ok := frame.SubImage(r.Add(frame.Rect.Min)).(*image.RGBA)
+		if !ok {
+			continue
+		}
+		meta := &SampleMeta{
+			Class:   class,
+			Index:   i,
+			Source:  source,
+			Window:  e.Window,
+			Rect:    r,
+			Grid:    xy,
+			Frame:   e.Size,
+			Labeler: e.Labeler,
+			Time:    e.Time,
+		}
+		if err := File.SaveNewMarked(Image.Sample(sub), meta); err != nil {
+			return n, fmt.Errorf("Project regenerate error: %v", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// resampleLegacy only brings the crops of a legacy frame to the sample size,
+// there is no frame to cut another grid from.
+func (m *ProjectStruct) resampleLegacy(source string, e *ProjectFrame, classes map[string]int) (int, error) {
+	n := 0
+	for _, t := range e.Tiles {
+		c, ok := classes[t.Label]
+		if !ok {
+			log.Println(fmt.Errorf("Project regenerate error: %v tile %d has no label %q", source, t.Index, t.Label))
+			continue
+		}
+		img, err := File.LoadImage(filepath.Join(m.Folder(), t.Crop))
+		if err != nil {
+			log.Println(fmt.Errorf("Project regenerate error: %v", err))
+			continue
+		}
+		meta := &SampleMeta{
+			Class:    c,
+			Index:    t.Index,
+			Source:   source,
+			Window:   e.Window,
+			Rect:     t.Rect,
+			Grid:     image.Point{X: e.Grid.Columns, Y: e.Grid.Rows},
+			Frame:    e.Size,
+			Labeler:  e.Labeler,
+			Time:     e.Time,
+			Migrated: true,
+		}
+		if err := File.SaveNewMarked(Image.Sample(img), meta); err != nil {
+			return n, fmt.Errorf("Project regenerate error: %v", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ImportLegacy adds the samples of a crop-only folder as legacy frames, one
+// per source screenshot. Sources the project has a frame for are skipped.
+func (m *ProjectStruct) ImportLegacy(dir string) (int, error) {
+	bySource := make(map[string][]*SampleMeta)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(p) != "."+fileScreenshotDataSuffix {
+			return nil
+		}
+		meta, err := File.MarkedMeta(p)
+		if err != nil {
+			log.Println(err)
+			return nil
+		}
+		meta.File = p
+		bySource[meta.Source] = append(bySource[meta.Source], meta)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Project import error: %v", err)
+	}
+	legacy := filepath.Join(m.Folder(), fileProjectLegacyFolder)
+	if err := os.MkdirAll(legacy, os.ModeDir); err != nil {
+		return 0, fmt.Errorf("Project import error: %v", err)
+	}
+	known := make(map[string]bool)
+	for _, s := range m.List() {
+		known[s] = true
+	}
+	n := 0
+	for source, l := range bySource {
+		if known[source] {
+			continue
+		}
+		sort.Slice(l, func(i, j int) bool { return l[i].Index < l[j].Index })
+		e := ProjectFrame{Legacy: true, Time: l[0].Time, Window: l[0].Window, Labeler: l[0].Labeler, Size: l[0].Frame, Tiles: make([]ProjectTile, 0, len(l))}
+		// Samples saved before the index know nothing of their grid,
+		// their frame keeps a zero one.
+		if g := l[0].Grid; g.X > 0 && g.Y > 0 {
+			e.Grid = ProjectGrid{VerticalDomains: g.Y, Columns: g.X, Rows: g.Y, Tile: l[0].Rect.Size()}
+		}
+		for _, meta := range l {
+			data, err := os.ReadFile(meta.File)
+			if err != nil {
+				return n, fmt.Errorf("Project import error: %v", err)
+			}
+			crop := filepath.Join(fileProjectLegacyFolder, filepath.Base(meta.File))
+			if err := os.WriteFile(filepath.Join(m.Folder(), crop), data, 0644); err != nil {
+				return n, fmt.Errorf("Project import error: %v", err)
+			}
+			e.Tiles = append(e.Tiles, ProjectTile{Index: meta.Index, Rect: meta.Rect, Label: Config.Labels[meta.Class].Name, Crop: crop})
+		}
+		if err := m.writeEntry(source, &e); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
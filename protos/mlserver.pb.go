@@ -23,19 +23,55 @@ const (
 type EnumError int32
 
 const (
-	EnumError_ENUMERROR_NOERROR   EnumError = 0
-	EnumError_ENUMERROR_NOOUTDATA EnumError = 1
+	EnumError_ENUMERROR_NOERROR         EnumError = 0
+	EnumError_ENUMERROR_NOOUTDATA       EnumError = 1
+	EnumError_ENUMERROR_NOSESSION       EnumError = 2
+	EnumError_ENUMERROR_NOMODEL         EnumError = 3
+	EnumError_ENUMERROR_MODELACTIVE     EnumError = 4
+	EnumError_ENUMERROR_BADSAMPLE       EnumError = 5
+	EnumError_ENUMERROR_SHAPEMISMATCH   EnumError = 6
+	EnumError_ENUMERROR_NOTINITIALIZED  EnumError = 7
+	EnumError_ENUMERROR_TRAININGFAILED  EnumError = 8
+	EnumError_ENUMERROR_OUTOFMEMORY     EnumError = 9
+	EnumError_ENUMERROR_INTERNAL        EnumError = 10
+	EnumError_ENUMERROR_BADMODELSPEC    EnumError = 11
+	EnumError_ENUMERROR_WEIGHTSMISMATCH EnumError = 12
+	EnumError_ENUMERROR_EXPORTFAILED    EnumError = 13
 )
 
 // Enum value maps for EnumError.
 var (
 	EnumError_name = map[int32]string{
-		0: "ENUMERROR_NOERROR",
-		1: "ENUMERROR_NOOUTDATA",
+		0:  "ENUMERROR_NOERROR",
+		1:  "ENUMERROR_NOOUTDATA",
+		2:  "ENUMERROR_NOSESSION",
+		3:  "ENUMERROR_NOMODEL",
+		4:  "ENUMERROR_MODELACTIVE",
+		5:  "ENUMERROR_BADSAMPLE",
+		6:  "ENUMERROR_SHAPEMISMATCH",
+		7:  "ENUMERROR_NOTINITIALIZED",
+		8:  "ENUMERROR_TRAININGFAILED",
+		9:  "ENUMERROR_OUTOFMEMORY",
+		10: "ENUMERROR_INTERNAL",
+		11: "ENUMERROR_BADMODELSPEC",
+		12: "ENUMERROR_WEIGHTSMISMATCH",
+		13: "ENUMERROR_EXPORTFAILED",
 	}
 	EnumError_value = map[string]int32{
-		"ENUMERROR_NOERROR":   0,
-		"ENUMERROR_NOOUTDATA": 1,
+		"ENUMERROR_NOERROR":         0,
+		"ENUMERROR_NOOUTDATA":       1,
+		"ENUMERROR_NOSESSION":       2,
+		"ENUMERROR_NOMODEL":         3,
+		"ENUMERROR_MODELACTIVE":     4,
+		"ENUMERROR_BADSAMPLE":       5,
+		"ENUMERROR_SHAPEMISMATCH":   6,
+		"ENUMERROR_NOTINITIALIZED":  7,
+		"ENUMERROR_TRAININGFAILED":  8,
+		"ENUMERROR_OUTOFMEMORY":     9,
+		"ENUMERROR_INTERNAL":        10,
+		"ENUMERROR_BADMODELSPEC":    11,
+		"ENUMERROR_WEIGHTSMISMATCH": 12,
+		"ENUMERROR_EXPORTFAILED":    13,
 	}
 )
 
@@ -113,32 +149,844 @@ func (x *Message) GetMsg() string {
 	return ""
 }
 
+type MsgSession struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int64 `protobuf:"varint,1,opt,name=Id,proto3" json:"Id,omitempty"`
+}
+
+func (x *MsgSession) Reset() {
+	*x = MsgSession{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgSession) ProtoMessage() {}
+
+func (x *MsgSession) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgSession.ProtoReflect.Descriptor instead.
+func (*MsgSession) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MsgSession) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
 type MsgSample struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	XData []byte `protobuf:"bytes,1,opt,name=XData,proto3" json:"XData,omitempty"`
-	YData int64  `protobuf:"varint,2,opt,name=YData,proto3" json:"YData,omitempty"`
+	XData   []byte `protobuf:"bytes,1,opt,name=XData,proto3" json:"XData,omitempty"`
+	YData   int64  `protobuf:"varint,2,opt,name=YData,proto3" json:"YData,omitempty"`
+	Session int64  `protobuf:"varint,3,opt,name=Session,proto3" json:"Session,omitempty"`
+}
+
+func (x *MsgSample) Reset() {
+	*x = MsgSample{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgSample) ProtoMessage() {}
+
+func (x *MsgSample) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgSample.ProtoReflect.Descriptor instead.
+func (*MsgSample) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MsgSample) GetXData() []byte {
+	if x != nil {
+		return x.XData
+	}
+	return nil
+}
+
+func (x *MsgSample) GetYData() int64 {
+	if x != nil {
+		return x.YData
+	}
+	return 0
+}
+
+func (x *MsgSample) GetSession() int64 {
+	if x != nil {
+		return x.Session
+	}
+	return 0
+}
+
+type MsgSampleHashes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session int64     `protobuf:"varint,1,opt,name=Session,proto3" json:"Session,omitempty"`
+	Hashes  [][]byte  `protobuf:"bytes,2,rep,name=Hashes,proto3" json:"Hashes,omitempty"`
+	Err     EnumError `protobuf:"varint,3,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	Message string    `protobuf:"bytes,4,opt,name=Message,proto3" json:"Message,omitempty"`
+}
+
+func (x *MsgSampleHashes) Reset() {
+	*x = MsgSampleHashes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgSampleHashes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgSampleHashes) ProtoMessage() {}
+
+func (x *MsgSampleHashes) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgSampleHashes.ProtoReflect.Descriptor instead.
+func (*MsgSampleHashes) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MsgSampleHashes) GetSession() int64 {
+	if x != nil {
+		return x.Session
+	}
+	return 0
+}
+
+func (x *MsgSampleHashes) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+func (x *MsgSampleHashes) GetErr() EnumError {
+	if x != nil {
+		return x.Err
+	}
+	return EnumError_ENUMERROR_NOERROR
+}
+
+func (x *MsgSampleHashes) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type MsgSampleData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash  []byte `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	XData []byte `protobuf:"bytes,2,opt,name=XData,proto3" json:"XData,omitempty"`
+	YData int64  `protobuf:"varint,3,opt,name=YData,proto3" json:"YData,omitempty"`
+}
+
+func (x *MsgSampleData) Reset() {
+	*x = MsgSampleData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgSampleData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgSampleData) ProtoMessage() {}
+
+func (x *MsgSampleData) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgSampleData.ProtoReflect.Descriptor instead.
+func (*MsgSampleData) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MsgSampleData) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *MsgSampleData) GetXData() []byte {
+	if x != nil {
+		return x.XData
+	}
+	return nil
+}
+
+func (x *MsgSampleData) GetYData() int64 {
+	if x != nil {
+		return x.YData
+	}
+	return 0
+}
+
+type MsgSampleChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Session int64            `protobuf:"varint,1,opt,name=Session,proto3" json:"Session,omitempty"`
+	Samples []*MsgSampleData `protobuf:"bytes,2,rep,name=Samples,proto3" json:"Samples,omitempty"`
+}
+
+func (x *MsgSampleChunk) Reset() {
+	*x = MsgSampleChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgSampleChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgSampleChunk) ProtoMessage() {}
+
+func (x *MsgSampleChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgSampleChunk.ProtoReflect.Descriptor instead.
+func (*MsgSampleChunk) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MsgSampleChunk) GetSession() int64 {
+	if x != nil {
+		return x.Session
+	}
+	return 0
+}
+
+func (x *MsgSampleChunk) GetSamples() []*MsgSampleData {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+type MsgUploadAck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Received int64     `protobuf:"varint,1,opt,name=Received,proto3" json:"Received,omitempty"`
+	Err      EnumError `protobuf:"varint,2,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	Message  string    `protobuf:"bytes,3,opt,name=Message,proto3" json:"Message,omitempty"`
+}
+
+func (x *MsgUploadAck) Reset() {
+	*x = MsgUploadAck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgUploadAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgUploadAck) ProtoMessage() {}
+
+func (x *MsgUploadAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgUploadAck.ProtoReflect.Descriptor instead.
+func (*MsgUploadAck) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MsgUploadAck) GetReceived() int64 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *MsgUploadAck) GetErr() EnumError {
+	if x != nil {
+		return x.Err
+	}
+	return EnumError_ENUMERROR_NOERROR
+}
+
+func (x *MsgUploadAck) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type MsgTrainProgress struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Epoch       int64     `protobuf:"varint,1,opt,name=Epoch,proto3" json:"Epoch,omitempty"`
+	Epochs      int64     `protobuf:"varint,2,opt,name=Epochs,proto3" json:"Epochs,omitempty"`
+	Loss        float32   `protobuf:"fixed32,3,opt,name=Loss,proto3" json:"Loss,omitempty"`
+	Accuracy    float32   `protobuf:"fixed32,4,opt,name=Accuracy,proto3" json:"Accuracy,omitempty"`
+	ValLoss     float32   `protobuf:"fixed32,5,opt,name=ValLoss,proto3" json:"ValLoss,omitempty"`
+	ValAccuracy float32   `protobuf:"fixed32,6,opt,name=ValAccuracy,proto3" json:"ValAccuracy,omitempty"`
+	Err         EnumError `protobuf:"varint,7,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	Message     string    `protobuf:"bytes,8,opt,name=Message,proto3" json:"Message,omitempty"`
+}
+
+func (x *MsgTrainProgress) Reset() {
+	*x = MsgTrainProgress{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgTrainProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgTrainProgress) ProtoMessage() {}
+
+func (x *MsgTrainProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgTrainProgress.ProtoReflect.Descriptor instead.
+func (*MsgTrainProgress) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MsgTrainProgress) GetEpoch() int64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *MsgTrainProgress) GetEpochs() int64 {
+	if x != nil {
+		return x.Epochs
+	}
+	return 0
+}
+
+func (x *MsgTrainProgress) GetLoss() float32 {
+	if x != nil {
+		return x.Loss
+	}
+	return 0
+}
+
+func (x *MsgTrainProgress) GetAccuracy() float32 {
+	if x != nil {
+		return x.Accuracy
+	}
+	return 0
+}
+
+func (x *MsgTrainProgress) GetValLoss() float32 {
+	if x != nil {
+		return x.ValLoss
+	}
+	return 0
+}
+
+func (x *MsgTrainProgress) GetValAccuracy() float32 {
+	if x != nil {
+		return x.ValAccuracy
+	}
+	return 0
+}
+
+func (x *MsgTrainProgress) GetErr() EnumError {
+	if x != nil {
+		return x.Err
+	}
+	return EnumError_ENUMERROR_NOERROR
+}
+
+func (x *MsgTrainProgress) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type MsgPredFrameIn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tiles      [][]byte `protobuf:"bytes,1,rep,name=Tiles,proto3" json:"Tiles,omitempty"`
+	Session    int64    `protobuf:"varint,2,opt,name=Session,proto3" json:"Session,omitempty"`
+	NumClasses int64    `protobuf:"varint,3,opt,name=NumClasses,proto3" json:"NumClasses,omitempty"`
+	Channels   int64    `protobuf:"varint,4,opt,name=Channels,proto3" json:"Channels,omitempty"`
+}
+
+func (x *MsgPredFrameIn) Reset() {
+	*x = MsgPredFrameIn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgPredFrameIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgPredFrameIn) ProtoMessage() {}
+
+func (x *MsgPredFrameIn) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgPredFrameIn.ProtoReflect.Descriptor instead.
+func (*MsgPredFrameIn) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MsgPredFrameIn) GetTiles() [][]byte {
+	if x != nil {
+		return x.Tiles
+	}
+	return nil
+}
+
+func (x *MsgPredFrameIn) GetSession() int64 {
+	if x != nil {
+		return x.Session
+	}
+	return 0
+}
+
+func (x *MsgPredFrameIn) GetNumClasses() int64 {
+	if x != nil {
+		return x.NumClasses
+	}
+	return 0
+}
+
+func (x *MsgPredFrameIn) GetChannels() int64 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+type MsgPredFrameOut struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Probs      []float32 `protobuf:"fixed32,1,rep,packed,name=Probs,proto3" json:"Probs,omitempty"`
+	Err        EnumError `protobuf:"varint,2,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	NumClasses int64     `protobuf:"varint,3,opt,name=NumClasses,proto3" json:"NumClasses,omitempty"`
+	Message    string    `protobuf:"bytes,4,opt,name=Message,proto3" json:"Message,omitempty"`
+}
+
+func (x *MsgPredFrameOut) Reset() {
+	*x = MsgPredFrameOut{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgPredFrameOut) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgPredFrameOut) ProtoMessage() {}
+
+func (x *MsgPredFrameOut) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgPredFrameOut.ProtoReflect.Descriptor instead.
+func (*MsgPredFrameOut) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MsgPredFrameOut) GetProbs() []float32 {
+	if x != nil {
+		return x.Probs
+	}
+	return nil
+}
+
+func (x *MsgPredFrameOut) GetErr() EnumError {
+	if x != nil {
+		return x.Err
+	}
+	return EnumError_ENUMERROR_NOERROR
+}
+
+func (x *MsgPredFrameOut) GetNumClasses() int64 {
+	if x != nil {
+		return x.NumClasses
+	}
+	return 0
+}
+
+func (x *MsgPredFrameOut) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type MsgInit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SampleSize   int64     `protobuf:"varint,1,opt,name=SampleSize,proto3" json:"SampleSize,omitempty"`
+	Session      int64     `protobuf:"varint,2,opt,name=Session,proto3" json:"Session,omitempty"`
+	NumClasses   int64     `protobuf:"varint,3,opt,name=NumClasses,proto3" json:"NumClasses,omitempty"`
+	ClassNames   []string  `protobuf:"bytes,4,rep,name=ClassNames,proto3" json:"ClassNames,omitempty"`
+	Channels     int64     `protobuf:"varint,5,opt,name=Channels,proto3" json:"Channels,omitempty"`
+	ModelSpec    string    `protobuf:"bytes,6,opt,name=ModelSpec,proto3" json:"ModelSpec,omitempty"`
+	ClassWeights []float32 `protobuf:"fixed32,7,rep,packed,name=ClassWeights,proto3" json:"ClassWeights,omitempty"`
+}
+
+func (x *MsgInit) Reset() {
+	*x = MsgInit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgInit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgInit) ProtoMessage() {}
+
+func (x *MsgInit) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgInit.ProtoReflect.Descriptor instead.
+func (*MsgInit) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MsgInit) GetSampleSize() int64 {
+	if x != nil {
+		return x.SampleSize
+	}
+	return 0
+}
+
+func (x *MsgInit) GetSession() int64 {
+	if x != nil {
+		return x.Session
+	}
+	return 0
+}
+
+func (x *MsgInit) GetNumClasses() int64 {
+	if x != nil {
+		return x.NumClasses
+	}
+	return 0
+}
+
+func (x *MsgInit) GetClassNames() []string {
+	if x != nil {
+		return x.ClassNames
+	}
+	return nil
+}
+
+func (x *MsgInit) GetChannels() int64 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+func (x *MsgInit) GetModelSpec() string {
+	if x != nil {
+		return x.ModelSpec
+	}
+	return ""
+}
+
+func (x *MsgInit) GetClassWeights() []float32 {
+	if x != nil {
+		return x.ClassWeights
+	}
+	return nil
+}
+
+type MsgModelInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version     string   `protobuf:"bytes,1,opt,name=Version,proto3" json:"Version,omitempty"`
+	Created     string   `protobuf:"bytes,2,opt,name=Created,proto3" json:"Created,omitempty"`
+	Samples     int64    `protobuf:"varint,3,opt,name=Samples,proto3" json:"Samples,omitempty"`
+	ClassNames  []string `protobuf:"bytes,4,rep,name=ClassNames,proto3" json:"ClassNames,omitempty"`
+	Loss        float32  `protobuf:"fixed32,5,opt,name=Loss,proto3" json:"Loss,omitempty"`
+	Accuracy    float32  `protobuf:"fixed32,6,opt,name=Accuracy,proto3" json:"Accuracy,omitempty"`
+	ValLoss     float32  `protobuf:"fixed32,7,opt,name=ValLoss,proto3" json:"ValLoss,omitempty"`
+	ValAccuracy float32  `protobuf:"fixed32,8,opt,name=ValAccuracy,proto3" json:"ValAccuracy,omitempty"`
+	Active      bool     `protobuf:"varint,9,opt,name=Active,proto3" json:"Active,omitempty"`
+}
+
+func (x *MsgModelInfo) Reset() {
+	*x = MsgModelInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgModelInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgModelInfo) ProtoMessage() {}
+
+func (x *MsgModelInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgModelInfo.ProtoReflect.Descriptor instead.
+func (*MsgModelInfo) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MsgModelInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *MsgModelInfo) GetCreated() string {
+	if x != nil {
+		return x.Created
+	}
+	return ""
+}
+
+func (x *MsgModelInfo) GetSamples() int64 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *MsgModelInfo) GetClassNames() []string {
+	if x != nil {
+		return x.ClassNames
+	}
+	return nil
+}
+
+func (x *MsgModelInfo) GetLoss() float32 {
+	if x != nil {
+		return x.Loss
+	}
+	return 0
+}
+
+func (x *MsgModelInfo) GetAccuracy() float32 {
+	if x != nil {
+		return x.Accuracy
+	}
+	return 0
+}
+
+func (x *MsgModelInfo) GetValLoss() float32 {
+	if x != nil {
+		return x.ValLoss
+	}
+	return 0
+}
+
+func (x *MsgModelInfo) GetValAccuracy() float32 {
+	if x != nil {
+		return x.ValAccuracy
+	}
+	return 0
+}
+
+func (x *MsgModelInfo) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+type MsgModelList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Models []*MsgModelInfo `protobuf:"bytes,1,rep,name=Models,proto3" json:"Models,omitempty"`
 }
 
-func (x *MsgSample) Reset() {
-	*x = MsgSample{}
+func (x *MsgModelList) Reset() {
+	*x = MsgModelList{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_mlserver_proto_msgTypes[1]
+		mi := &file_mlserver_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MsgSample) String() string {
+func (x *MsgModelList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MsgSample) ProtoMessage() {}
+func (*MsgModelList) ProtoMessage() {}
 
-func (x *MsgSample) ProtoReflect() protoreflect.Message {
-	mi := &file_mlserver_proto_msgTypes[1]
+func (x *MsgModelList) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -149,50 +997,91 @@ func (x *MsgSample) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MsgSample.ProtoReflect.Descriptor instead.
-func (*MsgSample) Descriptor() ([]byte, []int) {
-	return file_mlserver_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use MsgModelList.ProtoReflect.Descriptor instead.
+func (*MsgModelList) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *MsgSample) GetXData() []byte {
+func (x *MsgModelList) GetModels() []*MsgModelInfo {
 	if x != nil {
-		return x.XData
+		return x.Models
 	}
 	return nil
 }
 
-func (x *MsgSample) GetYData() int64 {
+type MsgModelId struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version string `protobuf:"bytes,1,opt,name=Version,proto3" json:"Version,omitempty"`
+}
+
+func (x *MsgModelId) Reset() {
+	*x = MsgModelId{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mlserver_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MsgModelId) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MsgModelId) ProtoMessage() {}
+
+func (x *MsgModelId) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MsgModelId.ProtoReflect.Descriptor instead.
+func (*MsgModelId) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MsgModelId) GetVersion() string {
 	if x != nil {
-		return x.YData
+		return x.Version
 	}
-	return 0
+	return ""
 }
 
-type MsgPredIn struct {
+type MsgExportIn struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data []byte `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+	Version string `protobuf:"bytes,1,opt,name=Version,proto3" json:"Version,omitempty"`
+	Folder  string `protobuf:"bytes,2,opt,name=Folder,proto3" json:"Folder,omitempty"`
 }
 
-func (x *MsgPredIn) Reset() {
-	*x = MsgPredIn{}
+func (x *MsgExportIn) Reset() {
+	*x = MsgExportIn{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_mlserver_proto_msgTypes[2]
+		mi := &file_mlserver_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MsgPredIn) String() string {
+func (x *MsgExportIn) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MsgPredIn) ProtoMessage() {}
+func (*MsgExportIn) ProtoMessage() {}
 
-func (x *MsgPredIn) ProtoReflect() protoreflect.Message {
-	mi := &file_mlserver_proto_msgTypes[2]
+func (x *MsgExportIn) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -203,44 +1092,53 @@ func (x *MsgPredIn) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MsgPredIn.ProtoReflect.Descriptor instead.
-func (*MsgPredIn) Descriptor() ([]byte, []int) {
-	return file_mlserver_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use MsgExportIn.ProtoReflect.Descriptor instead.
+func (*MsgExportIn) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *MsgPredIn) GetData() []byte {
+func (x *MsgExportIn) GetVersion() string {
 	if x != nil {
-		return x.Data
+		return x.Version
 	}
-	return nil
+	return ""
+}
+
+func (x *MsgExportIn) GetFolder() string {
+	if x != nil {
+		return x.Folder
+	}
+	return ""
 }
 
-type MsgPredOut struct {
+type MsgExportOut struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data []byte    `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
-	Err  EnumError `protobuf:"varint,2,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	ModelFile   string    `protobuf:"bytes,1,opt,name=ModelFile,proto3" json:"ModelFile,omitempty"`
+	SidecarFile string    `protobuf:"bytes,2,opt,name=SidecarFile,proto3" json:"SidecarFile,omitempty"`
+	Err         EnumError `protobuf:"varint,3,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	Message     string    `protobuf:"bytes,4,opt,name=Message,proto3" json:"Message,omitempty"`
 }
 
-func (x *MsgPredOut) Reset() {
-	*x = MsgPredOut{}
+func (x *MsgExportOut) Reset() {
+	*x = MsgExportOut{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_mlserver_proto_msgTypes[3]
+		mi := &file_mlserver_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MsgPredOut) String() string {
+func (x *MsgExportOut) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MsgPredOut) ProtoMessage() {}
+func (*MsgExportOut) ProtoMessage() {}
 
-func (x *MsgPredOut) ProtoReflect() protoreflect.Message {
-	mi := &file_mlserver_proto_msgTypes[3]
+func (x *MsgExportOut) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -251,50 +1149,66 @@ func (x *MsgPredOut) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MsgPredOut.ProtoReflect.Descriptor instead.
-func (*MsgPredOut) Descriptor() ([]byte, []int) {
-	return file_mlserver_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use MsgExportOut.ProtoReflect.Descriptor instead.
+func (*MsgExportOut) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *MsgPredOut) GetData() []byte {
+func (x *MsgExportOut) GetModelFile() string {
 	if x != nil {
-		return x.Data
+		return x.ModelFile
 	}
-	return nil
+	return ""
+}
+
+func (x *MsgExportOut) GetSidecarFile() string {
+	if x != nil {
+		return x.SidecarFile
+	}
+	return ""
 }
 
-func (x *MsgPredOut) GetErr() EnumError {
+func (x *MsgExportOut) GetErr() EnumError {
 	if x != nil {
 		return x.Err
 	}
 	return EnumError_ENUMERROR_NOERROR
 }
 
-type MsgInit struct {
+func (x *MsgExportOut) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type MsgEvalResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SampleSize int64 `protobuf:"varint,1,opt,name=SampleSize,proto3" json:"SampleSize,omitempty"`
+	Predicted []int64   `protobuf:"varint,1,rep,packed,name=Predicted,proto3" json:"Predicted,omitempty"`
+	Err       EnumError `protobuf:"varint,2,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	Message   string    `protobuf:"bytes,3,opt,name=Message,proto3" json:"Message,omitempty"`
 }
 
-func (x *MsgInit) Reset() {
-	*x = MsgInit{}
+func (x *MsgEvalResult) Reset() {
+	*x = MsgEvalResult{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_mlserver_proto_msgTypes[4]
+		mi := &file_mlserver_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MsgInit) String() string {
+func (x *MsgEvalResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MsgInit) ProtoMessage() {}
+func (*MsgEvalResult) ProtoMessage() {}
 
-func (x *MsgInit) ProtoReflect() protoreflect.Message {
-	mi := &file_mlserver_proto_msgTypes[4]
+func (x *MsgEvalResult) ProtoReflect() protoreflect.Message {
+	mi := &file_mlserver_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -305,16 +1219,30 @@ func (x *MsgInit) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MsgInit.ProtoReflect.Descriptor instead.
-func (*MsgInit) Descriptor() ([]byte, []int) {
-	return file_mlserver_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use MsgEvalResult.ProtoReflect.Descriptor instead.
+func (*MsgEvalResult) Descriptor() ([]byte, []int) {
+	return file_mlserver_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *MsgInit) GetSampleSize() int64 {
+func (x *MsgEvalResult) GetPredicted() []int64 {
 	if x != nil {
-		return x.SampleSize
+		return x.Predicted
 	}
-	return 0
+	return nil
+}
+
+func (x *MsgEvalResult) GetErr() EnumError {
+	if x != nil {
+		return x.Err
+	}
+	return EnumError_ENUMERROR_NOERROR
+}
+
+func (x *MsgEvalResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
 }
 
 type MsgError struct {
@@ -322,13 +1250,14 @@ type MsgError struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Err EnumError `protobuf:"varint,1,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	Err     EnumError `protobuf:"varint,1,opt,name=Err,proto3,enum=EnumError" json:"Err,omitempty"`
+	Message string    `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
 }
 
 func (x *MsgError) Reset() {
 	*x = MsgError{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_mlserver_proto_msgTypes[5]
+		mi := &file_mlserver_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -341,7 +1270,7 @@ func (x *MsgError) String() string {
 func (*MsgError) ProtoMessage() {}
 
 func (x *MsgError) ProtoReflect() protoreflect.Message {
-	mi := &file_mlserver_proto_msgTypes[5]
+	mi := &file_mlserver_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -354,7 +1283,7 @@ func (x *MsgError) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MsgError.ProtoReflect.Descriptor instead.
 func (*MsgError) Descriptor() ([]byte, []int) {
-	return file_mlserver_proto_rawDescGZIP(), []int{5}
+	return file_mlserver_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *MsgError) GetErr() EnumError {
@@ -364,6 +1293,13 @@ func (x *MsgError) GetErr() EnumError {
 	return EnumError_ENUMERROR_NOERROR
 }
 
+func (x *MsgError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 type VoidMsg struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -373,7 +1309,7 @@ type VoidMsg struct {
 func (x *VoidMsg) Reset() {
 	*x = VoidMsg{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_mlserver_proto_msgTypes[6]
+		mi := &file_mlserver_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -386,7 +1322,7 @@ func (x *VoidMsg) String() string {
 func (*VoidMsg) ProtoMessage() {}
 
 func (x *VoidMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_mlserver_proto_msgTypes[6]
+	mi := &file_mlserver_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -399,7 +1335,7 @@ func (x *VoidMsg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VoidMsg.ProtoReflect.Descriptor instead.
 func (*VoidMsg) Descriptor() ([]byte, []int) {
-	return file_mlserver_proto_rawDescGZIP(), []int{6}
+	return file_mlserver_proto_rawDescGZIP(), []int{18}
 }
 
 var File_mlserver_proto protoreflect.FileDescriptor
@@ -407,42 +1343,198 @@ var File_mlserver_proto protoreflect.FileDescriptor
 var file_mlserver_proto_rawDesc = []byte{
 	0x0a, 0x0e, 0x6d, 0x6c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x22, 0x1b, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x4d,
-	0x73, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x4d, 0x73, 0x67, 0x22, 0x37, 0x0a,
-	0x09, 0x4d, 0x73, 0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x58, 0x44,
-	0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x58, 0x44, 0x61, 0x74, 0x61,
-	0x12, 0x14, 0x0a, 0x05, 0x59, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x05, 0x59, 0x44, 0x61, 0x74, 0x61, 0x22, 0x1f, 0x0a, 0x09, 0x4d, 0x73, 0x67, 0x50, 0x72, 0x65,
-	0x64, 0x49, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x22, 0x3e, 0x0a, 0x0a, 0x4d, 0x73, 0x67, 0x50, 0x72,
-	0x65, 0x64, 0x4f, 0x75, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1c, 0x0a, 0x03, 0x45, 0x72, 0x72,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x45, 0x6e, 0x75, 0x6d, 0x45, 0x72, 0x72,
-	0x6f, 0x72, 0x52, 0x03, 0x45, 0x72, 0x72, 0x22, 0x29, 0x0a, 0x07, 0x4d, 0x73, 0x67, 0x49, 0x6e,
-	0x69, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69,
-	0x7a, 0x65, 0x22, 0x28, 0x0a, 0x08, 0x4d, 0x73, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1c,
-	0x0a, 0x03, 0x45, 0x72, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x45, 0x6e,
-	0x75, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x03, 0x45, 0x72, 0x72, 0x22, 0x09, 0x0a, 0x07,
-	0x56, 0x6f, 0x69, 0x64, 0x4d, 0x73, 0x67, 0x2a, 0x3b, 0x0a, 0x09, 0x45, 0x6e, 0x75, 0x6d, 0x45,
-	0x72, 0x72, 0x6f, 0x72, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f,
-	0x52, 0x5f, 0x4e, 0x4f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x45,
-	0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4e, 0x4f, 0x4f, 0x55, 0x54, 0x44, 0x41,
-	0x54, 0x41, 0x10, 0x01, 0x32, 0xf3, 0x01, 0x0a, 0x08, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x72, 0x12, 0x1c, 0x0a, 0x04, 0x54, 0x65, 0x73, 0x74, 0x12, 0x08, 0x2e, 0x56, 0x6f, 0x69, 0x64,
-	0x4d, 0x73, 0x67, 0x1a, 0x08, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x4d, 0x73, 0x67, 0x22, 0x00, 0x12,
-	0x2f, 0x0a, 0x14, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e,
-	0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x0a, 0x2e, 0x4d, 0x73, 0x67, 0x53, 0x61, 0x6d,
-	0x70, 0x6c, 0x65, 0x1a, 0x09, 0x2e, 0x4d, 0x73, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x00,
-	0x12, 0x25, 0x0a, 0x0c, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
-	0x12, 0x08, 0x2e, 0x4d, 0x73, 0x67, 0x49, 0x6e, 0x69, 0x74, 0x1a, 0x09, 0x2e, 0x4d, 0x73, 0x67,
-	0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x00, 0x12, 0x1d, 0x0a, 0x05, 0x54, 0x72, 0x61, 0x69, 0x6e,
-	0x12, 0x08, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x4d, 0x73, 0x67, 0x1a, 0x08, 0x2e, 0x56, 0x6f, 0x69,
-	0x64, 0x4d, 0x73, 0x67, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x13, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64,
-	0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x0a, 0x2e,
-	0x4d, 0x73, 0x67, 0x50, 0x72, 0x65, 0x64, 0x49, 0x6e, 0x1a, 0x09, 0x2e, 0x4d, 0x73, 0x67, 0x45,
-	0x72, 0x72, 0x6f, 0x72, 0x22, 0x00, 0x12, 0x22, 0x0a, 0x07, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63,
-	0x74, 0x12, 0x08, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x4d, 0x73, 0x67, 0x1a, 0x0b, 0x2e, 0x4d, 0x73,
-	0x67, 0x50, 0x72, 0x65, 0x64, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x2f,
+	0x73, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x4d, 0x73, 0x67, 0x22, 0x1c, 0x0a,
+	0x0a, 0x4d, 0x73, 0x67, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x49,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x49, 0x64, 0x22, 0x51, 0x0a, 0x09, 0x4d,
+	0x73, 0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x58, 0x44, 0x61, 0x74,
+	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x58, 0x44, 0x61, 0x74, 0x61, 0x12, 0x14,
+	0x0a, 0x05, 0x59, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x59,
+	0x44, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x7b,
+	0x0a, 0x0f, 0x4d, 0x73, 0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x48,
+	0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x48, 0x61, 0x73,
+	0x68, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x03, 0x45, 0x72, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0a, 0x2e, 0x45, 0x6e, 0x75, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x03, 0x45, 0x72,
+	0x72, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x4f, 0x0a, 0x0d, 0x4d,
+	0x73, 0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x44, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04,
+	0x48, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68,
+	0x12, 0x14, 0x0a, 0x05, 0x58, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x05, 0x58, 0x44, 0x61, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x59, 0x44, 0x61, 0x74, 0x61, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x59, 0x44, 0x61, 0x74, 0x61, 0x22, 0x54, 0x0a, 0x0e,
+	0x4d, 0x73, 0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x18,
+	0x0a, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x07, 0x53, 0x61, 0x6d, 0x70,
+	0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x4d, 0x73, 0x67, 0x53,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x44, 0x61, 0x74, 0x61, 0x52, 0x07, 0x53, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x73, 0x22, 0x62, 0x0a, 0x0c, 0x4d, 0x73, 0x67, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x41,
+	0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x1c,
+	0x0a, 0x03, 0x45, 0x72, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x45, 0x6e,
+	0x75, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x03, 0x45, 0x72, 0x72, 0x12, 0x18, 0x0a, 0x07,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xe4, 0x01, 0x0a, 0x10, 0x4d, 0x73, 0x67, 0x54, 0x72,
+	0x61, 0x69, 0x6e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x45,
+	0x70, 0x6f, 0x63, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x45, 0x70, 0x6f, 0x63,
+	0x68, 0x12, 0x16, 0x0a, 0x06, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x4c, 0x6f, 0x73,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x04, 0x4c, 0x6f, 0x73, 0x73, 0x12, 0x1a, 0x0a,
+	0x08, 0x41, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x52,
+	0x08, 0x41, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x61, 0x6c,
+	0x4c, 0x6f, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x52, 0x07, 0x56, 0x61, 0x6c, 0x4c,
+	0x6f, 0x73, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x56, 0x61, 0x6c, 0x41, 0x63, 0x63, 0x75, 0x72, 0x61,
+	0x63, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0b, 0x56, 0x61, 0x6c, 0x41, 0x63, 0x63,
+	0x75, 0x72, 0x61, 0x63, 0x79, 0x12, 0x1c, 0x0a, 0x03, 0x45, 0x72, 0x72, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x45, 0x6e, 0x75, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x03,
+	0x45, 0x72, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x7c, 0x0a,
+	0x0e, 0x4d, 0x73, 0x67, 0x50, 0x72, 0x65, 0x64, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x49, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x54, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x05,
+	0x54, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x1e, 0x0a, 0x0a, 0x4e, 0x75, 0x6d, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0a, 0x4e, 0x75, 0x6d, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0x7f, 0x0a, 0x0f, 0x4d,
+	0x73, 0x67, 0x50, 0x72, 0x65, 0x64, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x4f, 0x75, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x50, 0x72, 0x6f, 0x62, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x02, 0x52, 0x05, 0x50,
+	0x72, 0x6f, 0x62, 0x73, 0x12, 0x1c, 0x0a, 0x03, 0x45, 0x72, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0a, 0x2e, 0x45, 0x6e, 0x75, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x03, 0x45,
+	0x72, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x4e, 0x75, 0x6d, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x4e, 0x75, 0x6d, 0x43, 0x6c, 0x61, 0x73, 0x73,
+	0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xe1, 0x01, 0x0a,
+	0x07, 0x4d, 0x73, 0x67, 0x49, 0x6e, 0x69, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x61, 0x6d, 0x70,
+	0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x53, 0x61,
+	0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x4e, 0x75, 0x6d, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x4e, 0x75, 0x6d, 0x43, 0x6c, 0x61, 0x73, 0x73,
+	0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x4e, 0x61, 0x6d, 0x65, 0x73,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x4e, 0x61, 0x6d,
+	0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x12, 0x1c,
+	0x0a, 0x09, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x53, 0x70, 0x65, 0x63, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x53, 0x70, 0x65, 0x63, 0x12, 0x22, 0x0a, 0x0c,
+	0x43, 0x6c, 0x61, 0x73, 0x73, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x02, 0x52, 0x0c, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73,
+	0x22, 0x80, 0x02, 0x0a, 0x0c, 0x4d, 0x73, 0x67, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x6e, 0x66,
+	0x6f, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12,
+	0x1e, 0x0a, 0x0a, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0a, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12,
+	0x12, 0x0a, 0x04, 0x4c, 0x6f, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x52, 0x04, 0x4c,
+	0x6f, 0x73, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x41, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x02, 0x52, 0x08, 0x41, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x12,
+	0x18, 0x0a, 0x07, 0x56, 0x61, 0x6c, 0x4c, 0x6f, 0x73, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x02,
+	0x52, 0x07, 0x56, 0x61, 0x6c, 0x4c, 0x6f, 0x73, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x56, 0x61, 0x6c,
+	0x41, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0b,
+	0x56, 0x61, 0x6c, 0x41, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x41,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x41, 0x63, 0x74,
+	0x69, 0x76, 0x65, 0x22, 0x35, 0x0a, 0x0c, 0x4d, 0x73, 0x67, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x4c,
+	0x69, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x06, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x4d, 0x73, 0x67, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x06, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x22, 0x26, 0x0a, 0x0a, 0x4d, 0x73,
+	0x67, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x22, 0x3f, 0x0a, 0x0b, 0x4d, 0x73, 0x67, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x49,
+	0x6e, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x46,
+	0x6f, 0x6c, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x46, 0x6f, 0x6c,
+	0x64, 0x65, 0x72, 0x22, 0x86, 0x01, 0x0a, 0x0c, 0x4d, 0x73, 0x67, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x4f, 0x75, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x46, 0x69, 0x6c,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x46, 0x69,
+	0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x53, 0x69, 0x64, 0x65, 0x63, 0x61, 0x72, 0x46, 0x69, 0x6c,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x53, 0x69, 0x64, 0x65, 0x63, 0x61, 0x72,
+	0x46, 0x69, 0x6c, 0x65, 0x12, 0x1c, 0x0a, 0x03, 0x45, 0x72, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0a, 0x2e, 0x45, 0x6e, 0x75, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x03, 0x45,
+	0x72, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x65, 0x0a, 0x0d,
+	0x4d, 0x73, 0x67, 0x45, 0x76, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1c, 0x0a,
+	0x09, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03,
+	0x52, 0x09, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x03, 0x45,
+	0x72, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x45, 0x6e, 0x75, 0x6d, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x52, 0x03, 0x45, 0x72, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x22, 0x42, 0x0a, 0x08, 0x4d, 0x73, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12,
+	0x1c, 0x0a, 0x03, 0x45, 0x72, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x45,
+	0x6e, 0x75, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x03, 0x45, 0x72, 0x72, 0x12, 0x18, 0x0a,
+	0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x09, 0x0a, 0x07, 0x56, 0x6f, 0x69, 0x64, 0x4d,
+	0x73, 0x67, 0x2a, 0x82, 0x03, 0x0a, 0x09, 0x45, 0x6e, 0x75, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x12, 0x15, 0x0a, 0x11, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4e, 0x4f,
+	0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x45, 0x4e, 0x55, 0x4d, 0x45,
+	0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4e, 0x4f, 0x4f, 0x55, 0x54, 0x44, 0x41, 0x54, 0x41, 0x10, 0x01,
+	0x12, 0x17, 0x0a, 0x13, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4e, 0x4f,
+	0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x4e, 0x55,
+	0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4e, 0x4f, 0x4d, 0x4f, 0x44, 0x45, 0x4c, 0x10, 0x03,
+	0x12, 0x19, 0x0a, 0x15, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4d, 0x4f,
+	0x44, 0x45, 0x4c, 0x41, 0x43, 0x54, 0x49, 0x56, 0x45, 0x10, 0x04, 0x12, 0x17, 0x0a, 0x13, 0x45,
+	0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x42, 0x41, 0x44, 0x53, 0x41, 0x4d, 0x50,
+	0x4c, 0x45, 0x10, 0x05, 0x12, 0x1b, 0x0a, 0x17, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f,
+	0x52, 0x5f, 0x53, 0x48, 0x41, 0x50, 0x45, 0x4d, 0x49, 0x53, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x10,
+	0x06, 0x12, 0x1c, 0x0a, 0x18, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4e,
+	0x4f, 0x54, 0x49, 0x4e, 0x49, 0x54, 0x49, 0x41, 0x4c, 0x49, 0x5a, 0x45, 0x44, 0x10, 0x07, 0x12,
+	0x1c, 0x0a, 0x18, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x54, 0x52, 0x41,
+	0x49, 0x4e, 0x49, 0x4e, 0x47, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x08, 0x12, 0x19, 0x0a,
+	0x15, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4f, 0x55, 0x54, 0x4f, 0x46,
+	0x4d, 0x45, 0x4d, 0x4f, 0x52, 0x59, 0x10, 0x09, 0x12, 0x16, 0x0a, 0x12, 0x45, 0x4e, 0x55, 0x4d,
+	0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x10, 0x0a,
+	0x12, 0x1a, 0x0a, 0x16, 0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x42, 0x41,
+	0x44, 0x4d, 0x4f, 0x44, 0x45, 0x4c, 0x53, 0x50, 0x45, 0x43, 0x10, 0x0b, 0x12, 0x1d, 0x0a, 0x19,
+	0x45, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x57, 0x45, 0x49, 0x47, 0x48, 0x54,
+	0x53, 0x4d, 0x49, 0x53, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x10, 0x0c, 0x12, 0x1a, 0x0a, 0x16, 0x45,
+	0x4e, 0x55, 0x4d, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x45, 0x58, 0x50, 0x4f, 0x52, 0x54, 0x46,
+	0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x0d, 0x32, 0xc0, 0x05, 0x0a, 0x08, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x04, 0x54, 0x65, 0x73, 0x74, 0x12, 0x08, 0x2e, 0x56,
+	0x6f, 0x69, 0x64, 0x4d, 0x73, 0x67, 0x1a, 0x09, 0x2e, 0x4d, 0x73, 0x67, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x22, 0x00, 0x12, 0x20, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x12,
+	0x08, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x4d, 0x73, 0x67, 0x1a, 0x08, 0x2e, 0x56, 0x6f, 0x69, 0x64,
+	0x4d, 0x73, 0x67, 0x22, 0x00, 0x12, 0x26, 0x0a, 0x0b, 0x4f, 0x70, 0x65, 0x6e, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x08, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x4d, 0x73, 0x67, 0x1a, 0x0b,
+	0x2e, 0x4d, 0x73, 0x67, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x00, 0x12, 0x28, 0x0a,
+	0x0c, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0b, 0x2e,
+	0x4d, 0x73, 0x67, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x1a, 0x09, 0x2e, 0x4d, 0x73, 0x67,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x00, 0x12, 0x2f, 0x0a, 0x14, 0x41, 0x70, 0x70, 0x65, 0x6e,
+	0x64, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x12,
+	0x0a, 0x2e, 0x4d, 0x73, 0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x1a, 0x09, 0x2e, 0x4d, 0x73,
+	0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x00, 0x12, 0x33, 0x0a, 0x0b, 0x53, 0x79, 0x6e, 0x63,
+	0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x10, 0x2e, 0x4d, 0x73, 0x67, 0x53, 0x61, 0x6d,
+	0x70, 0x6c, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x1a, 0x10, 0x2e, 0x4d, 0x73, 0x67, 0x53,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0x00, 0x12, 0x33, 0x0a,
+	0x0d, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x0f,
+	0x2e, 0x4d, 0x73, 0x67, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x1a,
+	0x0d, 0x2e, 0x4d, 0x73, 0x67, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x41, 0x63, 0x6b, 0x22, 0x00,
+	0x28, 0x01, 0x12, 0x25, 0x0a, 0x0c, 0x49, 0x6e, 0x69, 0x74, 0x4d, 0x6c, 0x50, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x12, 0x08, 0x2e, 0x4d, 0x73, 0x67, 0x49, 0x6e, 0x69, 0x74, 0x1a, 0x09, 0x2e, 0x4d,
+	0x73, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x05, 0x54, 0x72, 0x61,
+	0x69, 0x6e, 0x12, 0x0b, 0x2e, 0x4d, 0x73, 0x67, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x1a,
+	0x11, 0x2e, 0x4d, 0x73, 0x67, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x22, 0x00, 0x30, 0x01, 0x12, 0x27, 0x0a, 0x0b, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c,
+	0x54, 0x72, 0x61, 0x69, 0x6e, 0x12, 0x0b, 0x2e, 0x4d, 0x73, 0x67, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x1a, 0x09, 0x2e, 0x4d, 0x73, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x00, 0x12,
+	0x33, 0x0a, 0x0c, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12,
+	0x0f, 0x2e, 0x4d, 0x73, 0x67, 0x50, 0x72, 0x65, 0x64, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x49, 0x6e,
+	0x1a, 0x10, 0x2e, 0x4d, 0x73, 0x67, 0x50, 0x72, 0x65, 0x64, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x4f,
+	0x75, 0x74, 0x22, 0x00, 0x12, 0x29, 0x0a, 0x08, 0x45, 0x76, 0x61, 0x6c, 0x75, 0x61, 0x74, 0x65,
+	0x12, 0x0b, 0x2e, 0x4d, 0x73, 0x67, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x1a, 0x0e, 0x2e,
+	0x4d, 0x73, 0x67, 0x45, 0x76, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12,
+	0x27, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x12, 0x08, 0x2e,
+	0x56, 0x6f, 0x69, 0x64, 0x4d, 0x73, 0x67, 0x1a, 0x0d, 0x2e, 0x4d, 0x73, 0x67, 0x4d, 0x6f, 0x64,
+	0x65, 0x6c, 0x4c, 0x69, 0x73, 0x74, 0x22, 0x00, 0x12, 0x29, 0x0a, 0x0d, 0x41, 0x63, 0x74, 0x69,
+	0x76, 0x61, 0x74, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x0b, 0x2e, 0x4d, 0x73, 0x67, 0x4d,
+	0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x1a, 0x09, 0x2e, 0x4d, 0x73, 0x67, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x22, 0x00, 0x12, 0x27, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x6f, 0x64,
+	0x65, 0x6c, 0x12, 0x0b, 0x2e, 0x4d, 0x73, 0x67, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x1a,
+	0x09, 0x2e, 0x4d, 0x73, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x00, 0x12, 0x2c, 0x0a, 0x0b,
+	0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x0c, 0x2e, 0x4d, 0x73,
+	0x67, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x49, 0x6e, 0x1a, 0x0d, 0x2e, 0x4d, 0x73, 0x67, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x2f,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
@@ -459,37 +1551,76 @@ func file_mlserver_proto_rawDescGZIP() []byte {
 }
 
 var file_mlserver_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_mlserver_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_mlserver_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_mlserver_proto_goTypes = []interface{}{
-	(EnumError)(0),     // 0: EnumError
-	(*Message)(nil),    // 1: Message
-	(*MsgSample)(nil),  // 2: MsgSample
-	(*MsgPredIn)(nil),  // 3: MsgPredIn
-	(*MsgPredOut)(nil), // 4: MsgPredOut
-	(*MsgInit)(nil),    // 5: MsgInit
-	(*MsgError)(nil),   // 6: MsgError
-	(*VoidMsg)(nil),    // 7: VoidMsg
+	(EnumError)(0),           // 0: EnumError
+	(*Message)(nil),          // 1: Message
+	(*MsgSession)(nil),       // 2: MsgSession
+	(*MsgSample)(nil),        // 3: MsgSample
+	(*MsgSampleHashes)(nil),  // 4: MsgSampleHashes
+	(*MsgSampleData)(nil),    // 5: MsgSampleData
+	(*MsgSampleChunk)(nil),   // 6: MsgSampleChunk
+	(*MsgUploadAck)(nil),     // 7: MsgUploadAck
+	(*MsgTrainProgress)(nil), // 8: MsgTrainProgress
+	(*MsgPredFrameIn)(nil),   // 9: MsgPredFrameIn
+	(*MsgPredFrameOut)(nil),  // 10: MsgPredFrameOut
+	(*MsgInit)(nil),          // 11: MsgInit
+	(*MsgModelInfo)(nil),     // 12: MsgModelInfo
+	(*MsgModelList)(nil),     // 13: MsgModelList
+	(*MsgModelId)(nil),       // 14: MsgModelId
+	(*MsgExportIn)(nil),      // 15: MsgExportIn
+	(*MsgExportOut)(nil),     // 16: MsgExportOut
+	(*MsgEvalResult)(nil),    // 17: MsgEvalResult
+	(*MsgError)(nil),         // 18: MsgError
+	(*VoidMsg)(nil),          // 19: VoidMsg
 }
 var file_mlserver_proto_depIdxs = []int32{
-	0, // 0: MsgPredOut.Err:type_name -> EnumError
-	0, // 1: MsgError.Err:type_name -> EnumError
-	7, // 2: Messager.Test:input_type -> VoidMsg
-	2, // 3: Messager.AppendTrainingSample:input_type -> MsgSample
-	5, // 4: Messager.InitMlParams:input_type -> MsgInit
-	7, // 5: Messager.Train:input_type -> VoidMsg
-	3, // 6: Messager.AppendPredictSample:input_type -> MsgPredIn
-	7, // 7: Messager.Predict:input_type -> VoidMsg
-	7, // 8: Messager.Test:output_type -> VoidMsg
-	6, // 9: Messager.AppendTrainingSample:output_type -> MsgError
-	6, // 10: Messager.InitMlParams:output_type -> MsgError
-	7, // 11: Messager.Train:output_type -> VoidMsg
-	6, // 12: Messager.AppendPredictSample:output_type -> MsgError
-	4, // 13: Messager.Predict:output_type -> MsgPredOut
-	8, // [8:14] is the sub-list for method output_type
-	2, // [2:8] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	0,  // 0: MsgSampleHashes.Err:type_name -> EnumError
+	5,  // 1: MsgSampleChunk.Samples:type_name -> MsgSampleData
+	0,  // 2: MsgUploadAck.Err:type_name -> EnumError
+	0,  // 3: MsgTrainProgress.Err:type_name -> EnumError
+	0,  // 4: MsgPredFrameOut.Err:type_name -> EnumError
+	12, // 5: MsgModelList.Models:type_name -> MsgModelInfo
+	0,  // 6: MsgExportOut.Err:type_name -> EnumError
+	0,  // 7: MsgEvalResult.Err:type_name -> EnumError
+	0,  // 8: MsgError.Err:type_name -> EnumError
+	19, // 9: Messager.Test:input_type -> VoidMsg
+	19, // 10: Messager.Shutdown:input_type -> VoidMsg
+	19, // 11: Messager.OpenSession:input_type -> VoidMsg
+	2,  // 12: Messager.CloseSession:input_type -> MsgSession
+	3,  // 13: Messager.AppendTrainingSample:input_type -> MsgSample
+	4,  // 14: Messager.SyncSamples:input_type -> MsgSampleHashes
+	6,  // 15: Messager.UploadSamples:input_type -> MsgSampleChunk
+	11, // 16: Messager.InitMlParams:input_type -> MsgInit
+	2,  // 17: Messager.Train:input_type -> MsgSession
+	2,  // 18: Messager.CancelTrain:input_type -> MsgSession
+	9,  // 19: Messager.PredictFrame:input_type -> MsgPredFrameIn
+	2,  // 20: Messager.Evaluate:input_type -> MsgSession
+	19, // 21: Messager.ListModels:input_type -> VoidMsg
+	14, // 22: Messager.ActivateModel:input_type -> MsgModelId
+	14, // 23: Messager.DeleteModel:input_type -> MsgModelId
+	15, // 24: Messager.ExportModel:input_type -> MsgExportIn
+	18, // 25: Messager.Test:output_type -> MsgError
+	19, // 26: Messager.Shutdown:output_type -> VoidMsg
+	2,  // 27: Messager.OpenSession:output_type -> MsgSession
+	18, // 28: Messager.CloseSession:output_type -> MsgError
+	18, // 29: Messager.AppendTrainingSample:output_type -> MsgError
+	4,  // 30: Messager.SyncSamples:output_type -> MsgSampleHashes
+	7,  // 31: Messager.UploadSamples:output_type -> MsgUploadAck
+	18, // 32: Messager.InitMlParams:output_type -> MsgError
+	8,  // 33: Messager.Train:output_type -> MsgTrainProgress
+	18, // 34: Messager.CancelTrain:output_type -> MsgError
+	10, // 35: Messager.PredictFrame:output_type -> MsgPredFrameOut
+	17, // 36: Messager.Evaluate:output_type -> MsgEvalResult
+	13, // 37: Messager.ListModels:output_type -> MsgModelList
+	18, // 38: Messager.ActivateModel:output_type -> MsgError
+	18, // 39: Messager.DeleteModel:output_type -> MsgError
+	16, // 40: Messager.ExportModel:output_type -> MsgExportOut
+	25, // [25:41] is the sub-list for method output_type
+	9,  // [9:25] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_mlserver_proto_init() }
@@ -511,7 +1642,7 @@ func file_mlserver_proto_init() {
 			}
 		}
 		file_mlserver_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MsgSample); i {
+			switch v := v.(*MsgSession); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -523,7 +1654,7 @@ func file_mlserver_proto_init() {
 			}
 		}
 		file_mlserver_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MsgPredIn); i {
+			switch v := v.(*MsgSample); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -535,7 +1666,7 @@ func file_mlserver_proto_init() {
 			}
 		}
 		file_mlserver_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MsgPredOut); i {
+			switch v := v.(*MsgSampleHashes); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -547,7 +1678,7 @@ func file_mlserver_proto_init() {
 			}
 		}
 		file_mlserver_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MsgInit); i {
+			switch v := v.(*MsgSampleData); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -559,7 +1690,7 @@ func file_mlserver_proto_init() {
 			}
 		}
 		file_mlserver_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MsgError); i {
+			switch v := v.(*MsgSampleChunk); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -571,6 +1702,150 @@ func file_mlserver_proto_init() {
 			}
 		}
 		file_mlserver_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgUploadAck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgTrainProgress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgPredFrameIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgPredFrameOut); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgInit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgModelInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgModelList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgModelId); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgExportIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgExportOut); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgEvalResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MsgError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mlserver_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*VoidMsg); i {
 			case 0:
 				return &v.state
@@ -589,7 +1864,7 @@ func file_mlserver_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_mlserver_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   7,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
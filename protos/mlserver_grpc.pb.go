@@ -22,12 +22,22 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MessagerClient interface {
-	Test(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*VoidMsg, error)
+	Test(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*MsgError, error)
+	Shutdown(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*VoidMsg, error)
+	OpenSession(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*MsgSession, error)
+	CloseSession(ctx context.Context, in *MsgSession, opts ...grpc.CallOption) (*MsgError, error)
 	AppendTrainingSample(ctx context.Context, in *MsgSample, opts ...grpc.CallOption) (*MsgError, error)
+	SyncSamples(ctx context.Context, in *MsgSampleHashes, opts ...grpc.CallOption) (*MsgSampleHashes, error)
+	UploadSamples(ctx context.Context, opts ...grpc.CallOption) (Messager_UploadSamplesClient, error)
 	InitMlParams(ctx context.Context, in *MsgInit, opts ...grpc.CallOption) (*MsgError, error)
-	Train(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*VoidMsg, error)
-	AppendPredictSample(ctx context.Context, in *MsgPredIn, opts ...grpc.CallOption) (*MsgError, error)
-	Predict(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*MsgPredOut, error)
+	Train(ctx context.Context, in *MsgSession, opts ...grpc.CallOption) (Messager_TrainClient, error)
+	CancelTrain(ctx context.Context, in *MsgSession, opts ...grpc.CallOption) (*MsgError, error)
+	PredictFrame(ctx context.Context, in *MsgPredFrameIn, opts ...grpc.CallOption) (*MsgPredFrameOut, error)
+	Evaluate(ctx context.Context, in *MsgSession, opts ...grpc.CallOption) (*MsgEvalResult, error)
+	ListModels(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*MsgModelList, error)
+	ActivateModel(ctx context.Context, in *MsgModelId, opts ...grpc.CallOption) (*MsgError, error)
+	DeleteModel(ctx context.Context, in *MsgModelId, opts ...grpc.CallOption) (*MsgError, error)
+	ExportModel(ctx context.Context, in *MsgExportIn, opts ...grpc.CallOption) (*MsgExportOut, error)
 }
 
 type messagerClient struct {
@@ -38,8 +48,8 @@ func NewMessagerClient(cc grpc.ClientConnInterface) MessagerClient {
 	return &messagerClient{cc}
 }
 
-func (c *messagerClient) Test(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*VoidMsg, error) {
-	out := new(VoidMsg)
+func (c *messagerClient) Test(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*MsgError, error) {
+	out := new(MsgError)
 	err := c.cc.Invoke(ctx, "/Messager/Test", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -47,6 +57,33 @@ func (c *messagerClient) Test(ctx context.Context, in *VoidMsg, opts ...grpc.Cal
 	return out, nil
 }
 
+func (c *messagerClient) Shutdown(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*VoidMsg, error) {
+	out := new(VoidMsg)
+	err := c.cc.Invoke(ctx, "/Messager/Shutdown", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagerClient) OpenSession(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*MsgSession, error) {
+	out := new(MsgSession)
+	err := c.cc.Invoke(ctx, "/Messager/OpenSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagerClient) CloseSession(ctx context.Context, in *MsgSession, opts ...grpc.CallOption) (*MsgError, error) {
+	out := new(MsgError)
+	err := c.cc.Invoke(ctx, "/Messager/CloseSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *messagerClient) AppendTrainingSample(ctx context.Context, in *MsgSample, opts ...grpc.CallOption) (*MsgError, error) {
 	out := new(MsgError)
 	err := c.cc.Invoke(ctx, "/Messager/AppendTrainingSample", in, out, opts...)
@@ -56,6 +93,49 @@ func (c *messagerClient) AppendTrainingSample(ctx context.Context, in *MsgSample
 	return out, nil
 }
 
+func (c *messagerClient) SyncSamples(ctx context.Context, in *MsgSampleHashes, opts ...grpc.CallOption) (*MsgSampleHashes, error) {
+	out := new(MsgSampleHashes)
+	err := c.cc.Invoke(ctx, "/Messager/SyncSamples", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagerClient) UploadSamples(ctx context.Context, opts ...grpc.CallOption) (Messager_UploadSamplesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Messager_ServiceDesc.Streams[0], "/Messager/UploadSamples", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &messagerUploadSamplesClient{stream}
+	return x, nil
+}
+
+type Messager_UploadSamplesClient interface {
+	Send(*MsgSampleChunk) error
+	CloseAndRecv() (*MsgUploadAck, error)
+	grpc.ClientStream
+}
+
+type messagerUploadSamplesClient struct {
+	grpc.ClientStream
+}
+
+func (x *messagerUploadSamplesClient) Send(m *MsgSampleChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *messagerUploadSamplesClient) CloseAndRecv() (*MsgUploadAck, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(MsgUploadAck)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *messagerClient) InitMlParams(ctx context.Context, in *MsgInit, opts ...grpc.CallOption) (*MsgError, error) {
 	out := new(MsgError)
 	err := c.cc.Invoke(ctx, "/Messager/InitMlParams", in, out, opts...)
@@ -65,27 +145,95 @@ func (c *messagerClient) InitMlParams(ctx context.Context, in *MsgInit, opts ...
 	return out, nil
 }
 
-func (c *messagerClient) Train(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*VoidMsg, error) {
-	out := new(VoidMsg)
-	err := c.cc.Invoke(ctx, "/Messager/Train", in, out, opts...)
+func (c *messagerClient) Train(ctx context.Context, in *MsgSession, opts ...grpc.CallOption) (Messager_TrainClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Messager_ServiceDesc.Streams[1], "/Messager/Train", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &messagerTrainClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Messager_TrainClient interface {
+	Recv() (*MsgTrainProgress, error)
+	grpc.ClientStream
+}
+
+type messagerTrainClient struct {
+	grpc.ClientStream
+}
+
+func (x *messagerTrainClient) Recv() (*MsgTrainProgress, error) {
+	m := new(MsgTrainProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *messagerClient) CancelTrain(ctx context.Context, in *MsgSession, opts ...grpc.CallOption) (*MsgError, error) {
+	out := new(MsgError)
+	err := c.cc.Invoke(ctx, "/Messager/CancelTrain", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagerClient) PredictFrame(ctx context.Context, in *MsgPredFrameIn, opts ...grpc.CallOption) (*MsgPredFrameOut, error) {
+	out := new(MsgPredFrameOut)
+	err := c.cc.Invoke(ctx, "/Messager/PredictFrame", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *messagerClient) AppendPredictSample(ctx context.Context, in *MsgPredIn, opts ...grpc.CallOption) (*MsgError, error) {
+func (c *messagerClient) Evaluate(ctx context.Context, in *MsgSession, opts ...grpc.CallOption) (*MsgEvalResult, error) {
+	out := new(MsgEvalResult)
+	err := c.cc.Invoke(ctx, "/Messager/Evaluate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagerClient) ListModels(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*MsgModelList, error) {
+	out := new(MsgModelList)
+	err := c.cc.Invoke(ctx, "/Messager/ListModels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagerClient) ActivateModel(ctx context.Context, in *MsgModelId, opts ...grpc.CallOption) (*MsgError, error) {
 	out := new(MsgError)
-	err := c.cc.Invoke(ctx, "/Messager/AppendPredictSample", in, out, opts...)
+	err := c.cc.Invoke(ctx, "/Messager/ActivateModel", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *messagerClient) Predict(ctx context.Context, in *VoidMsg, opts ...grpc.CallOption) (*MsgPredOut, error) {
-	out := new(MsgPredOut)
-	err := c.cc.Invoke(ctx, "/Messager/Predict", in, out, opts...)
+func (c *messagerClient) DeleteModel(ctx context.Context, in *MsgModelId, opts ...grpc.CallOption) (*MsgError, error) {
+	out := new(MsgError)
+	err := c.cc.Invoke(ctx, "/Messager/DeleteModel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagerClient) ExportModel(ctx context.Context, in *MsgExportIn, opts ...grpc.CallOption) (*MsgExportOut, error) {
+	out := new(MsgExportOut)
+	err := c.cc.Invoke(ctx, "/Messager/ExportModel", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -96,12 +244,22 @@ func (c *messagerClient) Predict(ctx context.Context, in *VoidMsg, opts ...grpc.
 // All implementations must embed UnimplementedMessagerServer
 // for forward compatibility
 type MessagerServer interface {
-	Test(context.Context, *VoidMsg) (*VoidMsg, error)
+	Test(context.Context, *VoidMsg) (*MsgError, error)
+	Shutdown(context.Context, *VoidMsg) (*VoidMsg, error)
+	OpenSession(context.Context, *VoidMsg) (*MsgSession, error)
+	CloseSession(context.Context, *MsgSession) (*MsgError, error)
 	AppendTrainingSample(context.Context, *MsgSample) (*MsgError, error)
+	SyncSamples(context.Context, *MsgSampleHashes) (*MsgSampleHashes, error)
+	UploadSamples(Messager_UploadSamplesServer) error
 	InitMlParams(context.Context, *MsgInit) (*MsgError, error)
-	Train(context.Context, *VoidMsg) (*VoidMsg, error)
-	AppendPredictSample(context.Context, *MsgPredIn) (*MsgError, error)
-	Predict(context.Context, *VoidMsg) (*MsgPredOut, error)
+	Train(*MsgSession, Messager_TrainServer) error
+	CancelTrain(context.Context, *MsgSession) (*MsgError, error)
+	PredictFrame(context.Context, *MsgPredFrameIn) (*MsgPredFrameOut, error)
+	Evaluate(context.Context, *MsgSession) (*MsgEvalResult, error)
+	ListModels(context.Context, *VoidMsg) (*MsgModelList, error)
+	ActivateModel(context.Context, *MsgModelId) (*MsgError, error)
+	DeleteModel(context.Context, *MsgModelId) (*MsgError, error)
+	ExportModel(context.Context, *MsgExportIn) (*MsgExportOut, error)
 	mustEmbedUnimplementedMessagerServer()
 }
 
@@ -109,23 +267,53 @@ type MessagerServer interface {
 type UnimplementedMessagerServer struct {
 }
 
-func (UnimplementedMessagerServer) Test(context.Context, *VoidMsg) (*VoidMsg, error) {
+func (UnimplementedMessagerServer) Test(context.Context, *VoidMsg) (*MsgError, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Test not implemented")
 }
+func (UnimplementedMessagerServer) Shutdown(context.Context, *VoidMsg) (*VoidMsg, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shutdown not implemented")
+}
+func (UnimplementedMessagerServer) OpenSession(context.Context, *VoidMsg) (*MsgSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OpenSession not implemented")
+}
+func (UnimplementedMessagerServer) CloseSession(context.Context, *MsgSession) (*MsgError, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseSession not implemented")
+}
 func (UnimplementedMessagerServer) AppendTrainingSample(context.Context, *MsgSample) (*MsgError, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AppendTrainingSample not implemented")
 }
+func (UnimplementedMessagerServer) SyncSamples(context.Context, *MsgSampleHashes) (*MsgSampleHashes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncSamples not implemented")
+}
+func (UnimplementedMessagerServer) UploadSamples(Messager_UploadSamplesServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadSamples not implemented")
+}
 func (UnimplementedMessagerServer) InitMlParams(context.Context, *MsgInit) (*MsgError, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InitMlParams not implemented")
 }
-func (UnimplementedMessagerServer) Train(context.Context, *VoidMsg) (*VoidMsg, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Train not implemented")
+func (UnimplementedMessagerServer) Train(*MsgSession, Messager_TrainServer) error {
+	return status.Errorf(codes.Unimplemented, "method Train not implemented")
+}
+func (UnimplementedMessagerServer) CancelTrain(context.Context, *MsgSession) (*MsgError, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelTrain not implemented")
+}
+func (UnimplementedMessagerServer) PredictFrame(context.Context, *MsgPredFrameIn) (*MsgPredFrameOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PredictFrame not implemented")
+}
+func (UnimplementedMessagerServer) Evaluate(context.Context, *MsgSession) (*MsgEvalResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Evaluate not implemented")
 }
-func (UnimplementedMessagerServer) AppendPredictSample(context.Context, *MsgPredIn) (*MsgError, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method AppendPredictSample not implemented")
+func (UnimplementedMessagerServer) ListModels(context.Context, *VoidMsg) (*MsgModelList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListModels not implemented")
 }
-func (UnimplementedMessagerServer) Predict(context.Context, *VoidMsg) (*MsgPredOut, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Predict not implemented")
+func (UnimplementedMessagerServer) ActivateModel(context.Context, *MsgModelId) (*MsgError, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActivateModel not implemented")
+}
+func (UnimplementedMessagerServer) DeleteModel(context.Context, *MsgModelId) (*MsgError, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteModel not implemented")
+}
+func (UnimplementedMessagerServer) ExportModel(context.Context, *MsgExportIn) (*MsgExportOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportModel not implemented")
 }
 func (UnimplementedMessagerServer) mustEmbedUnimplementedMessagerServer() {}
 
@@ -158,6 +346,60 @@ func _Messager_Test_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Messager_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoidMsg)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagerServer).Shutdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Messager/Shutdown",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagerServer).Shutdown(ctx, req.(*VoidMsg))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messager_OpenSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoidMsg)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagerServer).OpenSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Messager/OpenSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagerServer).OpenSession(ctx, req.(*VoidMsg))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messager_CloseSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgSession)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagerServer).CloseSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Messager/CloseSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagerServer).CloseSession(ctx, req.(*MsgSession))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Messager_AppendTrainingSample_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(MsgSample)
 	if err := dec(in); err != nil {
@@ -176,6 +418,50 @@ func _Messager_AppendTrainingSample_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Messager_SyncSamples_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgSampleHashes)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagerServer).SyncSamples(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Messager/SyncSamples",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagerServer).SyncSamples(ctx, req.(*MsgSampleHashes))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messager_UploadSamples_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MessagerServer).UploadSamples(&messagerUploadSamplesServer{stream})
+}
+
+type Messager_UploadSamplesServer interface {
+	SendAndClose(*MsgUploadAck) error
+	Recv() (*MsgSampleChunk, error)
+	grpc.ServerStream
+}
+
+type messagerUploadSamplesServer struct {
+	grpc.ServerStream
+}
+
+func (x *messagerUploadSamplesServer) SendAndClose(m *MsgUploadAck) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *messagerUploadSamplesServer) Recv() (*MsgSampleChunk, error) {
+	m := new(MsgSampleChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Messager_InitMlParams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(MsgInit)
 	if err := dec(in); err != nil {
@@ -194,56 +480,149 @@ func _Messager_InitMlParams_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Messager_Train_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VoidMsg)
+func _Messager_Train_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(MsgSession)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MessagerServer).Train(m, &messagerTrainServer{stream})
+}
+
+type Messager_TrainServer interface {
+	Send(*MsgTrainProgress) error
+	grpc.ServerStream
+}
+
+type messagerTrainServer struct {
+	grpc.ServerStream
+}
+
+func (x *messagerTrainServer) Send(m *MsgTrainProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Messager_CancelTrain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgSession)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagerServer).CancelTrain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Messager/CancelTrain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagerServer).CancelTrain(ctx, req.(*MsgSession))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messager_PredictFrame_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgPredFrameIn)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MessagerServer).Train(ctx, in)
+		return srv.(MessagerServer).PredictFrame(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/Messager/Train",
+		FullMethod: "/Messager/PredictFrame",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MessagerServer).Train(ctx, req.(*VoidMsg))
+		return srv.(MessagerServer).PredictFrame(ctx, req.(*MsgPredFrameIn))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Messager_AppendPredictSample_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MsgPredIn)
+func _Messager_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgSession)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MessagerServer).AppendPredictSample(ctx, in)
+		return srv.(MessagerServer).Evaluate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/Messager/AppendPredictSample",
+		FullMethod: "/Messager/Evaluate",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MessagerServer).AppendPredictSample(ctx, req.(*MsgPredIn))
+		return srv.(MessagerServer).Evaluate(ctx, req.(*MsgSession))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Messager_Predict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _Messager_ListModels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VoidMsg)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MessagerServer).Predict(ctx, in)
+		return srv.(MessagerServer).ListModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Messager/ListModels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagerServer).ListModels(ctx, req.(*VoidMsg))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messager_ActivateModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgModelId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagerServer).ActivateModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Messager/ActivateModel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagerServer).ActivateModel(ctx, req.(*MsgModelId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messager_DeleteModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgModelId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagerServer).DeleteModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Messager/DeleteModel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagerServer).DeleteModel(ctx, req.(*MsgModelId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messager_ExportModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MsgExportIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagerServer).ExportModel(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/Messager/Predict",
+		FullMethod: "/Messager/ExportModel",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MessagerServer).Predict(ctx, req.(*VoidMsg))
+		return srv.(MessagerServer).ExportModel(ctx, req.(*MsgExportIn))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -259,27 +638,70 @@ var Messager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Test",
 			Handler:    _Messager_Test_Handler,
 		},
+		{
+			MethodName: "Shutdown",
+			Handler:    _Messager_Shutdown_Handler,
+		},
+		{
+			MethodName: "OpenSession",
+			Handler:    _Messager_OpenSession_Handler,
+		},
+		{
+			MethodName: "CloseSession",
+			Handler:    _Messager_CloseSession_Handler,
+		},
 		{
 			MethodName: "AppendTrainingSample",
 			Handler:    _Messager_AppendTrainingSample_Handler,
 		},
+		{
+			MethodName: "SyncSamples",
+			Handler:    _Messager_SyncSamples_Handler,
+		},
 		{
 			MethodName: "InitMlParams",
 			Handler:    _Messager_InitMlParams_Handler,
 		},
 		{
-			MethodName: "Train",
-			Handler:    _Messager_Train_Handler,
+			MethodName: "CancelTrain",
+			Handler:    _Messager_CancelTrain_Handler,
+		},
+		{
+			MethodName: "PredictFrame",
+			Handler:    _Messager_PredictFrame_Handler,
+		},
+		{
+			MethodName: "Evaluate",
+			Handler:    _Messager_Evaluate_Handler,
+		},
+		{
+			MethodName: "ListModels",
+			Handler:    _Messager_ListModels_Handler,
 		},
 		{
-			MethodName: "AppendPredictSample",
-			Handler:    _Messager_AppendPredictSample_Handler,
+			MethodName: "ActivateModel",
+			Handler:    _Messager_ActivateModel_Handler,
+		},
+		{
+			MethodName: "DeleteModel",
+			Handler:    _Messager_DeleteModel_Handler,
+		},
+		{
+			MethodName: "ExportModel",
+			Handler:    _Messager_ExportModel_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadSamples",
+			Handler:       _Messager_UploadSamples_Handler,
+			ClientStreams: true,
 		},
 		{
-			MethodName: "Predict",
-			Handler:    _Messager_Predict_Handler,
+			StreamName:    "Train",
+			Handler:       _Messager_Train_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "mlserver.proto",
 }
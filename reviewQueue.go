@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The review queue collects live frames with tiles the model is unsure
+// about: the most probable non-background label scores inside
+// [ReviewQueueMin, ReviewQueueMax]. Every frame is saved as <base>.png with
+// a <base>.json listing those tiles, the markup screen walks the queue and
+// outlines them.
+const fileReviewQueueFolder = "reviewqueue"
+
+type ReviewTile struct {
+	Index int             `json:"Index"`
+	Rect  image.Rectangle `json:"Rect"`
+	Class int             `json:"Class"`
+	Score float64         `json:"Score"`
+}
+
+type ReviewEntry struct {
+	Frame   string       `json:"Frame"`
+	Created string       `json:"Created"`
+	Tiles   []ReviewTile `json:"Tiles"`
+}
+
+type ReviewQueueStruct struct {
+	mut      sync.Mutex
+	lastSave time.Time
+	saving   bool
+}
+
+var ReviewQueue ReviewQueueStruct
+
+// Collect queues frame when some of its tiles are uncertain. It saves at most
+// one frame per ReviewQueuePeriod and stops at ReviewQueueMaxFrames.
+func (m *ReviewQueueStruct) Collect(frame *image.RGBA, rects []*image.Rectangle, predicted []*SelectedData) {
+	c := &Config.Common
+	if !c.ReviewQueue {
+		return
+	}
+	m.mut.Lock()
+	if m.saving || time.Since(m.lastSave) < c.reviewQueuePeriod {
+		m.mut.Unlock()
+		return
+	}
+	tiles := make([]ReviewTile, 0)
+	for i, d := range predicted {
+		if d.Value >= c.ReviewQueueMin && d.Value <= c.ReviewQueueMax {
+			tiles = append(tiles, ReviewTile{Index: i, Rect: *rects[i], Class: d.Class, Score: d.Value})
+		}
+	}
+	if len(tiles) == 0 {
+		m.mut.Unlock()
+		return
+	}
+	m.saving = true
+	m.lastSave = time.Now()
+	m.mut.Unlock()
+
+	go func() {
+		defer func() {
+			m.mut.Lock()
+			m.saving = false
+			m.mut.Unlock()
+		}()
+		if c.ReviewQueueMaxFrames > 0 && len(m.List()) >= c.ReviewQueueMaxFrames {
+			return
+		}
+		if err := m.save(frame, tiles); err != nil {
+			log.Println(err)
+			GuiTextView.PutString(err.Error())
+		}
+	}()
+}
+
+func (m *ReviewQueueStruct) save(frame *image.RGBA, tiles []ReviewTile) error {
+	err := os.MkdirAll(Dataset.Path(fileReviewQueueFolder), os.ModeDir)
+	if err != nil {
+		return fmt.Errorf("ReviewQueue save error: %v", err)
+	}
+	base := File.CreateBaseName()
+	e := ReviewEntry{
+		Frame:   fmt.Sprintf("%v.%v", base, fileScreenshotDataSuffix),
+		Created: time.Now().Format("2006-01-02 15:04:05"),
+		Tiles:   tiles,
+	}
+	data, err := json.MarshalIndent(&e, "", "    ")
+	if err != nil {
+		return fmt.Errorf("ReviewQueue save error: %v", err)
+	}
+	// The frame goes first, List only sees frames with an entry.
+	err = File.SaveImage(frame, Dataset.Path(fileReviewQueueFolder, e.Frame))
+	if err != nil {
+		return fmt.Errorf("ReviewQueue save error: %v", err)
+	}
+	err = os.WriteFile(Dataset.Path(fileReviewQueueFolder, base+".json"), data, 0644)
+	if err != nil {
+		return fmt.Errorf("ReviewQueue save error: %v", err)
+	}
+	return nil
+}
+
+// List returns the queued frames, oldest first.
+func (m *ReviewQueueStruct) List() []string {
+	s := make([]string, 0)
+	filepath.Walk(Dataset.Path(fileReviewQueueFolder),
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && filepath.Ext(path) == "."+fileScreenshotDataSuffix {
+				if _, err := os.Stat(m.entryFile(path)); err == nil {
+					s = append(s, path)
+				}
+			}
+			return nil
+		})
+	return s
+}
+
+func (m *ReviewQueueStruct) Load(framePath string) (*ReviewEntry, error) {
+	data, err := os.ReadFile(m.entryFile(framePath))
+	if err != nil {
+		return nil, fmt.Errorf("ReviewQueue load error: %v", err)
+	}
+	e := &ReviewEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("ReviewQueue load error: %v", err)
+	}
+	return e, nil
+}
+
+// Delete removes a reviewed frame and its entry.
+func (m *ReviewQueueStruct) Delete(framePath string) error {
+	if err := os.Remove(m.entryFile(framePath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ReviewQueue delete error: %v", err)
+	}
+	return File.DeleteFile(framePath)
+}
+
+func (m *ReviewQueueStruct) entryFile(framePath string) string {
+	return strings.TrimSuffix(framePath, filepath.Ext(framePath)) + ".json"
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Rules of config.txt watch the live predictions of the main screen. A rule
+// holds while its condition is met on every frame, it fires once it held for
+// MinDuration and then not again until the condition clears and Cooldown
+// passed, so a detection makes one event instead of one per frame.
+const (
+	RuleActionLog     = "log"
+	RuleActionCommand = "command"
+	RuleActionFrame   = "frame"
+	RuleActionSound   = "sound"
+)
+
+type RuleEvent struct {
+	Time  string    `json:"Time"`
+	Rule  string    `json:"Rule"`
+	Label string    `json:"Label"`
+	Tiles []int     `json:"Tiles"`
+	Score []float64 `json:"Score"`
+	Frame string    `json:"Frame,omitempty"`
+}
+
+type ruleState struct {
+	since time.Time
+	fired bool
+	last  time.Time
+}
+
+type RulesStruct struct {
+	mut    sync.Mutex
+	states []ruleState
+	logMut sync.Mutex
+}
+
+var Rules RulesStruct
+
+// Check takes the frame, the source rects of its tiles and their
+// probabilities and fires the rules that are due.
+func (m *RulesStruct) Check(frame *image.RGBA, rects []*image.Rectangle, probs [][]float64) {
+	if len(Config.Rules) == 0 {
+		return
+	}
+	now := time.Now()
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if len(m.states) != len(Config.Rules) {
+		m.states = make([]ruleState, len(Config.Rules))
+	}
+	for i := range Config.Rules {
+		r := &Config.Rules[i]
+		s := &m.states[i]
+		tiles, scores := m.match(r, rects, probs)
+		if len(tiles) == 0 {
+			s.since = time.Time{}
+			s.fired = false
+			continue
+		}
+		if s.since.IsZero() {
+			s.since = now
+		}
+		if s.fired || now.Sub(s.since) < r.minDuration || (!s.last.IsZero() && now.Sub(s.last) < r.cooldown) {
+			continue
+		}
+		s.fired = true
+		s.last = now
+		e := &RuleEvent{Time: now.Format("2006-01-02 15:04:05.000"), Rule: r.Name, Label: r.Label, Tiles: tiles, Score: scores}
+		go m.fire(r, e, frame)
+	}
+}
+
+func (m *RulesStruct) match(r *ConfigRule, rects []*image.Rectangle, probs [][]float64) ([]int, []float64) {
+	tiles := make([]int, 0)
+	scores := make([]float64, 0)
+	for i, p := range probs {
+		if r.class >= len(p) || p[r.class] < r.Threshold {
+			continue
+		}
+		if len(r.Tiles) != 0 || r.Region != nil {
+			in := false
+			for _, t := range r.Tiles {
+				in = in || t == i
+			}
+			if r.Region != nil && i < len(rects) {
+				c := rects[i].Min.Add(rects[i].Max).Div(2)
+				in = in || c.In(*r.Region)
+			}
+			if !in {
+				continue
+			}
+		}
+		tiles = append(tiles, i)
+		scores = append(scores, p[r.class])
+	}
+	return tiles, scores
+}
+
+// fire runs the actions in order, the frame is saved first so the others
+// can refer to it.
+func (m *RulesStruct) fire(r *ConfigRule, e *RuleEvent, frame *image.RGBA) {
+	log.Printf("Rule %v fired on tiles %v", e.Rule, e.Tiles)
+	for _, a := range r.Actions {
+		if a.Type == RuleActionFrame && frame != nil {
+			if err := os.MkdirAll(a.Folder, os.ModeDir); err != nil {
+				log.Println(fmt.Errorf("rule %v error: %v", r.Name, err))
+				continue
+			}
+			p := filepath.Join(a.Folder, fmt.Sprintf("%v.%v", File.CreateBaseName(), fileScreenshotDataSuffix))
+			if err := File.encodeImage(frame, p); err != nil {
+				log.Println(fmt.Errorf("rule %v error: %v", r.Name, err))
+				continue
+			}
+			e.Frame = p
+		}
+	}
+	for _, a := range r.Actions {
+		var err error
+		switch a.Type {
+		case RuleActionLog:
+			err = m.appendLog(a.File, e)
+		case RuleActionCommand:
+			err = m.run(a, e)
+		case RuleActionSound:
+			err = m.play(a.File)
+		}
+		if err != nil {
+			log.Println(fmt.Errorf("rule %v error: %v", r.Name, err))
+			GuiTextView.PutString(fmt.Sprintf("rule %v error: %v", r.Name, err))
+		}
+	}
+}
+
+func (m *RulesStruct) appendLog(fileName string, e *RuleEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	m.logMut.Lock()
+	defer m.logMut.Unlock()
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// run starts the command without a shell and does not wait for it.
+func (m *RulesStruct) run(a ConfigRuleAction, e *RuleEvent) error {
+	tiles := make([]string, len(e.Tiles))
+	scores := make([]string, len(e.Score))
+	for i := range e.Tiles {
+		tiles[i] = fmt.Sprint(e.Tiles[i])
+		scores[i] = fmt.Sprintf("%.3f", e.Score[i])
+	}
+	replacer := strings.NewReplacer(
+		"{rule}", e.Rule,
+		"{label}", e.Label,
+		"{score}", strings.Join(scores, ","),
+		"{tiles}", strings.Join(tiles, ","),
+		"{frame}", e.Frame,
+		"{time}", e.Time,
+	)
+	args := make([]string, len(a.Args))
+	for i, x := range a.Args {
+		args[i] = replacer.Replace(x)
+	}
+	cmd := exec.Command(a.Command, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// play queues the WAV file on a new audio device and closes it when done.
+func (m *RulesStruct) play(fileName string) error {
+	data, spec := sdl.LoadWAV(fileName)
+	if spec == nil {
+		return fmt.Errorf("%v: %v", fileName, sdl.GetError())
+	}
+	defer sdl.FreeWAV(data)
+	dev, err := sdl.OpenAudioDevice("", false, spec, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer sdl.CloseAudioDevice(dev)
+	if err := sdl.QueueAudio(dev, data); err != nil {
+		return err
+	}
+	sdl.PauseAudioDevice(dev, false)
+	for sdl.GetQueuedAudioSize(dev) > 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
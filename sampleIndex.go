@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Every marked data folder keeps an append-only manifest, index.jsonl, with
+// a record per change of a sample: "put" with its metadata, "relabel" and
+// "delete". The last record of a file wins, so relabelling does not rename
+// it. The label in the file name is the one it was saved with and is only
+// used for files the manifest does not know, see File.MigrateMarkedIndex.
+const sampleIndexFileName = "index.jsonl"
+
+const (
+	sampleIndexPut     = "put"
+	sampleIndexRelabel = "relabel"
+	sampleIndexDelete  = "delete"
+)
+
+// SampleMeta is what is known about a marked sample. Grid is the number of
+// columns and rows of the source frame, Frame its size in pixels. Import is
+// the file an imported sample was made of.
+type SampleMeta struct {
+	File     string          `json:"File"`
+	Class    int             `json:"Class"`
+	Index    int             `json:"Index"`
+	Source   string          `json:"Source"`
+	Window   string          `json:"Window,omitempty"`
+	Rect     image.Rectangle `json:"Rect"`
+	Grid     image.Point     `json:"Grid"`
+	Frame    image.Point     `json:"Frame"`
+	Labeler  string          `json:"Labeler,omitempty"`
+	Time     string          `json:"Time"`
+	Migrated bool            `json:"Migrated,omitempty"`
+	Import   string          `json:"Import,omitempty"`
+}
+
+type sampleIndexRecord struct {
+	Op string `json:"Op"`
+	SampleMeta
+}
+
+type SampleIndexStruct struct {
+	mut     sync.Mutex
+	folders map[string]map[string]*SampleMeta
+}
+
+var SampleIndex SampleIndexStruct
+
+// folder returns the samples of dir by file name, the manifest is read on
+// first use. The caller holds mut.
+func (m *SampleIndexStruct) folder(dir string) map[string]*SampleMeta {
+	dir = filepath.Clean(dir)
+	if m.folders == nil {
+		m.folders = make(map[string]map[string]*SampleMeta)
+	}
+	if f, ok := m.folders[dir]; ok {
+		return f
+	}
+	f := make(map[string]*SampleMeta)
+	m.folders[dir] = f
+	data, err := os.ReadFile(filepath.Join(dir, sampleIndexFileName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println(fmt.Errorf("SampleIndex load error: %v", err))
+		}
+		return f
+	}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; s.Scan(); n++ {
+		r := sampleIndexRecord{}
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			// A line cut by a crash, the records before it hold.
+			log.Println(fmt.Errorf("SampleIndex load error: %v line %d: %v", dir, n, err))
+			continue
+		}
+		m.apply(f, &r)
+	}
+	return f
+}
+
+func (m *SampleIndexStruct) apply(f map[string]*SampleMeta, r *sampleIndexRecord) {
+	switch r.Op {
+	case sampleIndexPut:
+		meta := r.SampleMeta
+		f[r.File] = &meta
+	case sampleIndexRelabel:
+		if meta, ok := f[r.File]; ok {
+			meta.Class = r.Class
+			meta.Labeler = r.Labeler
+			meta.Time = r.Time
+		}
+	case sampleIndexDelete:
+		delete(f, r.File)
+	}
+}
+
+// write appends the records to the manifest of dir and applies them. The
+// caller holds mut.
+func (m *SampleIndexStruct) write(dir string, records ...*sampleIndexRecord) error {
+	f := m.folder(dir)
+	b := bytes.Buffer{}
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("SampleIndex write error: %v", err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	fl, err := os.OpenFile(filepath.Join(dir, sampleIndexFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("SampleIndex write error: %v", err)
+	}
+	defer fl.Close()
+	if _, err := fl.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("SampleIndex write error: %v", err)
+	}
+	for _, r := range records {
+		m.apply(f, r)
+	}
+	return nil
+}
+
+// Get returns a copy of the metadata of the sample at path.
+func (m *SampleIndexStruct) Get(path string) (*SampleMeta, bool) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	meta, ok := m.folder(filepath.Dir(path))[filepath.Base(path)]
+	if !ok {
+		return nil, false
+	}
+	x := *meta
+	return &x, true
+}
+
+func (m *SampleIndexStruct) Put(path string, meta *SampleMeta) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	r := &sampleIndexRecord{Op: sampleIndexPut, SampleMeta: *meta}
+	r.File = filepath.Base(path)
+	return m.write(filepath.Dir(path), r)
+}
+
+func (m *SampleIndexStruct) Relabel(path string, class int, labeler string, time string) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if _, ok := m.folder(filepath.Dir(path))[filepath.Base(path)]; !ok {
+		return fmt.Errorf("SampleIndex relabel error: %v is not indexed", path)
+	}
+	r := &sampleIndexRecord{Op: sampleIndexRelabel, SampleMeta: SampleMeta{File: filepath.Base(path), Class: class, Labeler: labeler, Time: time}}
+	return m.write(filepath.Dir(path), r)
+}
+
+// Forget drops the sample at path, a file the index does not know is fine.
+func (m *SampleIndexStruct) Forget(path string) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if _, ok := m.folder(filepath.Dir(path))[filepath.Base(path)]; !ok {
+		return nil
+	}
+	r := &sampleIndexRecord{Op: sampleIndexDelete, SampleMeta: SampleMeta{File: filepath.Base(path)}}
+	return m.write(filepath.Dir(path), r)
+}
+
+// Move carries the metadata of a sample moved from one folder to another.
+func (m *SampleIndexStruct) Move(from string, to string) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	meta, ok := m.folder(filepath.Dir(from))[filepath.Base(from)]
+	if !ok {
+		return nil
+	}
+	r := &sampleIndexRecord{Op: sampleIndexPut, SampleMeta: *meta}
+	r.File = filepath.Base(to)
+	if err := m.write(filepath.Dir(to), r); err != nil {
+		return err
+	}
+	return m.write(filepath.Dir(from), &sampleIndexRecord{Op: sampleIndexDelete, SampleMeta: SampleMeta{File: filepath.Base(from)}})
+}
+
+// Compact rewrites the manifest of dir with one put record per sample.
+func (m *SampleIndexStruct) Compact(dir string) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	f := m.folder(dir)
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b := bytes.Buffer{}
+	for _, name := range names {
+		data, err := json.Marshal(&sampleIndexRecord{Op: sampleIndexPut, SampleMeta: *f[name]})
+		if err != nil {
+			return fmt.Errorf("SampleIndex compact error: %v", err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	tmp := filepath.Join(dir, sampleIndexFileName+".tmp")
+	if err := os.WriteFile(tmp, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("SampleIndex compact error: %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, sampleIndexFileName)); err != nil {
+		return fmt.Errorf("SampleIndex compact error: %v", err)
+	}
+	return nil
+}
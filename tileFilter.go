@@ -0,0 +1,142 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// TileFilter steadies the live predictions before they light up tiles. The
+// "ema" mode averages the probabilities of a tile over the frames, "vote"
+// switches a tile after VoteCount of its last VoteWindow frames agree. Either
+// way a dark tile lights up at OnThreshold and a lit one goes dark below
+// OffThreshold, so a score hovering around one threshold does not flicker.
+const (
+	TileFilterOff  = "off"
+	TileFilterEMA  = "ema"
+	TileFilterVote = "vote"
+)
+
+type tileVote struct {
+	on    bool
+	class int
+	score float64
+}
+
+type tileState struct {
+	probs   []float64
+	votes   []tileVote
+	on      bool
+	class   int
+	score   float64
+	changed time.Time
+}
+
+type TileFilterStruct struct {
+	mut   sync.Mutex
+	tiles []tileState
+}
+
+var TileFilter TileFilterStruct
+
+func (m *TileFilterStruct) Reset() {
+	m.mut.Lock()
+	m.tiles = nil
+	m.mut.Unlock()
+}
+
+// Filter takes the probabilities of every tile of a frame and returns the
+// lit tiles by index. A new grid starts the filter over.
+func (m *TileFilterStruct) Filter(probs [][]float64) map[int]*SelectedData {
+	s := &Config.Smoothing
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if len(m.tiles) != len(probs) {
+		m.tiles = make([]tileState, len(probs))
+	}
+	now := time.Now()
+	lit := make(map[int]*SelectedData)
+	for i, p := range probs {
+		t := &m.tiles[i]
+		if s.Mode == TileFilterEMA {
+			if len(t.probs) != len(p) {
+				t.probs = append([]float64{}, p...)
+			} else {
+				for c := range p {
+					t.probs[c] = s.Alpha*p[c] + (1-s.Alpha)*t.probs[c]
+				}
+			}
+			p = t.probs
+		}
+		d := MlTopLabel(p)
+		on, class, score := m.hysteresis(t.on, d.Value), d.Class, d.Value
+		if s.Mode == TileFilterVote {
+			on, class, score = m.vote(t, tileVote{on: on, class: d.Class, score: d.Value})
+		}
+		if on != t.on || (on && class != t.class) {
+			t.changed = now
+		}
+		t.on, t.class, t.score = on, class, score
+		if on {
+			lit[i] = &SelectedData{Value: score, Class: class}
+		}
+	}
+	return lit
+}
+
+// Changed returns the tiles that lit up, went dark or changed the label in
+// the last ChangedPeriod.
+func (m *TileFilterStruct) Changed() []int {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	res := make([]int, 0)
+	for i, t := range m.tiles {
+		if !t.changed.IsZero() && time.Since(t.changed) < Config.Smoothing.changedPeriod {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func (m *TileFilterStruct) hysteresis(on bool, score float64) bool {
+	if on {
+		return score >= Config.Smoothing.OffThreshold
+	}
+	return score >= Config.Smoothing.OnThreshold
+}
+
+// vote keeps the last VoteWindow votes of t. The tile switches when
+// VoteCount of them are for the other state, it shows the mean score and the
+// label most of the lit votes are for.
+func (m *TileFilterStruct) vote(t *tileState, v tileVote) (bool, int, float64) {
+	s := &Config.Smoothing
+	t.votes = append(t.votes, v)
+	if len(t.votes) > s.VoteWindow {
+		t.votes = t.votes[len(t.votes)-s.VoteWindow:]
+	}
+	lit := 0
+	score := 0.0
+	classes := make(map[int]int)
+	for _, x := range t.votes {
+		score += x.score
+		if x.on {
+			lit++
+			classes[x.class]++
+		}
+	}
+	score /= float64(len(t.votes))
+	on := t.on
+	if !on && lit >= s.VoteCount {
+		on = true
+	} else if on && len(t.votes)-lit >= s.VoteCount {
+		on = false
+	}
+	// The labels go in order, so a tie of two other labels does not depend
+	// on the map order and flicker between frames.
+	class := v.class
+	for c := 0; c < len(Config.Labels); c++ {
+		if n := classes[c]; n > classes[class] || (n == classes[class] && c == t.class) {
+			class = c
+		}
+	}
+	return on, class, score
+}
@@ -0,0 +1,109 @@
+package main
+
+import "testing"
+
+func TestMlTopLabel(t *testing.T) {
+	tests := []struct {
+		probs []float64
+		want  SelectedData
+	}{
+		// The background label never wins, however probable.
+		{[]float64{0.9, 0.04, 0.06}, SelectedData{Value: 0.06, Class: 2}},
+		{[]float64{0.1, 0.5, 0.4}, SelectedData{Value: 0.5, Class: 1}},
+		{[]float64{0.2, 0.4, 0.4}, SelectedData{Value: 0.4, Class: 1}},
+		{[]float64{1, 0}, SelectedData{Value: 0, Class: 1}},
+	}
+	for _, tt := range tests {
+		if got := MlTopLabel(tt.probs); *got != tt.want {
+			t.Errorf("MlTopLabel(%v) = %v, want %v", tt.probs, *got, tt.want)
+		}
+	}
+}
+
+func tileFilterTestSetup(t *testing.T, s ConfigSmoothing) {
+	t.Helper()
+	old := Config.Smoothing
+	t.Cleanup(func() {
+		Config.Smoothing = old
+		TileFilter.Reset()
+	})
+	Config.Smoothing = s
+	TileFilter.Reset()
+}
+
+func TestTileFilterHysteresis(t *testing.T) {
+	tileFilterTestSetup(t, ConfigSmoothing{Mode: TileFilterOff, OnThreshold: 0.8, OffThreshold: 0.5})
+	steps := []struct {
+		score float64
+		lit   bool
+	}{
+		{0.7, false}, // below OnThreshold
+		{0.8, true},  // lights up at OnThreshold
+		{0.6, true},  // stays lit above OffThreshold
+		{0.5, true},
+		{0.49, false}, // goes dark below OffThreshold
+		{0.7, false},
+	}
+	for i, s := range steps {
+		lit := TileFilter.Filter([][]float64{{1 - s.score, s.score}})
+		if d, ok := lit[0]; ok != s.lit {
+			t.Fatalf("step %d score %v: lit %v, want %v", i, s.score, ok, s.lit)
+		} else if ok && (d.Class != 1 || d.Value != s.score) {
+			t.Fatalf("step %d: got %v", i, *d)
+		}
+	}
+}
+
+func TestTileFilterVote(t *testing.T) {
+	tileFilterTestSetup(t, ConfigSmoothing{Mode: TileFilterVote, OnThreshold: 0.5, OffThreshold: 0.5, VoteWindow: 3, VoteCount: 2})
+	scores := []float64{0.9, 0.1, 0.9, 0.9, 0.1, 0.1}
+	want := []bool{false, false, true, true, true, false}
+	for i, s := range scores {
+		_, lit := TileFilter.Filter([][]float64{{1 - s, s}})[0]
+		if lit != want[i] {
+			t.Fatalf("frame %d: lit %v, want %v", i, lit, want[i])
+		}
+	}
+}
+
+func TestTileFilterNewGrid(t *testing.T) {
+	tileFilterTestSetup(t, ConfigSmoothing{Mode: TileFilterEMA, Alpha: 0.5, OnThreshold: 0.55, OffThreshold: 0.55})
+	TileFilter.Filter([][]float64{{0, 1}})
+	// Another number of tiles starts over, a carried over EMA would be 0.6.
+	lit := TileFilter.Filter([][]float64{{0.8, 0.2}, {0.8, 0.2}})
+	if len(lit) != 0 {
+		t.Errorf("got %v lit tiles, want none", len(lit))
+	}
+	lit = TileFilter.Filter([][]float64{{0.8, 0.2}, {0, 1}})
+	if d, ok := lit[1]; !ok || d.Value != 0.6 {
+		t.Errorf("tile 1: got %v, want lit at an EMA of 0.6", lit[1])
+	}
+	if _, ok := lit[0]; ok {
+		t.Errorf("tile 0 lit at an EMA of 0.2")
+	}
+}
+
+func TestTileFilterVoteTie(t *testing.T) {
+	tileFilterTestSetup(t, ConfigSmoothing{Mode: TileFilterVote, OnThreshold: 0.5, OffThreshold: 0.5, VoteWindow: 6, VoteCount: 2})
+	labels := Config.Labels
+	t.Cleanup(func() { Config.Labels = labels })
+	Config.Labels = []ConfigLabel{{Name: "bg"}, {Name: "x"}, {Name: "a"}, {Name: "b"}}
+	vote := func(class int) []float64 {
+		p := []float64{0.9, 0, 0, 0}
+		p[class] = 0.9
+		return p
+	}
+	// x leads until its first vote leaves the window, a and b tie behind it
+	// and a dark vote comes in.
+	frames := [][]float64{vote(1), vote(1), vote(2), vote(2), vote(3), vote(3), {0.9, 0.05, 0, 0}}
+	for run := 0; run < 20; run++ {
+		TileFilter.Reset()
+		var lit map[int]*SelectedData
+		for _, p := range frames {
+			lit = TileFilter.Filter([][]float64{p})
+		}
+		if d, ok := lit[0]; !ok || d.Class != 2 {
+			t.Fatalf("run %d: got %v, want lit with the first of the tied labels", run, lit[0])
+		}
+	}
+}